@@ -69,6 +69,75 @@ resource "google_bigquery_table" "media_ds_scene_embeddings" {
 EOF
 }
 
+# The vector index of VECTOR_SEARCH. The searches pre-filter the embeddings by model and media,
+# the columns are stored in the index so the pre-filtered searches still use it. The distance
+# type must match the metric of the [vector_index] configuration.
+resource "google_bigquery_job" "media_ds_scene_embeddings_index" {
+  job_id   = "create_scene_embeddings_vector_index"
+  location = google_bigquery_dataset.media_ds.location
+  query {
+    query = <<EOF
+CREATE VECTOR INDEX IF NOT EXISTS scene_embeddings_index
+ON `${google_bigquery_table.media_ds_scene_embeddings.project}.${google_bigquery_dataset.media_ds.dataset_id}.${google_bigquery_table.media_ds_scene_embeddings.table_id}`(embeddings)
+STORING(media_id, model_name)
+OPTIONS(index_type = 'IVF', distance_type = '${var.vector_distance_type}')
+EOF
+    use_legacy_sql     = false
+    create_disposition = ""
+    write_disposition  = ""
+  }
+}
+
+# The keyframe embeddings of the multimodal model, apart from the scene embeddings as their
+# dimension differs and a vector index requires a single dimension.
+# trunk-ignore(checkov/CKV_GCP_80)
+resource "google_bigquery_table" "media_ds_keyframe_embeddings" {
+  dataset_id = google_bigquery_dataset.media_ds.dataset_id
+  table_id   = "keyframe_embeddings"
+  deletion_protection = true
+  schema = <<EOF
+[
+    {
+        "name": "media_id",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "model_name",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "sequence_number",
+        "type": "INTEGER",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "embeddings",
+        "type": "FLOAT64",
+        "mode": "REPEATED"
+    }
+]
+EOF
+}
+
+# The vector index of the keyframe embeddings, created like the scene embeddings index.
+resource "google_bigquery_job" "media_ds_keyframe_embeddings_index" {
+  job_id   = "create_keyframe_embeddings_vector_index"
+  location = google_bigquery_dataset.media_ds.location
+  query {
+    query = <<EOF
+CREATE VECTOR INDEX IF NOT EXISTS keyframe_embeddings_index
+ON `${google_bigquery_table.media_ds_keyframe_embeddings.project}.${google_bigquery_dataset.media_ds.dataset_id}.${google_bigquery_table.media_ds_keyframe_embeddings.table_id}`(embeddings)
+STORING(media_id, model_name)
+OPTIONS(index_type = 'IVF', distance_type = '${var.vector_distance_type}')
+EOF
+    use_legacy_sql     = false
+    create_disposition = ""
+    write_disposition  = ""
+  }
+}
+
 # trunk-ignore(checkov/CKV_GCP_80)
 resource "google_bigquery_table" "media_ds_media" {
   dataset_id = google_bigquery_dataset.media_ds.dataset_id
@@ -187,4 +256,162 @@ resource "google_bigquery_table" "media_ds_media" {
     }
 ]
 EOF
-}
\ No newline at end of file
+}
+
+# trunk-ignore(checkov/CKV_GCP_80)
+resource "google_bigquery_table" "media_ds_actors" {
+  dataset_id = google_bigquery_dataset.media_ds.dataset_id
+  table_id   = "actors"
+  deletion_protection = true
+  schema = <<EOF
+[
+    {
+        "name": "id",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "create_date",
+        "type": "TIMESTAMP",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "name",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "dob",
+        "type": "TIMESTAMP",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "dod",
+        "type": "TIMESTAMP",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "pob",
+        "type": "STRING",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "bio",
+        "type": "STRING",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "aliases",
+        "type": "STRING",
+        "mode": "REPEATED"
+    },
+    {
+        "name": "normalized_aliases",
+        "type": "STRING",
+        "mode": "REPEATED"
+    },
+    {
+        "name": "awards",
+        "type": "STRING",
+        "mode": "REPEATED"
+    },
+    {
+        "name": "nominations",
+        "type": "STRING",
+        "mode": "REPEATED"
+    },
+    {
+        "name": "img_url",
+        "type": "STRING",
+        "mode": "NULLABLE"
+    }
+]
+EOF
+}
+
+# trunk-ignore(checkov/CKV_GCP_80)
+resource "google_bigquery_table" "media_ds_search_events" {
+  dataset_id = google_bigquery_dataset.media_ds.dataset_id
+  table_id   = "search_events"
+  deletion_protection = true
+  schema = <<EOF
+[
+    {
+        "name": "id",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "create_date",
+        "type": "TIMESTAMP",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "query",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "mode",
+        "type": "STRING",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "filter",
+        "type": "STRING",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "latency_ms",
+        "type": "INTEGER",
+        "mode": "NULLABLE"
+    },
+    {
+        "name": "result_ids",
+        "type": "STRING",
+        "mode": "REPEATED"
+    }
+]
+EOF
+}
+
+# trunk-ignore(checkov/CKV_GCP_80)
+resource "google_bigquery_table" "media_ds_feedback_events" {
+  dataset_id = google_bigquery_dataset.media_ds.dataset_id
+  table_id   = "feedback_events"
+  deletion_protection = true
+  schema = <<EOF
+[
+    {
+        "name": "id",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "create_date",
+        "type": "TIMESTAMP",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "search_id",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "type",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "media_id",
+        "type": "STRING",
+        "mode": "REQUIRED"
+    },
+    {
+        "name": "sequence_number",
+        "type": "INTEGER",
+        "mode": "NULLABLE"
+    }
+]
+EOF
+}
@@ -17,4 +17,10 @@
 variable "region" {
     type = string
     description = "Project Default Region"
-}
\ No newline at end of file
+}
+
+variable "vector_distance_type" {
+    type = string
+    description = "Distance type of the scene and keyframe embeddings vector indexes, EUCLIDEAN, COSINE or DOT_PRODUCT"
+    default = "EUCLIDEAN"
+}
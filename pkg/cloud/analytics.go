@@ -0,0 +1,275 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/api/iterator"
+)
+
+// AnalyticsSink records searches and the feedback on their results, and aggregates them by query.
+type AnalyticsSink interface {
+	RecordSearch(ctx context.Context, event *model.SearchEvent) error
+	RecordFeedback(ctx context.Context, event *model.FeedbackEvent) error
+	// QueryStats returns the counts of the searches since the time by query, the
+	// click-through rate is left to the caller.
+	QueryStats(ctx context.Context, since time.Time) ([]*model.QueryStats, error)
+}
+
+// The files of the JSONLAnalyticsSink.
+const (
+	SearchEventFile   = "searches.jsonl"
+	FeedbackEventFile = "feedback.jsonl"
+)
+
+// JSONLAnalyticsSink is an AnalyticsSink appending the events to JSON lines files in a directory.
+type JSONLAnalyticsSink struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewJSONLAnalyticsSink(dir string) (*JSONLAnalyticsSink, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &JSONLAnalyticsSink{dir: dir}, nil
+}
+
+func (s *JSONLAnalyticsSink) append(name string, event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.OpenFile(filepath.Join(s.dir, name), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// read decodes each line of the file with the decode function, lines that
+// don't decode, e.g. one cut short by a crash, are skipped.
+func (s *JSONLAnalyticsSink) read(name string, decode func([]byte) error) error {
+	file, err := os.Open(filepath.Join(s.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if err = decode(scanner.Bytes()); err != nil {
+			log.Printf("skipping analytics event of %s: %v", name, err)
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *JSONLAnalyticsSink) RecordSearch(_ context.Context, event *model.SearchEvent) error {
+	return s.append(SearchEventFile, event)
+}
+
+func (s *JSONLAnalyticsSink) RecordFeedback(_ context.Context, event *model.FeedbackEvent) error {
+	return s.append(FeedbackEventFile, event)
+}
+
+func (s *JSONLAnalyticsSink) QueryStats(_ context.Context, since time.Time) ([]*model.QueryStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// The feedback of each search
+	type searchFeedback struct {
+		clicked    bool
+		thumbsUp   int
+		thumbsDown int
+	}
+	feedback := make(map[string]*searchFeedback)
+	err := s.read(FeedbackEventFile, func(line []byte) error {
+		event := &model.FeedbackEvent{}
+		if err := json.Unmarshal(line, event); err != nil {
+			return err
+		}
+		f, ok := feedback[event.SearchId]
+		if !ok {
+			f = &searchFeedback{}
+			feedback[event.SearchId] = f
+		}
+		switch event.Type {
+		case model.FeedbackClick:
+			f.clicked = true
+		case model.FeedbackThumbsUp:
+			f.thumbsUp++
+		case model.FeedbackThumbsDown:
+			f.thumbsDown++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*model.QueryStats)
+	latency := make(map[string]int64)
+	err = s.read(SearchEventFile, func(line []byte) error {
+		event := &model.SearchEvent{}
+		if err := json.Unmarshal(line, event); err != nil {
+			return err
+		}
+		if event.CreateDate.Before(since) {
+			return nil
+		}
+		q, ok := stats[event.Query]
+		if !ok {
+			q = &model.QueryStats{Query: event.Query}
+			stats[event.Query] = q
+		}
+		q.Searches++
+		latency[event.Query] += event.LatencyMs
+		if len(event.ResultIds) == 0 {
+			q.ZeroResults++
+		}
+		if f, ok := feedback[event.Id]; ok {
+			if f.clicked {
+				q.ClickedSearches++
+			}
+			q.ThumbsUp += f.thumbsUp
+			q.ThumbsDown += f.thumbsDown
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*model.QueryStats, 0, len(stats))
+	for query, q := range stats {
+		q.MeanLatencyMs = float64(latency[query]) / float64(q.Searches)
+		out = append(out, q)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Query < out[j].Query })
+	return out, nil
+}
+
+// QryQueryStats aggregates the search events of the first table since @since by query,
+// with the feedback events of the second table joined by search.
+const QryQueryStats = `WITH feedback AS (
+  SELECT search_id,
+    COUNTIF(type = 'click') > 0 AS clicked,
+    COUNTIF(type = 'thumbs_up') AS thumbs_up,
+    COUNTIF(type = 'thumbs_down') AS thumbs_down
+  FROM ` + "`%s`" + ` GROUP BY search_id
+)
+SELECT s.query,
+  COUNT(*) AS searches,
+  COUNTIF(IFNULL(ARRAY_LENGTH(s.result_ids), 0) = 0) AS zero_results,
+  COUNTIF(IFNULL(f.clicked, FALSE)) AS clicked_searches,
+  IFNULL(SUM(f.thumbs_up), 0) AS thumbs_up,
+  IFNULL(SUM(f.thumbs_down), 0) AS thumbs_down,
+  AVG(s.latency_ms) AS mean_latency_ms
+FROM ` + "`%s`" + ` s LEFT JOIN feedback f ON f.search_id = s.id
+WHERE s.create_date >= @since
+GROUP BY s.query
+ORDER BY s.query`
+
+// BigQueryAnalyticsSink is an AnalyticsSink streaming the events to BigQuery tables.
+type BigQueryAnalyticsSink struct {
+	client        *bigquery.Client
+	dataset       string
+	searchTable   string
+	feedbackTable string
+}
+
+func NewBigQueryAnalyticsSink(client *bigquery.Client, dataset string, searchTable string, feedbackTable string) *BigQueryAnalyticsSink {
+	return &BigQueryAnalyticsSink{client: client, dataset: dataset, searchTable: searchTable, feedbackTable: feedbackTable}
+}
+
+func (s *BigQueryAnalyticsSink) fqn(table string) string {
+	return strings.Replace(s.client.Dataset(s.dataset).Table(table).FullyQualifiedName(), ":", ".", -1)
+}
+
+func (s *BigQueryAnalyticsSink) RecordSearch(ctx context.Context, event *model.SearchEvent) error {
+	return s.client.Dataset(s.dataset).Table(s.searchTable).Inserter().Put(ctx, event)
+}
+
+func (s *BigQueryAnalyticsSink) RecordFeedback(ctx context.Context, event *model.FeedbackEvent) error {
+	return s.client.Dataset(s.dataset).Table(s.feedbackTable).Inserter().Put(ctx, event)
+}
+
+func (s *BigQueryAnalyticsSink) QueryStats(ctx context.Context, since time.Time) ([]*model.QueryStats, error) {
+	q := s.client.Query(fmt.Sprintf(QryQueryStats, s.fqn(s.feedbackTable), s.fqn(s.searchTable)))
+	q.Parameters = []bigquery.QueryParameter{{Name: "since", Value: since}}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.QueryStats, 0)
+	for {
+		stats := &model.QueryStats{}
+		err = itr.Next(stats)
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, stats)
+	}
+}
+
+// NewAnalyticsSink creates the sink configured for search analytics,
+// nil is returned when analytics are disabled.
+func NewAnalyticsSink(config Analytics, dataSource BigQueryDataSource, client *bigquery.Client) (AnalyticsSink, error) {
+	switch config.Backend {
+	case "":
+		return nil, nil
+	case "local":
+		if config.Path == "" {
+			return nil, errors.New("analytics sink 'local' requires a path")
+		}
+		return NewJSONLAnalyticsSink(config.Path)
+	case "bigquery":
+		if config.SearchTable == "" || config.FeedbackTable == "" {
+			return nil, errors.New("analytics sink 'bigquery' requires a search and a feedback table")
+		}
+		return NewBigQueryAnalyticsSink(client, dataSource.DatasetName, config.SearchTable, config.FeedbackTable), nil
+	default:
+		return nil, fmt.Errorf("unknown analytics sink: %s", config.Backend)
+	}
+}
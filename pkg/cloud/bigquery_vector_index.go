@@ -0,0 +1,236 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// QryVectorSearch searches the embeddings, a table or a query of the embeddings
+	// table, for the nearest scenes to @embedding.
+	QryVectorSearch = "SELECT base.media_id, base.sequence_number, distance FROM VECTOR_SEARCH(%s, 'embeddings', " +
+		"(SELECT @embedding AS embed), top_k => @top_k, distance_type => '%s') ORDER BY distance ASC"
+	// QryUpsertEmbeddings merges the @rows into the embeddings table with DML, so rows
+	// can be replaced without waiting for a streaming buffer to flush.
+	QryUpsertEmbeddings = "MERGE `%s` t USING UNNEST(@rows) r ON t.media_id = r.media_id AND t.sequence_number = r.sequence_number " +
+		"AND t.model_name = r.model_name WHEN MATCHED THEN UPDATE SET embeddings = r.embeddings " +
+		"WHEN NOT MATCHED THEN INSERT (media_id, sequence_number, model_name, embeddings) " +
+		"VALUES (r.media_id, r.sequence_number, r.model_name, r.embeddings)"
+	QryDeleteEmbeddings = "DELETE FROM `%s` WHERE media_id = @media_id"
+	QryModelCondition   = " AND model_name = @model_name"
+)
+
+// BigQueryVectorIndex is a VectorIndex of a BigQuery embeddings table, searched with VECTOR_SEARCH.
+// The embeddings of several models share the table, each index sees only the rows of its model.
+// Searches pre-filter the table by model_name and media_id, so the vector index of the table
+// must store both columns (STORING(media_id, model_name)), otherwise the filtered searches
+// bypass the vector index and compare every row.
+type BigQueryVectorIndex struct {
+	client    *bigquery.Client
+	dataset   string
+	table     string
+	modelName string
+	metric    Metric
+}
+
+// NewBigQueryVectorIndex creates a vector index of the embeddings of the model in the embeddings
+// table of the dataset, an empty model name sees every row.
+func NewBigQueryVectorIndex(client *bigquery.Client, dataset string, table string, modelName string, metric Metric) *BigQueryVectorIndex {
+	return &BigQueryVectorIndex{client: client, dataset: dataset, table: table, modelName: modelName, metric: metric}
+}
+
+// scoped restricts the query of the embeddings table to the rows of the model.
+func (b *BigQueryVectorIndex) scoped(qb *QueryBuilder) *QueryBuilder {
+	if b.modelName == "" {
+		return qb
+	}
+	return qb.Where("model_name = @model_name", bigquery.QueryParameter{Name: "model_name", Value: b.modelName})
+}
+
+func (b *BigQueryVectorIndex) fqTable() string {
+	return strings.Replace(b.client.Dataset(b.dataset).Table(b.table).FullyQualifiedName(), ":", ".", -1)
+}
+
+// distanceType returns the VECTOR_SEARCH distance type of the metric.
+func (b *BigQueryVectorIndex) distanceType() string {
+	switch b.metric {
+	case MetricCosine:
+		return "COSINE"
+	case MetricDot:
+		return "DOT_PRODUCT"
+	default:
+		return "EUCLIDEAN"
+	}
+}
+
+func (b *BigQueryVectorIndex) exec(ctx context.Context, q *bigquery.Query) error {
+	job, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
+
+func (b *BigQueryVectorIndex) Upsert(ctx context.Context, embeddings []*model.SceneEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	rows := make([]model.SceneEmbedding, len(embeddings))
+	for i, e := range embeddings {
+		rows[i] = *e
+	}
+	q := b.client.Query(fmt.Sprintf(QryUpsertEmbeddings, b.fqTable()))
+	q.Parameters = []bigquery.QueryParameter{{Name: "rows", Value: rows}}
+	return b.exec(ctx, q)
+}
+
+func (b *BigQueryVectorIndex) Delete(ctx context.Context, mediaId string) error {
+	sql := fmt.Sprintf(QryDeleteEmbeddings, b.fqTable())
+	params := []bigquery.QueryParameter{{Name: "media_id", Value: mediaId}}
+	if b.modelName != "" {
+		sql += QryModelCondition
+		params = append(params, bigquery.QueryParameter{Name: "model_name", Value: b.modelName})
+	}
+	q := b.client.Query(sql)
+	q.Parameters = params
+	return b.exec(ctx, q)
+}
+
+func (b *BigQueryVectorIndex) Query(ctx context.Context, query *VectorQuery) (out []*VectorMatch, err error) {
+	out = make([]*VectorMatch, 0)
+	if query.MediaIds != nil && len(query.MediaIds) == 0 {
+		return out, nil
+	}
+	// The embeddings are pre-filtered by a query of the table on columns stored in the vector index
+	subquery := b.scoped(Select("*").From(b.fqTable(), ""))
+	if query.MediaIds != nil {
+		subquery.Where("media_id IN UNNEST(@media_ids)", bigquery.QueryParameter{Name: "media_ids", Value: query.MediaIds})
+	}
+	if query.Scope != nil {
+		scope, scopeParams, err := query.Scope.Select().Build()
+		if err != nil {
+			return out, err
+		}
+		subquery.Where("media_id IN ("+scope+")", scopeParams...)
+	}
+	if len(query.ExcludeMediaIds) > 0 {
+		subquery.Where("media_id NOT IN UNNEST(@exclude_media_ids)", bigquery.QueryParameter{Name: "exclude_media_ids", Value: query.ExcludeMediaIds})
+	}
+	source, params, err := subquery.Build()
+	if err != nil {
+		return out, err
+	}
+	if len(params) == 0 {
+		source = fmt.Sprintf("TABLE `%s`", b.fqTable())
+	} else {
+		source = "(" + source + ")"
+	}
+	q := b.client.Query(fmt.Sprintf(QryVectorSearch, source, b.distanceType()))
+	q.Parameters = append(params,
+		bigquery.QueryParameter{Name: "embedding", Value: query.Vector},
+		bigquery.QueryParameter{Name: "top_k", Value: query.TopK})
+
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		var m = &VectorMatch{}
+		err = itr.Next(m)
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		m.Similarity = b.metric.Similarity(m.Distance)
+		out = append(out, m)
+	}
+}
+
+func (b *BigQueryVectorIndex) Get(ctx context.Context, mediaId string) (out []*model.SceneEmbedding, err error) {
+	out = make([]*model.SceneEmbedding, 0)
+	q, err := b.scoped(Select("*").From(b.fqTable(), "")).
+		Where("media_id = @media_id", bigquery.QueryParameter{Name: "media_id", Value: mediaId}).
+		OrderBy("sequence_number").
+		Query(b.client)
+	if err != nil {
+		return out, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		var e = &model.SceneEmbedding{}
+		err = itr.Next(e)
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, e)
+	}
+}
+
+func (b *BigQueryVectorIndex) MediaIds(ctx context.Context) (out []string, err error) {
+	out = make([]string, 0)
+	q, err := b.scoped(Select("DISTINCT media_id").From(b.fqTable(), "")).Query(b.client)
+	if err != nil {
+		return out, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		var row struct {
+			MediaId string `bigquery:"media_id"`
+		}
+		err = itr.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, row.MediaId)
+	}
+}
+
+// Unindexed matches the media without rows of the model in the embeddings table with a
+// subquery, so the eligible media are never loaded.
+func (b *BigQueryVectorIndex) Unindexed(_ context.Context, column string) (string, []bigquery.QueryParameter, error) {
+	indexed, params, err := b.scoped(Select("media_id").From(b.fqTable(), "")).Build()
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s NOT IN (%s)", column, indexed), params, nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"cloud.google.com/go/storage"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist in the store.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// ErrInvalidBlobName is returned when a bucket or object name resolves outside of a local store.
+var ErrInvalidBlobName = errors.New("invalid blob name")
+
+// BlobAttrs are the attributes of a blob.
+type BlobAttrs struct {
+	Bucket      string
+	Name        string
+	ContentType string
+	Size        int64
+	Generation  string
+	Updated     time.Time
+}
+
+// BlobWriter writes a blob, the blob is written when the writer is closed. Abort discards
+// what was written instead, leaving any existing blob of the name as it was.
+type BlobWriter interface {
+	io.WriteCloser
+	Abort() error
+}
+
+// BlobStore is the storage of media files, addressed by bucket and object name
+// whether the backend is GCS, a GCS FUSE mount, or a local directory.
+type BlobStore interface {
+	// Open opens the blob for reading.
+	Open(ctx context.Context, bucket string, name string) (io.ReadCloser, error)
+	// Create opens the blob for writing, the blob is written when the writer is closed, not if it is aborted.
+	Create(ctx context.Context, bucket string, name string, contentType string) (BlobWriter, error)
+	// Stat returns the attributes of the blob.
+	Stat(ctx context.Context, bucket string, name string) (*BlobAttrs, error)
+	// List returns the attributes of the blobs with names starting with the prefix.
+	List(ctx context.Context, bucket string, prefix string) ([]*BlobAttrs, error)
+	// Delete deletes the blob.
+	Delete(ctx context.Context, bucket string, name string) error
+	// SignedURL returns a URL granting the HTTP method on the blob until it expires.
+	SignedURL(ctx context.Context, bucket string, name string, method string, expires time.Duration) (string, error)
+}
+
+// LocalBlobStorer is implemented by blob stores keeping blobs as local files,
+// so commands running external tools like ffmpeg can read them in place.
+type LocalBlobStorer interface {
+	// LocalPath returns the file path of the blob, or ErrInvalidBlobName if it is outside the store.
+	LocalPath(bucket string, name string) (string, error)
+}
+
+// The blob store backends of the Storage configuration.
+const (
+	BlobStoreFUSE  = "fuse"
+	BlobStoreGCS   = "gcs"
+	BlobStoreLocal = "local"
+)
+
+// NewBlobStore creates the blob store configured for media files.
+func NewBlobStore(config Storage, client *storage.Client) (BlobStore, error) {
+	switch config.Backend {
+	case "", BlobStoreFUSE:
+		return NewFUSEBlobStore(config.GCSFuseMountPoint, client), nil
+	case BlobStoreGCS:
+		return NewGCSBlobStore(client), nil
+	case BlobStoreLocal:
+		if config.LocalPath == "" {
+			return nil, errors.New("blob store 'local' requires a local path")
+		}
+		return NewLocalBlobStore(config.LocalPath), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store: %s", config.Backend)
+	}
+}
+
+// CopyToLocalFile returns a local file path of the blob, the blob is downloaded to a
+// temp file unless the store keeps it as a local file. The returned function removes
+// any temp file and must be called once the file is no longer needed.
+func CopyToLocalFile(ctx context.Context, store BlobStore, bucket string, name string) (path string, cleanup func(), err error) {
+	if local, ok := store.(LocalBlobStorer); ok {
+		path, err := local.LocalPath(bucket, name)
+		if err != nil {
+			return "", nil, err
+		}
+		return path, func() {}, nil
+	}
+	reader, err := store.Open(ctx, bucket, name)
+	if err != nil {
+		return "", nil, err
+	}
+	defer reader.Close()
+	tempFile, err := os.CreateTemp("", "blob-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { _ = os.Remove(tempFile.Name()) }
+	if _, err = io.Copy(tempFile, reader); err != nil {
+		_ = tempFile.Close()
+		cleanup()
+		return "", nil, err
+	}
+	if err = tempFile.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return tempFile.Name(), cleanup, nil
+}
+
+// CopyFromLocalFile writes the local file to the blob.
+func CopyFromLocalFile(ctx context.Context, store BlobStore, path string, bucket string, name string, contentType string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteBlob(ctx, store, file, bucket, name, contentType)
+}
+
+// WriteBlob writes the content of the reader to the blob, the blob is only
+// written if all of the content is, a failed copy aborts the writer.
+func WriteBlob(ctx context.Context, store BlobStore, reader io.Reader, bucket string, name string, contentType string) error {
+	writer, err := store.Create(ctx, bucket, name, contentType)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(writer, reader); err != nil {
+		return errors.Join(err, writer.Abort())
+	}
+	return writer.Close()
+}
@@ -0,0 +1,74 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// channelMessage is a message sent to a ChannelMessageSource.
+type channelMessage struct {
+	id   string
+	data string
+}
+
+// ChannelMessageSource is a MessageSource for messages sent in-process with Send,
+// it is used to run the workflows without Pub/Sub, e.g. in tests.
+type ChannelMessageSource struct {
+	messageDispatcher
+	messages chan channelMessage
+}
+
+// NewChannelMessageSource creates a source buffering up to size messages.
+func NewChannelMessageSource(size int) *ChannelMessageSource {
+	return &ChannelMessageSource{messages: make(chan channelMessage, size)}
+}
+
+// Send sends the notification, it blocks until the message is received or the context is done.
+func (s *ChannelMessageSource) Send(ctx context.Context, notification *GCSPubSubNotification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	message := channelMessage{id: uuid.NewString(), data: string(data)}
+	select {
+	case s.messages <- message:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Listen starts delivering the sent messages until the context is done.
+func (s *ChannelMessageSource) Listen(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message := <-s.messages:
+				if err := s.dispatch(ctx, message.id, message.data, nil); err != nil {
+					log.Printf("error executing chain: %v", err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"cloud.google.com/go/storage"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"google.golang.org/api/iterator"
+)
+
+// GCSCheckpointStore is a cor.CheckpointStore backed by a GCS bucket,
+// each checkpoint is an object named <prefix>/<key>/<name>.json.
+type GCSCheckpointStore struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+func NewGCSCheckpointStore(client *storage.Client, bucket string, prefix string) *GCSCheckpointStore {
+	return &GCSCheckpointStore{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *GCSCheckpointStore) objectName(key string, name string) string {
+	return path.Join(s.prefix, key, name+".json")
+}
+
+func (s *GCSCheckpointStore) Load(ctx context.Context, key string, name string) ([]byte, bool, error) {
+	reader, err := s.client.Bucket(s.bucket).Object(s.objectName(key, name)).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+func (s *GCSCheckpointStore) Save(ctx context.Context, key string, name string, data []byte) error {
+	writer := s.client.Bucket(s.bucket).Object(s.objectName(key, name)).NewWriter(ctx)
+	writer.ContentType = "application/json"
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (s *GCSCheckpointStore) Clear(ctx context.Context, key string) error {
+	bucket := s.client.Bucket(s.bucket)
+	it := bucket.Objects(ctx, &storage.Query{Prefix: path.Join(s.prefix, key) + "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = bucket.Object(attrs.Name).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+			return err
+		}
+	}
+}
+
+// NewCheckpointStore creates the checkpoint store configured for ingestion,
+// nil is returned when checkpoints are disabled.
+func NewCheckpointStore(config Checkpoint, client *storage.Client) (cor.CheckpointStore, error) {
+	switch config.Store {
+	case "":
+		return nil, nil
+	case "local":
+		if config.Path == "" {
+			return nil, errors.New("checkpoint store 'local' requires a path")
+		}
+		return cor.NewFileCheckpointStore(config.Path), nil
+	case "gcs":
+		if config.Bucket == "" {
+			return nil, errors.New("checkpoint store 'gcs' requires a bucket")
+		}
+		return NewGCSCheckpointStore(client, config.Bucket, config.Prefix), nil
+	default:
+		return nil, fmt.Errorf("unknown checkpoint store: %s", config.Store)
+	}
+}
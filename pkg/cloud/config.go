@@ -20,7 +20,9 @@ package cloud
 
 import (
 	"text/template"
+	"time"
 
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
 	"google.golang.org/genai"
 )
 
@@ -46,9 +48,11 @@ var DefaultSafetySettings = []*genai.SafetySetting{
 
 // BigQueryDataSource represents the configuration for a BigQuery data source.
 type BigQueryDataSource struct {
-	DatasetName    string `toml:"dataset"`         // The name of the BigQuery dataset.
-	MediaTable     string `toml:"media_table"`     // The name of the BigQuery table containing media information.
-	EmbeddingTable string `toml:"embedding_table"` // The name of the BigQuery table containing embedding vectors.
+	DatasetName            string `toml:"dataset"`                  // The name of the BigQuery dataset.
+	MediaTable             string `toml:"media_table"`              // The name of the BigQuery table containing media information.
+	EmbeddingTable         string `toml:"embedding_table"`          // The name of the BigQuery table containing embedding vectors.
+	KeyframeEmbeddingTable string `toml:"keyframe_embedding_table"` // The name of the BigQuery table containing the keyframe embedding vectors, DefaultKeyframeEmbeddingTable if empty.
+	ActorTable             string `toml:"actor_table"`              // The name of the BigQuery table containing the actor registry.
 }
 
 // PromptTemplates holds the templates for different types of prompts.
@@ -82,6 +86,8 @@ type VertexAiLLMModel struct {
 	OutputFormat       string  `toml:"output_format"`       // The desired output format for the LLM.
 	EnableGoogle       bool    `toml:"enable_google"`       // Whether to enable Google Search for the LLM.
 	RateLimit          int     `toml:"rate_limit"`          // The rate limit for the LLM in requests per second.
+	Provider           string  `toml:"provider"`            // The model provider, "vertex" (default), "replay" or "record".
+	ReplayPath         string  `toml:"replay_path"`         // The directory of the recorded responses of the "replay" and "record" providers.
 }
 
 // TopicSubscription represents the configuration for a Pub/Sub topic subscription.
@@ -89,6 +95,19 @@ type TopicSubscription struct {
 	Name             string `toml:"name"`               // The name of the Pub/Sub subscription.
 	DeadLetterTopic  string `toml:"dead_letter_topic"`  // The name of the dead-letter topic for the subscription.
 	TimeoutInSeconds int    `toml:"timeout_in_seconds"` // The timeout for the subscription in seconds.
+	Workers          int    `toml:"workers"`            // The number of jobs of the subscription executed at the same time.
+
+	Source                string `toml:"source"`                   // The message source, "pubsub" (default), "directory" or "channel".
+	Directory             string `toml:"directory"`                // The directory watched by a "directory" source.
+	Bucket                string `toml:"bucket"`                   // The bucket named in "directory" source notifications, the directory name if empty.
+	PollIntervalInSeconds int    `toml:"poll_interval_in_seconds"` // The interval a "directory" source scans at.
+}
+
+// Jobs represents the configuration of the job store for received messages.
+type Jobs struct {
+	Store            string `toml:"store"`              // The store type, "local" or "memory", empty is "local".
+	Path             string `toml:"path"`               // The directory of the local store, DefaultJobStorePath if empty.
+	RetentionInHours int    `toml:"retention_in_hours"` // How long finished jobs are kept by the local store, DefaultJobRetention if 0, forever if negative.
 }
 
 // Storage represents the configuration for storage buckets.
@@ -96,6 +115,92 @@ type Storage struct {
 	HiResInputBucket   string `toml:"high_res_input_bucket"` // The name of the bucket for high-resolution input files.
 	LowResOutputBucket string `toml:"low_res_output_bucket"` // The name of the bucket for low-resolution output files.
 	GCSFuseMountPoint  string `toml:"gcs_fuse_mount_point"`  // The mount point for GCS FUSE.
+	Backend            string `toml:"backend"`               // The blob store, "fuse" (default), "gcs" or "local".
+	LocalPath          string `toml:"local_path"`            // The directory of the "local" blob store, holding a directory per bucket.
+}
+
+// RetryPolicy represents the retry configuration for a command, keyed by the command name.
+type RetryPolicy struct {
+	MaxAttempts             int     `toml:"max_attempts"`               // The maximum number of attempts, including the first.
+	InitialBackoffInSeconds float64 `toml:"initial_backoff_in_seconds"` // The wait before the first retry.
+	MaxBackoffInSeconds     float64 `toml:"max_backoff_in_seconds"`     // The upper bound of the wait between retries.
+	Multiplier              float64 `toml:"multiplier"`                 // The growth factor of the wait between retries.
+	Jitter                  float64 `toml:"jitter"`                     // The random fraction (0 to 1) applied to each wait.
+	AttemptTimeoutInSeconds int     `toml:"attempt_timeout_in_seconds"` // The timeout of a single attempt, 0 for none.
+}
+
+// ToRetryPolicy converts the configuration to a cor.RetryPolicy.
+func (r RetryPolicy) ToRetryPolicy() *cor.RetryPolicy {
+	return &cor.RetryPolicy{
+		MaxAttempts:    r.MaxAttempts,
+		InitialBackoff: time.Duration(r.InitialBackoffInSeconds * float64(time.Second)),
+		MaxBackoff:     time.Duration(r.MaxBackoffInSeconds * float64(time.Second)),
+		Multiplier:     r.Multiplier,
+		Jitter:         r.Jitter,
+		AttemptTimeout: time.Duration(r.AttemptTimeoutInSeconds) * time.Second,
+	}
+}
+
+// Checkpoint represents the configuration of the ingestion checkpoint store.
+type Checkpoint struct {
+	Store  string `toml:"store"`  // The store type, "local" or "gcs", empty disables checkpoints.
+	Path   string `toml:"path"`   // The directory of the local store.
+	Bucket string `toml:"bucket"` // The bucket of the gcs store.
+	Prefix string `toml:"prefix"` // The object name prefix of the gcs store.
+}
+
+// Analytics represents the configuration of the sink of search and feedback events.
+type Analytics struct {
+	Backend       string `toml:"backend"`        // The sink, "bigquery" or "local", empty disables analytics.
+	Path          string `toml:"path"`           // The directory of the JSON lines files of the "local" sink.
+	SearchTable   string `toml:"search_table"`   // The BigQuery table of the search events.
+	FeedbackTable string `toml:"feedback_table"` // The BigQuery table of the feedback events.
+}
+
+// SavedSearchConfig represents the configuration of the saved searches and their alerts.
+type SavedSearchConfig struct {
+	Store                   string  `toml:"store"`                      // The store type, "local" or "memory", empty is "local".
+	Path                    string  `toml:"path"`                       // The directory of the local store, a temporary directory if empty.
+	MinSimilarity           float64 `toml:"min_similarity"`             // The cosine similarity of a scene to a saved query at which it is alerted, unless the search sets one.
+	Notifier                string  `toml:"notifier"`                   // The notifier of alerts, "log" (default) or "webhook".
+	WebhookUrl              string  `toml:"webhook_url"`                // The URL alerts are posted to by the "webhook" notifier.
+	WebhookTimeoutInSeconds int     `toml:"webhook_timeout_in_seconds"` // The timeout of a post of the "webhook" notifier.
+}
+
+// Search represents the configuration of the search service.
+type Search struct {
+	DefaultMode        string   `toml:"default_mode"`         // The mode of searches without one, "vector", "keyword" or "hybrid".
+	VectorWeight       *float64 `toml:"vector_weight"`        // The weight of the vector ranking in hybrid search, 1 if not set, 0 switches it off.
+	KeywordWeight      *float64 `toml:"keyword_weight"`       // The weight of the keyword ranking in hybrid search, 1 if not set, 0 switches it off.
+	RRFK               float64  `toml:"rrf_k"`                // The rank constant of the reciprocal rank fusion of hybrid search.
+	MinSimilarity      float64  `toml:"min_similarity"`       // The similarity below which vector matches are dropped, 0 keeps every match.
+	ExplainModel       string   `toml:"explain_model"`        // The agent model explaining search results, explanations are disabled if empty.
+	InterpretModel     string   `toml:"interpret_model"`      // The agent model extracting filters from search queries, interpretation is disabled if empty.
+	Facets             []string `toml:"facets"`               // The facet fields counted for searches, every field if empty.
+	FacetDepth         int      `toml:"facet_depth"`          // The top scenes whose media are counted by facets.
+	FacetSize          int      `toml:"facet_size"`           // The buckets of a facet.
+	EmbeddingCacheSize int      `toml:"embedding_cache_size"` // The query embeddings cached, 0 disables the cache.
+	ResultCacheSize    int      `toml:"result_cache_size"`    // The search pages cached, 0 disables the cache.
+	CacheTTLInSeconds  int      `toml:"cache_ttl_in_seconds"` // The time cached query embeddings and search pages are kept, 0 until evicted.
+}
+
+// VectorIndexConfig represents the configuration of the nearest neighbour index of the scene embeddings.
+type VectorIndexConfig struct {
+	Backend        string `toml:"backend"`         // The index, "bigquery" (default) or "hnsw".
+	Metric         string `toml:"metric"`          // The distance metric, "euclidean" (default), "cosine" or "dot".
+	Path           string `toml:"path"`            // The file of the "hnsw" index, empty keeps it in memory.
+	M              int    `toml:"m"`               // The neighbours of a node per layer of the "hnsw" graph.
+	EfConstruction int    `toml:"ef_construction"` // The candidates considered when inserting into the "hnsw" graph.
+	EfSearch       int    `toml:"ef_search"`       // The candidates considered when querying the "hnsw" graph.
+}
+
+// MultimodalEmbeddingConfig represents the configuration of the scene keyframe embeddings,
+// searched by image and video clip queries.
+type MultimodalEmbeddingConfig struct {
+	Model         string  `toml:"model"`          // The Vertex AI multimodal embedding model, keyframes aren't embedded if empty.
+	Dimension     int     `toml:"dimension"`      // The dimension of the image embeddings, 1408 if not set. Clip queries require 1408.
+	IndexPath     string  `toml:"index_path"`     // The file of the "hnsw" index of the keyframe embeddings, empty keeps it in memory.
+	MinSimilarity float64 `toml:"min_similarity"` // The similarity below which keyframe matches are dropped, 0 keeps every match.
 }
 
 type Category struct {
@@ -120,14 +225,22 @@ type Config struct {
 		GoogleLocation  string `toml:"location"`          // The Google Cloud location.
 		ThreadPoolSize  int    `toml:"thread_pool_size"`  // The size of the thread pool.
 	} `toml:"application"`
-	Storage            Storage                           `toml:"storage"`               // Storage configuration.
-	BigQueryDataSource BigQueryDataSource                `toml:"big_query_data_source"` // BigQuery data source configuration.
-	PromptTemplates    map[string]PromptTemplates        `toml:"prompt_templates"`      // Prompt templates configuration.
-	TopicSubscriptions map[string]TopicSubscription      `toml:"topic_subscriptions"`   // Pub/Sub topic subscriptions configuration.
-	EmbeddingModels    map[string]VertexAiEmbeddingModel `toml:"embedding_models"`      // Vertex AI embedding models configuration.
-	AgentModels        map[string]VertexAiLLMModel       `toml:"agent_models"`          // Vertex AI LLM models configuration.
-	Categories         map[string]Category               `toml:"categories"`            // A list of category definitions and LLM overrides.
-	ContentType        ContentType                       `toml:"content_type"`          // Content type configuration.
+	Storage             Storage                           `toml:"storage"`               // Storage configuration.
+	BigQueryDataSource  BigQueryDataSource                `toml:"big_query_data_source"` // BigQuery data source configuration.
+	PromptTemplates     map[string]PromptTemplates        `toml:"prompt_templates"`      // Prompt templates configuration.
+	TopicSubscriptions  map[string]TopicSubscription      `toml:"topic_subscriptions"`   // Pub/Sub topic subscriptions configuration.
+	EmbeddingModels     map[string]VertexAiEmbeddingModel `toml:"embedding_models"`      // Vertex AI embedding models configuration.
+	AgentModels         map[string]VertexAiLLMModel       `toml:"agent_models"`          // Vertex AI LLM models configuration.
+	Categories          map[string]Category               `toml:"categories"`            // A list of category definitions and LLM overrides.
+	ContentType         ContentType                       `toml:"content_type"`          // Content type configuration.
+	RetryPolicies       map[string]RetryPolicy            `toml:"retry_policies"`        // Retry policies keyed by command name.
+	Checkpoint          Checkpoint                        `toml:"checkpoint"`            // Ingestion checkpoint configuration.
+	Jobs                Jobs                              `toml:"jobs"`                  // Job store configuration.
+	Search              Search                            `toml:"search"`                // Search configuration.
+	VectorIndex         VectorIndexConfig                 `toml:"vector_index"`          // Vector index configuration.
+	MultimodalEmbedding MultimodalEmbeddingConfig         `toml:"multimodal_embedding"`  // Multimodal embedding configuration.
+	Analytics           Analytics                         `toml:"analytics"`             // Search analytics configuration.
+	SavedSearches       SavedSearchConfig                 `toml:"saved_searches"`        // Saved search configuration.
 }
 
 func (c *Config) Replace(newConfig *Config) {
@@ -140,6 +253,14 @@ func (c *Config) Replace(newConfig *Config) {
 	c.AgentModels = newConfig.AgentModels
 	c.Categories = newConfig.Categories
 	c.ContentType = newConfig.ContentType
+	c.RetryPolicies = newConfig.RetryPolicies
+	c.Checkpoint = newConfig.Checkpoint
+	c.Jobs = newConfig.Jobs
+	c.Search = newConfig.Search
+	c.VectorIndex = newConfig.VectorIndex
+	c.MultimodalEmbedding = newConfig.MultimodalEmbedding
+	c.Analytics = newConfig.Analytics
+	c.SavedSearches = newConfig.SavedSearches
 }
 
 // NewConfig creates a new Config instance with initialized maps.
@@ -149,5 +270,6 @@ func NewConfig() *Config {
 		EmbeddingModels:    make(map[string]VertexAiEmbeddingModel),
 		AgentModels:        make(map[string]VertexAiLLMModel),
 		Categories:         make(map[string]Category),
+		RetryPolicies:      make(map[string]RetryPolicy),
 	}
 }
@@ -0,0 +1,148 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DirectoryMessageSource is a MessageSource watching a local directory, it sends a
+// GCSPubSubNotification for each new or changed file once the file stops changing.
+// The directory mirrors a bucket mounted with GCS FUSE, so pointing the FUSE mount
+// point at its parent runs the workflows against local files.
+type DirectoryMessageSource struct {
+	messageDispatcher
+	directory    string
+	bucket       string
+	pollInterval time.Duration
+	files        map[string]fileVersion // The last seen version of each file.
+	sent         map[string]fileVersion // The version of each file a message was sent for.
+}
+
+// fileVersion identifies a version of a file, a file is considered written once its version is unchanged between scans.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewDirectoryMessageSource creates a source for the directory, notifications name the
+// given bucket, or the directory name if empty, and the file path relative to the directory.
+func NewDirectoryMessageSource(directory string, bucket string, pollInterval time.Duration) *DirectoryMessageSource {
+	if bucket == "" {
+		bucket = filepath.Base(directory)
+	}
+	return &DirectoryMessageSource{
+		directory:    directory,
+		bucket:       bucket,
+		pollInterval: pollInterval,
+		files:        make(map[string]fileVersion),
+		sent:         make(map[string]fileVersion),
+	}
+}
+
+// Listen starts scanning the directory until the context is done.
+func (s *DirectoryMessageSource) Listen(ctx context.Context) {
+	log.Printf("listening: %s", s.directory)
+	go func() {
+		ticker := time.NewTicker(s.pollInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.Scan(ctx); err != nil {
+				log.Printf("error scanning directory %s: %v", s.directory, err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Scan sends a message for each file unchanged since the previous scan that has not been sent,
+// Listen calls it on every poll interval.
+func (s *DirectoryMessageSource) Scan(ctx context.Context) error {
+	seen := make(map[string]bool)
+	err := filepath.WalkDir(s.directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(s.directory, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		seen[name] = true
+
+		version := fileVersion{modTime: info.ModTime(), size: info.Size()}
+		previous, ok := s.files[name]
+		s.files[name] = version
+		if !ok || previous != version || s.sent[name] == version {
+			return nil
+		}
+		if err = s.send(ctx, name, version); err != nil {
+			log.Printf("error executing chain: %v", err)
+			return nil
+		}
+		s.sent[name] = version
+		return nil
+	})
+	for name := range s.files {
+		if !seen[name] {
+			delete(s.files, name)
+			delete(s.sent, name)
+		}
+	}
+	return err
+}
+
+func (s *DirectoryMessageSource) send(ctx context.Context, name string, version fileVersion) error {
+	generation := strconv.FormatInt(version.modTime.UnixNano(), 10)
+	timestamp := version.modTime.UTC().Format(time.RFC3339Nano)
+	notification := &GCSPubSubNotification{
+		Kind:           "storage#object",
+		ID:             fmt.Sprintf("%s/%s/%s", s.bucket, name, generation),
+		Name:           name,
+		Bucket:         s.bucket,
+		Generation:     generation,
+		MetaGeneration: "1",
+		ContentType:    mime.TypeByExtension(filepath.Ext(name)),
+		TimeCreated:    timestamp,
+		Updated:        timestamp,
+		Size:           strconv.FormatInt(version.size, 10),
+	}
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	// Message ids are used in job ids, so they do not contain path separators
+	id := fmt.Sprintf("%s-%s", strings.ReplaceAll(name, "/", "-"), generation)
+	return s.dispatch(ctx, id, string(data), nil)
+}
@@ -16,11 +16,16 @@
 
 package cloud
 
+import "github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+
 // GetGCSObjectName returns a placeholder string for a GCS object name.
 func GetGCSObjectName() string {
 	return "__GCS__OBJ__"
 }
 
+// GCSObjectKey is the typed context key for the GCSObject being processed.
+var GCSObjectKey = cor.NewKey[*GCSObject](GetGCSObjectName())
+
 // GCSPubSubNotification is the structure of a message received from a
 // Google Cloud Storage (GCS) Pub/Sub notification. It contains metadata
 // about a change to an object in a GCS bucket.
@@ -46,9 +51,10 @@ type GCSPubSubNotification struct {
 }
 
 // GCSObject is a simplified representation of a Google Cloud Storage (GCS)
-// object. It contains the bucket name, object name, and MIME type of the object.
+// object. It contains the bucket name, object name, generation, and MIME type of the object.
 type GCSObject struct {
-	Bucket   string
-	Name     string
-	MIMEType string
+	Bucket     string
+	Name       string
+	Generation string
+	MIMEType   string
 }
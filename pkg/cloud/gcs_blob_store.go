@@ -0,0 +1,140 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// GCSBlobStore is a BlobStore using the GCS client.
+type GCSBlobStore struct {
+	client *storage.Client
+}
+
+func NewGCSBlobStore(client *storage.Client) *GCSBlobStore {
+	return &GCSBlobStore{client: client}
+}
+
+// gcsError converts the GCS not found error to ErrBlobNotFound.
+func gcsError(err error) error {
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return errors.Join(ErrBlobNotFound, err)
+	}
+	return err
+}
+
+func toBlobAttrs(attrs *storage.ObjectAttrs) *BlobAttrs {
+	return &BlobAttrs{
+		Bucket:      attrs.Bucket,
+		Name:        attrs.Name,
+		ContentType: attrs.ContentType,
+		Size:        attrs.Size,
+		Generation:  strconv.FormatInt(attrs.Generation, 10),
+		Updated:     attrs.Updated,
+	}
+}
+
+func (s *GCSBlobStore) Open(ctx context.Context, bucket string, name string) (io.ReadCloser, error) {
+	reader, err := s.client.Bucket(bucket).Object(name).NewReader(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return reader, nil
+}
+
+// gcsWriter writes an object with a cancellable context, cancelling the upload on abort
+// so a partial object is never committed.
+type gcsWriter struct {
+	*storage.Writer
+	cancel context.CancelFunc
+}
+
+func (w *gcsWriter) Close() error {
+	defer w.cancel()
+	return w.Writer.Close()
+}
+
+func (w *gcsWriter) Abort() error {
+	w.cancel()
+	// Closing a cancelled writer fails without creating the object
+	_ = w.Writer.Close()
+	return nil
+}
+
+func (s *GCSBlobStore) Create(ctx context.Context, bucket string, name string, contentType string) (BlobWriter, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	writer := s.client.Bucket(bucket).Object(name).NewWriter(ctx)
+	writer.ContentType = contentType
+	return &gcsWriter{Writer: writer, cancel: cancel}, nil
+}
+
+func (s *GCSBlobStore) Stat(ctx context.Context, bucket string, name string) (*BlobAttrs, error) {
+	attrs, err := s.client.Bucket(bucket).Object(name).Attrs(ctx)
+	if err != nil {
+		return nil, gcsError(err)
+	}
+	return toBlobAttrs(attrs), nil
+}
+
+func (s *GCSBlobStore) List(ctx context.Context, bucket string, prefix string) ([]*BlobAttrs, error) {
+	out := make([]*BlobAttrs, 0)
+	it := s.client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, toBlobAttrs(attrs))
+	}
+}
+
+func (s *GCSBlobStore) Delete(ctx context.Context, bucket string, name string) error {
+	return gcsError(s.client.Bucket(bucket).Object(name).Delete(ctx))
+}
+
+func (s *GCSBlobStore) SignedURL(_ context.Context, bucket string, name string, method string, expires time.Duration) (string, error) {
+	return s.client.Bucket(bucket).SignedURL(name, &storage.SignedURLOptions{
+		Method:  method,
+		Expires: time.Now().Add(expires),
+		Scheme:  storage.SigningSchemeV4,
+	})
+}
+
+// FUSEBlobStore is a BlobStore reading and writing a GCS FUSE mount,
+// signed URLs are created with the GCS client.
+type FUSEBlobStore struct {
+	*LocalBlobStore
+	gcs *GCSBlobStore
+}
+
+func NewFUSEBlobStore(mountPoint string, client *storage.Client) *FUSEBlobStore {
+	return &FUSEBlobStore{LocalBlobStore: NewLocalBlobStore(mountPoint), gcs: NewGCSBlobStore(client)}
+}
+
+func (s *FUSEBlobStore) SignedURL(ctx context.Context, bucket string, name string, method string, expires time.Duration) (string, error) {
+	return s.gcs.SignedURL(ctx, bucket, name, method, expires)
+}
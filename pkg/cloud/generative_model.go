@@ -0,0 +1,83 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// GenerativeModel is a large language model generating content from multi-modal contents.
+// The output schema, when not nil, requests structured output and the response carries
+// the token usage in its UsageMetadata.
+type GenerativeModel interface {
+	GenerateContent(ctx context.Context, systemInstruction string, contents []*genai.Content, outputSchema *genai.Schema) (*genai.GenerateContentResponse, error)
+}
+
+// The generative model providers of a VertexAiLLMModel.
+const (
+	ProviderVertex = "vertex" // Vertex AI through the genai client.
+	ProviderReplay = "replay" // Responses recorded on disk, see ReplayGenerativeModel.
+	ProviderRecord = "record" // Vertex AI, recording the responses for the replay provider.
+)
+
+// VertexGenerativeModel is a GenerativeModel using a Vertex AI model through the genai client.
+type VertexGenerativeModel struct {
+	GenerativeContentConfig *genai.GenerateContentConfig // The configuration for LLM content generation.
+	ModelName               string
+	ModelHandle             *genai.Models
+}
+
+func NewVertexGenerativeModel(config *genai.GenerateContentConfig, modelName string, modelHandle *genai.Models) *VertexGenerativeModel {
+	return &VertexGenerativeModel{GenerativeContentConfig: config, ModelName: modelName, ModelHandle: modelHandle}
+}
+
+func (v *VertexGenerativeModel) GenerateContent(ctx context.Context, systemInstruction string, contents []*genai.Content, outputSchema *genai.Schema) (*genai.GenerateContentResponse, error) {
+	// Create a copy of the generative content config to avoid modifying the original.
+	config := *v.GenerativeContentConfig
+
+	// set the desired output schema, take it from
+	if outputSchema != nil {
+		config.ResponseSchema = outputSchema
+	}
+
+	if systemInstruction != "" {
+		config.SystemInstruction = genai.NewContentFromText(systemInstruction, genai.RoleUser)
+	}
+	return v.ModelHandle.GenerateContent(ctx, v.ModelName, contents, &config)
+}
+
+// NewGenerativeModel creates the generative model of the configured provider,
+// the Vertex AI model is used by the "vertex" and "record" providers.
+func NewGenerativeModel(config VertexAiLLMModel, vertex GenerativeModel) (GenerativeModel, error) {
+	switch config.Provider {
+	case "", ProviderVertex:
+		return vertex, nil
+	case ProviderReplay, ProviderRecord:
+		if config.ReplayPath == "" {
+			return nil, fmt.Errorf("generative model provider '%s' requires a replay path", config.Provider)
+		}
+		if config.Provider == ProviderReplay {
+			vertex = nil
+		}
+		return NewReplayGenerativeModel(config.ReplayPath, vertex), nil
+	default:
+		return nil, fmt.Errorf("unknown generative model provider: %s", config.Provider)
+	}
+}
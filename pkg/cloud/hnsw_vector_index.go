@@ -0,0 +1,511 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// HNSWParams are the graph parameters of an HNSWVectorIndex, zero values are defaults.
+type HNSWParams struct {
+	M              int // The neighbours of a node per layer, twice as many on the bottom layer, default 16.
+	EfConstruction int // The candidates considered when inserting, default 200.
+	EfSearch       int // The candidates considered when querying, at least the top k, default 64.
+}
+
+func (p HNSWParams) withDefaults() HNSWParams {
+	if p.M <= 1 {
+		p.M = 16
+	}
+	if p.EfConstruction <= 0 {
+		p.EfConstruction = 200
+	}
+	if p.EfSearch <= 0 {
+		p.EfSearch = 64
+	}
+	return p
+}
+
+// hnswNode is a scene embedding in the graph, deleted nodes stay in the graph
+// as tombstones until it is rebuilt so searches can still traverse them.
+type hnswNode struct {
+	MediaId        string
+	SequenceNumber int
+	ModelName      string
+	Vector         []float64
+	Friends        [][]int // The neighbours of the node on each of its layers.
+	Deleted        bool
+}
+
+// hnswSnapshot is the on-disk form of the index.
+type hnswSnapshot struct {
+	Metric   Metric
+	Params   HNSWParams
+	Nodes    []*hnswNode
+	Entry    int
+	MaxLevel int
+}
+
+type hnswCandidate struct {
+	id       int
+	distance float64
+}
+
+// hnswHeap is a heap of candidates, nearest on top, or farthest on top when farthest is set.
+type hnswHeap struct {
+	items    []hnswCandidate
+	farthest bool
+}
+
+func (h *hnswHeap) Len() int { return len(h.items) }
+func (h *hnswHeap) Less(i, j int) bool {
+	if h.farthest {
+		return h.items[i].distance > h.items[j].distance
+	}
+	return h.items[i].distance < h.items[j].distance
+}
+func (h *hnswHeap) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *hnswHeap) Push(x any)    { h.items = append(h.items, x.(hnswCandidate)) }
+func (h *hnswHeap) Pop() any {
+	last := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return last
+}
+
+// HNSWVectorIndex is an in-process VectorIndex searching a hierarchical navigable
+// small world graph. With a path the index is loaded from and saved to the file
+// after every change, otherwise it only lives in memory.
+type HNSWVectorIndex struct {
+	mu       sync.RWMutex
+	metric   Metric
+	params   HNSWParams
+	path     string
+	nodes    []*hnswNode
+	entry    int
+	maxLevel int
+	deleted  int
+	keys     map[string]int   // The live node of each media id and sequence number.
+	media    map[string][]int // The live nodes of each media id.
+	resolver MediaScopeResolver
+}
+
+// NewHNSWVectorIndex creates an HNSW vector index, loading it from the path if the
+// file exists. A graph saved with another metric or M is rebuilt.
+func NewHNSWVectorIndex(metric Metric, params HNSWParams, path string) (*HNSWVectorIndex, error) {
+	h := &HNSWVectorIndex{metric: metric, params: params.withDefaults(), path: path}
+	h.reset()
+	if path == "" {
+		return h, nil
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var snapshot hnswSnapshot
+	if err = gob.NewDecoder(f).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("failed to load vector index %s: %w", path, err)
+	}
+	if snapshot.Metric != h.metric || snapshot.Params.M != h.params.M {
+		for _, n := range snapshot.Nodes {
+			if !n.Deleted {
+				h.insert(n)
+			}
+		}
+		return h, nil
+	}
+	h.nodes, h.entry, h.maxLevel = snapshot.Nodes, snapshot.Entry, snapshot.MaxLevel
+	for id, n := range h.nodes {
+		if n.Deleted {
+			h.deleted++
+			continue
+		}
+		h.keys[embeddingKey(n.MediaId, n.SequenceNumber)] = id
+		h.media[n.MediaId] = append(h.media[n.MediaId], id)
+	}
+	return h, nil
+}
+
+func embeddingKey(mediaId string, sequenceNumber int) string {
+	return fmt.Sprintf("%s/%d", mediaId, sequenceNumber)
+}
+
+func (h *HNSWVectorIndex) reset() {
+	h.nodes = make([]*hnswNode, 0)
+	h.entry = -1
+	h.maxLevel = 0
+	h.deleted = 0
+	h.keys = make(map[string]int)
+	h.media = make(map[string][]int)
+}
+
+func (h *HNSWVectorIndex) dimensions() int {
+	for _, n := range h.nodes {
+		return len(n.Vector)
+	}
+	return 0
+}
+
+func (h *HNSWVectorIndex) Upsert(_ context.Context, embeddings []*model.SceneEmbedding) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	dims := h.dimensions()
+	if dims == 0 && len(embeddings) > 0 {
+		dims = len(embeddings[0].Embeddings)
+	}
+	for _, e := range embeddings {
+		if len(e.Embeddings) != dims {
+			return fmt.Errorf("embedding of %s has %d dimensions, the index has %d", embeddingKey(e.Id, e.SequenceNumber), len(e.Embeddings), dims)
+		}
+	}
+	for _, e := range embeddings {
+		if id, ok := h.keys[embeddingKey(e.Id, e.SequenceNumber)]; ok {
+			h.remove(id)
+		}
+		h.insert(&hnswNode{
+			MediaId:        e.Id,
+			SequenceNumber: e.SequenceNumber,
+			ModelName:      e.ModelName,
+			Vector:         slices.Clone(e.Embeddings),
+		})
+	}
+	h.compact()
+	return h.save()
+}
+
+func (h *HNSWVectorIndex) Delete(_ context.Context, mediaId string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// Every node is tombstoned before a rebuild renumbers the nodes
+	for _, id := range slices.Clone(h.media[mediaId]) {
+		h.remove(id)
+	}
+	h.compact()
+	return h.save()
+}
+
+// ResolveScopes sets the resolver of the media scopes of the queries, without one
+// scoped queries fail.
+func (h *HNSWVectorIndex) ResolveScopes(resolver MediaScopeResolver) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resolver = resolver
+}
+
+// scopeMediaIds returns the media ids the query is restricted to, nil is every media.
+func (h *HNSWVectorIndex) scopeMediaIds(ctx context.Context, query *VectorQuery) ([]string, error) {
+	if query.Scope == nil {
+		return query.MediaIds, nil
+	}
+	h.mu.RLock()
+	resolver := h.resolver
+	h.mu.RUnlock()
+	if resolver == nil {
+		return nil, errors.New("the vector index can't resolve media scopes")
+	}
+	inScope, err := resolver(ctx, query.Scope)
+	if err != nil {
+		return nil, err
+	}
+	if query.MediaIds == nil {
+		return inScope, nil
+	}
+	return slices.DeleteFunc(inScope, func(mediaId string) bool { return !slices.Contains(query.MediaIds, mediaId) }), nil
+}
+
+func (h *HNSWVectorIndex) Query(ctx context.Context, query *VectorQuery) ([]*VectorMatch, error) {
+	out := make([]*VectorMatch, 0)
+	mediaIds, err := h.scopeMediaIds(ctx, query)
+	if err != nil {
+		return out, err
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if query.TopK <= 0 || len(h.keys) == 0 {
+		return out, nil
+	}
+	if dims := h.dimensions(); len(query.Vector) != dims {
+		return out, fmt.Errorf("query vector has %d dimensions, the index has %d", len(query.Vector), dims)
+	}
+
+	var candidates []hnswCandidate
+	if mediaIds != nil {
+		// A filtered query is answered exactly from the nodes of the media
+		for _, mediaId := range mediaIds {
+			for _, id := range h.media[mediaId] {
+				candidates = append(candidates, hnswCandidate{id: id, distance: h.metric.Distance(query.Vector, h.nodes[id].Vector)})
+			}
+		}
+		slices.SortFunc(candidates, compareCandidates)
+	} else {
+		entries := []int{h.entry}
+		for layer := h.maxLevel; layer > 0; layer-- {
+			entries = candidateIds(h.searchLayer(query.Vector, entries, 1, layer))
+		}
+		// Widen the search by the tombstones and excluded nodes it may have to step over
+		skipped := h.deleted
+		for _, mediaId := range query.ExcludeMediaIds {
+			skipped += len(h.media[mediaId])
+		}
+		candidates = h.searchLayer(query.Vector, entries, max(h.params.EfSearch, query.TopK)+skipped, 0)
+	}
+
+	for _, c := range candidates {
+		n := h.nodes[c.id]
+		if n.Deleted || slices.Contains(query.ExcludeMediaIds, n.MediaId) {
+			continue
+		}
+		out = append(out, &VectorMatch{
+			MediaId:        n.MediaId,
+			SequenceNumber: n.SequenceNumber,
+			Distance:       c.distance,
+			Similarity:     h.metric.Similarity(c.distance),
+		})
+		if len(out) == query.TopK {
+			break
+		}
+	}
+	return out, nil
+}
+
+func (h *HNSWVectorIndex) Get(_ context.Context, mediaId string) ([]*model.SceneEmbedding, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make([]*model.SceneEmbedding, 0)
+	for _, id := range h.media[mediaId] {
+		n := h.nodes[id]
+		out = append(out, &model.SceneEmbedding{
+			Id:             n.MediaId,
+			SequenceNumber: n.SequenceNumber,
+			ModelName:      n.ModelName,
+			Embeddings:     slices.Clone(n.Vector),
+		})
+	}
+	slices.SortFunc(out, func(a, b *model.SceneEmbedding) int { return a.SequenceNumber - b.SequenceNumber })
+	return out, nil
+}
+
+func (h *HNSWVectorIndex) MediaIds(_ context.Context) ([]string, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	out := make([]string, 0, len(h.media))
+	for mediaId := range h.media {
+		out = append(out, mediaId)
+	}
+	slices.Sort(out)
+	return out, nil
+}
+
+// Unindexed binds the ids of the media in the index, the index lives in process so the
+// ids can't be matched by a subquery.
+func (h *HNSWVectorIndex) Unindexed(ctx context.Context, column string) (string, []bigquery.QueryParameter, error) {
+	indexed, err := h.MediaIds(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return column + " NOT IN UNNEST(@indexed)", []bigquery.QueryParameter{{Name: "indexed", Value: indexed}}, nil
+}
+
+// randomLevel draws the top layer of a new node from an exponentially decaying distribution.
+func (h *HNSWVectorIndex) randomLevel() int {
+	return int(math.Floor(-math.Log(1-rand.Float64()) / math.Log(float64(h.params.M))))
+}
+
+func (h *HNSWVectorIndex) maxFriends(layer int) int {
+	if layer == 0 {
+		return 2 * h.params.M
+	}
+	return h.params.M
+}
+
+func (h *HNSWVectorIndex) insert(n *hnswNode) {
+	id := len(h.nodes)
+	level := h.randomLevel()
+	n.Friends = make([][]int, level+1)
+	n.Deleted = false
+	h.nodes = append(h.nodes, n)
+	h.keys[embeddingKey(n.MediaId, n.SequenceNumber)] = id
+	h.media[n.MediaId] = append(h.media[n.MediaId], id)
+
+	if h.entry < 0 {
+		h.entry, h.maxLevel = id, level
+		return
+	}
+
+	entries := []int{h.entry}
+	for layer := h.maxLevel; layer > level; layer-- {
+		entries = candidateIds(h.searchLayer(n.Vector, entries, 1, layer))
+	}
+	for layer := min(level, h.maxLevel); layer >= 0; layer-- {
+		nearest := h.searchLayer(n.Vector, entries, h.params.EfConstruction, layer)
+		friends := candidateIds(nearest[:min(h.params.M, len(nearest))])
+		n.Friends[layer] = friends
+		for _, f := range friends {
+			friend := h.nodes[f]
+			friend.Friends[layer] = append(friend.Friends[layer], id)
+			if len(friend.Friends[layer]) > h.maxFriends(layer) {
+				h.prune(friend, layer)
+			}
+		}
+		entries = candidateIds(nearest)
+	}
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = id, level
+	}
+}
+
+// prune keeps the nearest neighbours of the node on the layer.
+func (h *HNSWVectorIndex) prune(n *hnswNode, layer int) {
+	candidates := make([]hnswCandidate, len(n.Friends[layer]))
+	for i, f := range n.Friends[layer] {
+		candidates[i] = hnswCandidate{id: f, distance: h.metric.Distance(n.Vector, h.nodes[f].Vector)}
+	}
+	slices.SortFunc(candidates, compareCandidates)
+	n.Friends[layer] = candidateIds(candidates[:h.maxFriends(layer)])
+}
+
+// remove marks the node deleted and rebuilds the graph once tombstones outnumber the live nodes.
+func (h *HNSWVectorIndex) remove(id int) {
+	n := h.nodes[id]
+	n.Deleted = true
+	h.deleted++
+	delete(h.keys, embeddingKey(n.MediaId, n.SequenceNumber))
+	h.media[n.MediaId] = slices.DeleteFunc(h.media[n.MediaId], func(i int) bool { return i == id })
+	if len(h.media[n.MediaId]) == 0 {
+		delete(h.media, n.MediaId)
+	}
+}
+
+// compact rebuilds the graph once the tombstones outnumber the live nodes. The rebuild
+// renumbers the nodes, so node ids held across it are stale.
+func (h *HNSWVectorIndex) compact() {
+	if h.deleted > len(h.keys) {
+		h.rebuild()
+	}
+}
+
+func (h *HNSWVectorIndex) rebuild() {
+	nodes := h.nodes
+	h.reset()
+	for _, n := range nodes {
+		if !n.Deleted {
+			h.insert(n)
+		}
+	}
+}
+
+// searchLayer returns the ef nearest nodes to the vector found on the layer from the entries, nearest first.
+func (h *HNSWVectorIndex) searchLayer(vector []float64, entries []int, ef int, layer int) []hnswCandidate {
+	visited := make(map[int]bool)
+	candidates := &hnswHeap{}
+	results := &hnswHeap{farthest: true}
+	for _, e := range entries {
+		visited[e] = true
+		c := hnswCandidate{id: e, distance: h.metric.Distance(vector, h.nodes[e].Vector)}
+		heap.Push(candidates, c)
+		heap.Push(results, c)
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(hnswCandidate)
+		if results.Len() >= ef && c.distance > results.items[0].distance {
+			break
+		}
+		friends := h.nodes[c.id].Friends
+		if layer >= len(friends) {
+			continue
+		}
+		for _, f := range friends[layer] {
+			if visited[f] {
+				continue
+			}
+			visited[f] = true
+			d := h.metric.Distance(vector, h.nodes[f].Vector)
+			if results.Len() < ef || d < results.items[0].distance {
+				heap.Push(candidates, hnswCandidate{id: f, distance: d})
+				heap.Push(results, hnswCandidate{id: f, distance: d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+	out := results.items
+	slices.SortFunc(out, compareCandidates)
+	return out
+}
+
+// save writes the index to its file, replacing it atomically.
+func (h *HNSWVectorIndex) save() error {
+	if h.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(h.path), filepath.Base(h.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	snapshot := hnswSnapshot{Metric: h.metric, Params: h.params, Nodes: h.nodes, Entry: h.entry, MaxLevel: h.maxLevel}
+	if err = gob.NewEncoder(tempFile).Encode(&snapshot); err != nil {
+		_ = tempFile.Close()
+		_ = os.Remove(tempFile.Name())
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		_ = os.Remove(tempFile.Name())
+		return err
+	}
+	return os.Rename(tempFile.Name(), h.path)
+}
+
+func compareCandidates(a, b hnswCandidate) int {
+	switch {
+	case a.distance < b.distance:
+		return -1
+	case a.distance > b.distance:
+		return 1
+	}
+	return a.id - b.id
+}
+
+func candidateIds(candidates []hnswCandidate) []int {
+	out := make([]int, len(candidates))
+	for i, c := range candidates {
+		out[i] = c.id
+	}
+	return out
+}
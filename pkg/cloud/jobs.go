@@ -0,0 +1,77 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
+)
+
+// DefaultJobStorePath is the directory of the local job store when none is configured.
+var DefaultJobStorePath = filepath.Join(os.TempDir(), "media-search-jobs")
+
+// DefaultJobRetention is how long the local job store keeps finished jobs when none is configured.
+const DefaultJobRetention = 7 * 24 * time.Hour
+
+// NewJobStore creates the job store configured for received messages. Messages are acknowledged
+// once their job is recorded, so the default is the durable "local" store; jobs of the "memory"
+// store, and the messages they were created from, are lost on restart.
+func NewJobStore(config Jobs) (jobs.Store, error) {
+	switch config.Store {
+	case "", "local":
+		path := config.Path
+		if path == "" {
+			path = DefaultJobStorePath
+		}
+		retention := time.Duration(config.RetentionInHours) * time.Hour
+		if config.RetentionInHours == 0 {
+			retention = DefaultJobRetention
+		} else if retention < 0 {
+			retention = 0
+		}
+		return jobs.NewFileStore(path, retention)
+	case "memory":
+		return jobs.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown job store: %s", config.Store)
+	}
+}
+
+// NewPubSubDeadLetter returns a jobs.DeadLetterFunc publishing the payload of failed
+// jobs to the topic, with the job id, attempts and last error added as attributes.
+func NewPubSubDeadLetter(client *pubsub.Client, topicId string) jobs.DeadLetterFunc {
+	topic := client.Topic(topicId)
+	return func(ctx context.Context, job *jobs.Job) error {
+		attributes := make(map[string]string, len(job.Attributes)+3)
+		for k, v := range job.Attributes {
+			attributes[k] = v
+		}
+		attributes["job_id"] = job.Id
+		attributes["job_attempts"] = strconv.Itoa(job.Attempts)
+		attributes["job_error"] = job.LastError
+		result := topic.Publish(ctx, &pubsub.Message{Data: []byte(job.Payload), Attributes: attributes})
+		_, err := result.Get(ctx)
+		return err
+	}
+}
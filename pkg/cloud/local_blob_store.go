@@ -0,0 +1,188 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalBlobStore is a BlobStore on a local directory, each bucket is a sub-directory and
+// each blob a file named by the object name, the same layout as a GCS FUSE mount.
+type LocalBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(root string) *LocalBlobStore {
+	return &LocalBlobStore{root: root}
+}
+
+// LocalPath returns the file path of the blob. Names with ".." segments resolving outside
+// of the bucket directory, and buckets outside of the root, are rejected.
+func (s *LocalBlobStore) LocalPath(bucket string, name string) (string, error) {
+	bucketPath := filepath.Join(s.root, bucket)
+	path := filepath.Join(bucketPath, filepath.FromSlash(name))
+	if !within(s.root, bucketPath) || bucketPath == filepath.Clean(s.root) || !within(bucketPath, path) {
+		return "", fmt.Errorf("%w: %s/%s", ErrInvalidBlobName, bucket, name)
+	}
+	return path, nil
+}
+
+// within returns true if the path is the directory or one of its descendants.
+func within(dir string, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// localError converts the not exist error to ErrBlobNotFound.
+func localError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return errors.Join(ErrBlobNotFound, err)
+	}
+	return err
+}
+
+func (s *LocalBlobStore) Open(_ context.Context, bucket string, name string) (io.ReadCloser, error) {
+	path, err := s.LocalPath(bucket, name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, localError(err)
+	}
+	return file, nil
+}
+
+// localWriter writes to a temp file renamed to the blob on close, so readers never see a partial blob.
+type localWriter struct {
+	*os.File
+	path string
+}
+
+func (w *localWriter) Close() error {
+	if err := w.File.Close(); err != nil {
+		_ = os.Remove(w.Name())
+		return err
+	}
+	return os.Rename(w.Name(), w.path)
+}
+
+func (w *localWriter) Abort() error {
+	_ = w.File.Close()
+	return os.Remove(w.Name())
+}
+
+func (s *LocalBlobStore) Create(_ context.Context, bucket string, name string, _ string) (BlobWriter, error) {
+	path, err := s.LocalPath(bucket, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), ".blob-")
+	if err != nil {
+		return nil, err
+	}
+	return &localWriter{File: file, path: path}, nil
+}
+
+func (s *LocalBlobStore) attrs(bucket string, name string, info fs.FileInfo) *BlobAttrs {
+	return &BlobAttrs{
+		Bucket:      bucket,
+		Name:        name,
+		ContentType: mime.TypeByExtension(filepath.Ext(name)),
+		Size:        info.Size(),
+		Generation:  strconv.FormatInt(info.ModTime().UnixNano(), 10),
+		Updated:     info.ModTime(),
+	}
+}
+
+func (s *LocalBlobStore) Stat(_ context.Context, bucket string, name string) (*BlobAttrs, error) {
+	path, err := s.LocalPath(bucket, name)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, localError(err)
+	}
+	return s.attrs(bucket, name, info), nil
+}
+
+func (s *LocalBlobStore) List(_ context.Context, bucket string, prefix string) ([]*BlobAttrs, error) {
+	out := make([]*BlobAttrs, 0)
+	bucketPath, err := s.LocalPath(bucket, "")
+	if err != nil {
+		return nil, err
+	}
+	err = filepath.WalkDir(bucketPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), ".blob-") {
+			return err
+		}
+		name, err := filepath.Rel(bucketPath, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		out = append(out, s.attrs(bucket, name, info))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return out, nil
+	}
+	return out, err
+}
+
+func (s *LocalBlobStore) Delete(_ context.Context, bucket string, name string) error {
+	path, err := s.LocalPath(bucket, name)
+	if err != nil {
+		return err
+	}
+	return localError(os.Remove(path))
+}
+
+// SignedURL returns a file URL, local files have no access control to sign.
+func (s *LocalBlobStore) SignedURL(_ context.Context, bucket string, name string, _ string, _ time.Duration) (string, error) {
+	path, err := s.LocalPath(bucket, name)
+	if err != nil {
+		return "", err
+	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String(), nil
+}
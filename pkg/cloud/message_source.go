@@ -0,0 +1,127 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// MessageSource delivers GCSPubSubNotification JSON messages to a workflow, either through
+// a job queue or by executing the workflow command directly. Workflows are wired to sources
+// by subscription name, so the same workflows run against Pub/Sub, a local directory, or
+// messages sent in-process.
+type MessageSource interface {
+	// SetCommand sets the command executed for each message when there is no job queue.
+	SetCommand(command cor.Command)
+	// SetQueue sets the job queue receiving the messages.
+	SetQueue(queue *jobs.Queue)
+	// Listen starts delivering messages asynchronously until the context is done.
+	Listen(ctx context.Context)
+}
+
+// The message source types of a TopicSubscription.
+const (
+	MessageSourcePubSub    = "pubsub"
+	MessageSourceDirectory = "directory"
+	MessageSourceChannel   = "channel"
+)
+
+// DefaultPollInterval is the interval a directory source scans its directory at, when not configured.
+const DefaultPollInterval = 5 * time.Second
+
+// NewMessageSource creates the message source configured for the subscription.
+func NewMessageSource(config TopicSubscription, pubsubClient *pubsub.Client) (MessageSource, error) {
+	switch config.Source {
+	case "", MessageSourcePubSub:
+		return NewPubSubListener(pubsubClient, config.Name, nil)
+	case MessageSourceDirectory:
+		if config.Directory == "" {
+			return nil, fmt.Errorf("message source '%s' requires a directory", config.Source)
+		}
+		pollInterval := time.Duration(config.PollIntervalInSeconds) * time.Second
+		if pollInterval <= 0 {
+			pollInterval = DefaultPollInterval
+		}
+		return NewDirectoryMessageSource(config.Directory, config.Bucket, pollInterval), nil
+	case MessageSourceChannel:
+		return NewChannelMessageSource(0), nil
+	default:
+		return nil, fmt.Errorf("unknown message source: %s", config.Source)
+	}
+}
+
+// messageDispatcher implements the delivery shared by the message sources.
+type messageDispatcher struct {
+	command cor.Command // The command to execute when a message is received.
+	queue   *jobs.Queue // The job queue receiving messages, takes precedence over the command.
+}
+
+// SetCommand A setter for the underlying handler command.
+func (d *messageDispatcher) SetCommand(command cor.Command) {
+	// Only set the command if it's not already set.
+	if d.command == nil {
+		d.command = command
+	}
+}
+
+// SetQueue A setter for the job queue, when set messages are accepted once
+// their job is recorded and the command is executed by the queue.
+func (d *messageDispatcher) SetQueue(queue *jobs.Queue) {
+	d.queue = queue
+}
+
+// dispatch delivers the message, an error means the message was not accepted and should be delivered again.
+func (d *messageDispatcher) dispatch(ctx context.Context, id string, data string, attributes map[string]string) error {
+	if d.queue != nil {
+		// A job recorded before the context was cancelled is resumed on restart
+		if job, err := d.queue.Enqueue(ctx, id, data, attributes); job == nil {
+			return fmt.Errorf("error enqueuing message %s: %w", id, err)
+		}
+		return nil
+	}
+	if d.command == nil {
+		return fmt.Errorf("no command or queue for message %s", id)
+	}
+
+	// Start a new span.
+	spanCtx, span := otel.Tracer("message-listener").Start(ctx, "receive-message")
+	defer span.End()
+	span.SetAttributes(attribute.String("msg", data))
+
+	// Create a new chain context.
+	chainCtx := cor.NewConcurrentContext()
+	chainCtx.SetContext(spanCtx)
+	chainCtx.Add(cor.CtxIn, data)
+
+	// Execute the command.
+	d.command.Execute(chainCtx)
+	if !chainCtx.HasErrors() {
+		span.SetStatus(codes.Ok, "success")
+		return nil
+	}
+	span.SetStatus(codes.Error, "failed")
+	return cor.JoinErrors(chainCtx.GetErrors())
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/api/aiplatform/v1"
+	"google.golang.org/api/option"
+)
+
+// DefaultMultimodalDimension is the embedding dimension of the multimodal embedding model.
+const DefaultMultimodalDimension = 1408
+
+// ErrVideoDimension is returned for video clips embedded by a model configured with a lower dimension,
+// clips are always embedded in the DefaultMultimodalDimension so they can't match lower dimension keyframes.
+var ErrVideoDimension = errors.New("video clips can only be embedded in 1408 dimensions")
+
+// MultimodalEmbeddingModel embeds images and video clips into a vector space shared by both,
+// so a still frame can be matched against the keyframes of the scenes.
+type MultimodalEmbeddingModel interface {
+	// EmbedImage returns the embedding of the image, a JPEG, PNG, BMP or GIF.
+	EmbedImage(ctx context.Context, image []byte) ([]float64, error)
+	// EmbedVideo returns the embedding of the video clip, the mean of the embeddings of its segments.
+	EmbedVideo(ctx context.Context, video []byte) ([]float64, error)
+}
+
+// VertexMultimodalEmbeddingModel is a MultimodalEmbeddingModel predicting with a Vertex AI publisher
+// model such as multimodalembedding@001, which the genai client can't embed with.
+type VertexMultimodalEmbeddingModel struct {
+	service   *aiplatform.Service
+	endpoint  string
+	dimension int
+}
+
+// NewVertexMultimodalEmbeddingModel creates the multimodal embedding model of the project and location.
+func NewVertexMultimodalEmbeddingModel(ctx context.Context, projectId string, location string, config MultimodalEmbeddingConfig) (*VertexMultimodalEmbeddingModel, error) {
+	service, err := aiplatform.NewService(ctx, option.WithEndpoint(fmt.Sprintf("https://%s-aiplatform.googleapis.com/", location)))
+	if err != nil {
+		return nil, err
+	}
+	dimension := config.Dimension
+	if dimension <= 0 {
+		dimension = DefaultMultimodalDimension
+	}
+	return &VertexMultimodalEmbeddingModel{
+		service:   service,
+		endpoint:  fmt.Sprintf("projects/%s/locations/%s/publishers/google/models/%s", projectId, location, config.Model),
+		dimension: dimension,
+	}, nil
+}
+
+// multimodalPrediction is a prediction of the multimodal embedding model.
+type multimodalPrediction struct {
+	ImageEmbedding  []float64 `json:"imageEmbedding"`
+	VideoEmbeddings []struct {
+		StartOffsetSec int       `json:"startOffsetSec"`
+		EndOffsetSec   int       `json:"endOffsetSec"`
+		Embedding      []float64 `json:"embedding"`
+	} `json:"videoEmbeddings"`
+}
+
+func (v *VertexMultimodalEmbeddingModel) predict(ctx context.Context, instance map[string]interface{}) (*multimodalPrediction, error) {
+	parameters := map[string]interface{}{}
+	if _, ok := instance["image"]; ok {
+		// The dimension can only be lowered for images, videos are always embedded in full
+		parameters["dimension"] = v.dimension
+	}
+	resp, err := v.service.Projects.Locations.Publishers.Models.Predict(v.endpoint, &aiplatform.GoogleCloudAiplatformV1PredictRequest{
+		Instances:  []interface{}{instance},
+		Parameters: parameters,
+	}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Predictions) == 0 {
+		return nil, errors.New("multimodal embedding model returned no predictions")
+	}
+	// The predictions are untyped JSON values
+	data, err := json.Marshal(resp.Predictions[0])
+	if err != nil {
+		return nil, err
+	}
+	prediction := &multimodalPrediction{}
+	if err = json.Unmarshal(data, prediction); err != nil {
+		return nil, err
+	}
+	return prediction, nil
+}
+
+func (v *VertexMultimodalEmbeddingModel) EmbedImage(ctx context.Context, image []byte) ([]float64, error) {
+	prediction, err := v.predict(ctx, map[string]interface{}{
+		"image": map[string]interface{}{"bytesBase64Encoded": base64.StdEncoding.EncodeToString(image)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(prediction.ImageEmbedding) == 0 {
+		return nil, errors.New("multimodal embedding model returned no image embedding")
+	}
+	return prediction.ImageEmbedding, nil
+}
+
+func (v *VertexMultimodalEmbeddingModel) EmbedVideo(ctx context.Context, video []byte) ([]float64, error) {
+	if v.dimension != DefaultMultimodalDimension {
+		return nil, fmt.Errorf("%w, the keyframes have %d", ErrVideoDimension, v.dimension)
+	}
+	prediction, err := v.predict(ctx, map[string]interface{}{
+		"video": map[string]interface{}{"bytesBase64Encoded": base64.StdEncoding.EncodeToString(video)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(prediction.VideoEmbeddings) == 0 {
+		return nil, errors.New("multimodal embedding model returned no video embeddings")
+	}
+	// A clip longer than a segment is embedded per segment, the clip is their mean
+	out := make([]float64, len(prediction.VideoEmbeddings[0].Embedding))
+	for _, segment := range prediction.VideoEmbeddings {
+		if len(segment.Embedding) != len(out) {
+			return nil, errors.New("multimodal embedding model returned segments of different dimensions")
+		}
+		for i, value := range segment.Embedding {
+			out[i] += value / float64(len(prediction.VideoEmbeddings))
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// Notifier delivers the alerts of saved searches.
+type Notifier interface {
+	Notify(ctx context.Context, alert *model.SavedSearchAlert) error
+}
+
+// LogNotifier is a Notifier writing the alerts to the log.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, alert *model.SavedSearchAlert) error {
+	out, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	log.Printf("saved search alert: %s", out)
+	return nil
+}
+
+// DefaultWebhookTimeout is the timeout of a post of a WebhookNotifier without one.
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookNotifier is a Notifier posting the alerts as JSON to a URL,
+// responses other than 2xx are errors.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (w *WebhookNotifier) Notify(ctx context.Context, alert *model.SavedSearchAlert) error {
+	out, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(out))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
+
+// NewNotifier creates the notifier configured for saved search alerts.
+func NewNotifier(config SavedSearchConfig) (Notifier, error) {
+	switch config.Notifier {
+	case "", "log":
+		return LogNotifier{}, nil
+	case "webhook":
+		if config.WebhookUrl == "" {
+			return nil, errors.New("notifier 'webhook' requires a webhook_url")
+		}
+		return NewWebhookNotifier(config.WebhookUrl, time.Duration(config.WebhookTimeoutInSeconds)*time.Second), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier: %s", config.Notifier)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+)
+
+// QueryBuilder composes a BigQuery SELECT statement. Values are bound as named
+// query parameters and never formatted into the statement, so conditions from
+// different features, such as filters and pagination, compose safely.
+// Errors, such as a parameter bound twice, are reported by Build.
+type QueryBuilder struct {
+	columns []string
+	from    []string
+	where   []string
+	orderBy []string
+	limit   int
+	params  []bigquery.QueryParameter
+	err     error
+}
+
+// Select starts a query of the columns.
+func Select(columns ...string) *QueryBuilder {
+	return &QueryBuilder{columns: columns}
+}
+
+// QuoteTable returns the table name quoted as a BigQuery identifier.
+func QuoteTable(table string) (string, error) {
+	if table == "" || strings.ContainsAny(table, "`\\\n") {
+		return "", fmt.Errorf("invalid table name: %q", table)
+	}
+	return "`" + table + "`", nil
+}
+
+// From adds the table with the alias to the FROM clause, the alias may be empty.
+func (b *QueryBuilder) From(table string, alias string) *QueryBuilder {
+	quoted, err := QuoteTable(table)
+	if err != nil {
+		b.fail(err)
+		return b
+	}
+	b.from = append(b.from, strings.TrimSpace(quoted+" "+alias))
+	return b
+}
+
+// Unnest cross joins the array column, e.g. Unnest("m.scenes", "s").
+func (b *QueryBuilder) Unnest(column string, alias string) *QueryBuilder {
+	b.from = append(b.from, fmt.Sprintf("UNNEST(%s) %s", column, alias))
+	return b
+}
+
+// Where adds a condition, conditions are combined with AND. The condition
+// refers to the values by the names of the parameters bound with it.
+func (b *QueryBuilder) Where(condition string, params ...bigquery.QueryParameter) *QueryBuilder {
+	if condition == "" {
+		return b
+	}
+	b.where = append(b.where, condition)
+	return b.Bind(params...)
+}
+
+// OrderBy adds the ordering expressions.
+func (b *QueryBuilder) OrderBy(expressions ...string) *QueryBuilder {
+	b.orderBy = append(b.orderBy, expressions...)
+	return b
+}
+
+// Limit limits the number of rows, bound as @limit, zero is unlimited.
+func (b *QueryBuilder) Limit(limit int) *QueryBuilder {
+	if limit <= 0 {
+		return b
+	}
+	b.limit = limit
+	return b.Bind(bigquery.QueryParameter{Name: "limit", Value: limit})
+}
+
+// Bind binds the named parameters, used by the select columns or ordering expressions.
+func (b *QueryBuilder) Bind(params ...bigquery.QueryParameter) *QueryBuilder {
+	for _, p := range params {
+		for _, bound := range b.params {
+			if bound.Name == p.Name {
+				b.fail(fmt.Errorf("query parameter @%s is bound twice", p.Name))
+				return b
+			}
+		}
+		b.params = append(b.params, p)
+	}
+	return b
+}
+
+func (b *QueryBuilder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// Build returns the statement and its parameters.
+func (b *QueryBuilder) Build() (string, []bigquery.QueryParameter, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	if len(b.columns) == 0 || len(b.from) == 0 {
+		return "", nil, fmt.Errorf("query requires columns and a table")
+	}
+	sql := strings.Builder{}
+	sql.WriteString("SELECT ")
+	sql.WriteString(strings.Join(b.columns, ", "))
+	sql.WriteString(" FROM ")
+	sql.WriteString(strings.Join(b.from, ", "))
+	if len(b.where) == 1 {
+		sql.WriteString(" WHERE " + b.where[0])
+	} else if len(b.where) > 1 {
+		sql.WriteString(" WHERE (" + strings.Join(b.where, ") AND (") + ")")
+	}
+	if len(b.orderBy) > 0 {
+		sql.WriteString(" ORDER BY " + strings.Join(b.orderBy, ", "))
+	}
+	if b.limit > 0 {
+		sql.WriteString(" LIMIT @limit")
+	}
+	return sql.String(), b.params, nil
+}
+
+// Query returns the built query on the client.
+func (b *QueryBuilder) Query(client *bigquery.Client) (*bigquery.Query, error) {
+	sql, params, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	q := client.Query(sql)
+	q.Parameters = params
+	return q, nil
+}
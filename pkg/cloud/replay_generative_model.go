@@ -0,0 +1,114 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"google.golang.org/genai"
+)
+
+// ErrRecordingNotFound is returned by a ReplayGenerativeModel without a recording for the request.
+var ErrRecordingNotFound = errors.New("recorded response not found")
+
+// ReplayGenerativeModel is a deterministic GenerativeModel serving responses recorded on disk,
+// one JSON file per request named by RecordingKey. When a recorder is set, requests without
+// a recording are sent to the recorder and its responses are recorded.
+type ReplayGenerativeModel struct {
+	dir      string
+	recorder GenerativeModel
+}
+
+func NewReplayGenerativeModel(dir string, recorder GenerativeModel) *ReplayGenerativeModel {
+	return &ReplayGenerativeModel{dir: dir, recorder: recorder}
+}
+
+// RecordingKey returns the key of the recording for the request, a hash of the request.
+func RecordingKey(systemInstruction string, contents []*genai.Content, outputSchema *genai.Schema) (string, error) {
+	request, err := json.Marshal(struct {
+		SystemInstruction string           `json:"system_instruction"`
+		Contents          []*genai.Content `json:"contents"`
+		OutputSchema      *genai.Schema    `json:"output_schema"`
+	}{systemInstruction, contents, outputSchema})
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(request)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (r *ReplayGenerativeModel) GenerateContent(ctx context.Context, systemInstruction string, contents []*genai.Content, outputSchema *genai.Schema) (*genai.GenerateContentResponse, error) {
+	key, err := RecordingKey(systemInstruction, contents, outputSchema)
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(r.dir, key+".json")
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		resp := &genai.GenerateContentResponse{}
+		if err = json.Unmarshal(data, resp); err != nil {
+			return nil, cor.Permanent(fmt.Errorf("invalid recording %s: %w", path, err))
+		}
+		return resp, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if r.recorder == nil {
+		// Retrying will not create the recording
+		return nil, cor.Permanent(fmt.Errorf("%w: %s", ErrRecordingNotFound, path))
+	}
+
+	resp, err := r.recorder.GenerateContent(ctx, systemInstruction, contents, outputSchema)
+	if err != nil {
+		return nil, err
+	}
+	return resp, r.Record(key, resp)
+}
+
+// Record saves the response as the recording for the key.
+func (r *ReplayGenerativeModel) Record(key string, resp *genai.GenerateContentResponse) error {
+	data, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(r.dir, 0o755); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(r.dir, ".recording-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), filepath.Join(r.dir, key+".json"))
+}
@@ -25,18 +25,29 @@ import (
 	"cloud.google.com/go/bigquery"
 	"cloud.google.com/go/pubsub"
 	"cloud.google.com/go/storage"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
 	"google.golang.org/genai"
 )
 
 // ServiceClients is the state machine for the cloud clients.
 type ServiceClients struct {
 	StorageClient   *storage.Client                         // The Google Cloud Storage client.
+	BlobStore       BlobStore                               // The storage of media files.
 	PubsubClient    *pubsub.Client                          // The Google Cloud Pub/Sub client.
 	GenAIClient     *genai.Client                           // The Google Cloud Vertex AI client.
 	BiqQueryClient  *bigquery.Client                        // The Google Cloud BigQuery client.
-	PubSubListeners map[string]*PubSubListener              // A map of Pub/Sub listeners, keyed by subscription name.
+	MessageSources  map[string]MessageSource                // A map of message sources, keyed by subscription name.
 	EmbeddingModels map[string]*genai.Models                // A map of Vertex AI embedding models, keyed by model name.
 	AgentModels     map[string]*QuotaAwareGenerativeAIModel // A map of Vertex AI LLM models, keyed by model name.
+	CheckpointStore cor.CheckpointStore                     // The ingestion checkpoint store, nil when disabled.
+	JobStore        jobs.Store                              // The store of jobs created from received messages.
+	VectorIndex     *NotifyingVectorIndex                   // The nearest neighbour index of the scene embeddings.
+	AnalyticsSink   AnalyticsSink                           // The sink of search and feedback events, nil when disabled.
+	Notifier        Notifier                                // The notifier of saved search alerts.
+
+	MultimodalEmbeddingModel MultimodalEmbeddingModel // The embedding model of images and video clips, nil when not configured.
+	MultimodalIndex          VectorIndex              // The nearest neighbour index of the scene keyframe embeddings, nil when not configured.
 }
 
 // Close A close method to ensure all clients are shut down,
@@ -78,11 +89,11 @@ func NewCloudServiceClients(ctx context.Context, config *Config) (cloud *Service
 		return nil, err
 	}
 
-	// Create Pub/Sub listeners based on the configuration.
-	subscriptions := make(map[string]*PubSubListener)
+	// Create message sources based on the configuration.
+	subscriptions := make(map[string]MessageSource)
 	for sub := range config.TopicSubscriptions {
 		values := config.TopicSubscriptions[sub]
-		actual, err := NewPubSubListener(pc, values.Name, nil)
+		actual, err := NewMessageSource(values, pc)
 		if err != nil {
 			return nil, err
 		}
@@ -109,19 +120,91 @@ func NewCloudServiceClients(ctx context.Context, config *Config) (cloud *Service
 			ResponseMIMEType:  values.OutputFormat,
 			Tools:             []*genai.Tool{},
 		}
-		wrappedAgent := NewQuotaAwareModel(generateContentConfig, values.Model, gc.Models, values.RateLimit)
+		provider, err := NewGenerativeModel(values, NewVertexGenerativeModel(generateContentConfig, values.Model, gc.Models))
+		if err != nil {
+			return nil, err
+		}
+		wrappedAgent := NewQuotaAwareModel(provider, values.Model, values.RateLimit)
 		agentModels[am] = wrappedAgent
 	}
 
+	// Create the media file storage based on the configuration.
+	blobStore, err := NewBlobStore(config.Storage, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the ingestion checkpoint store based on the configuration.
+	checkpointStore, err := NewCheckpointStore(config.Checkpoint, sc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the job store based on the configuration.
+	jobStore, err := NewJobStore(config.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the vector index based on the configuration.
+	textIndex, err := NewVectorIndex(config.VectorIndex, config.BigQueryDataSource, config.EmbeddingModels["multi-lingual"].Model, bc)
+	if err != nil {
+		return nil, err
+	}
+	vectorIndex := NewNotifyingVectorIndex(textIndex)
+
+	// Create the multimodal embedding model and the index of the keyframe embeddings, when configured.
+	var multimodalModel MultimodalEmbeddingModel
+	var multimodalIndex VectorIndex
+	if config.MultimodalEmbedding.Model != "" {
+		multimodalModel, err = NewVertexMultimodalEmbeddingModel(ctx, config.Application.GoogleProjectId, config.Application.GoogleLocation, config.MultimodalEmbedding)
+		if err != nil {
+			return nil, err
+		}
+		// The keyframe embeddings don't have the dimension of the text embeddings, they have their own table
+		indexConfig := config.VectorIndex
+		indexConfig.Path = config.MultimodalEmbedding.IndexPath
+		dataSource := config.BigQueryDataSource
+		dataSource.EmbeddingTable = dataSource.KeyframeEmbeddingTable
+		if dataSource.EmbeddingTable == "" {
+			dataSource.EmbeddingTable = DefaultKeyframeEmbeddingTable
+		}
+		multimodalIndex, err = NewVectorIndex(indexConfig, dataSource, config.MultimodalEmbedding.Model, bc)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the search analytics sink based on the configuration.
+	analyticsSink, err := NewAnalyticsSink(config.Analytics, config.BigQueryDataSource, bc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create the notifier of saved search alerts based on the configuration.
+	notifier, err := NewNotifier(config.SavedSearches)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create a new ServiceClients instance with all the initialized clients.
 	cloud = &ServiceClients{
 		StorageClient:   sc,
+		BlobStore:       blobStore,
 		PubsubClient:    pc,
 		GenAIClient:     gc,
 		BiqQueryClient:  bc,
-		PubSubListeners: subscriptions,
+		MessageSources:  subscriptions,
 		EmbeddingModels: embeddingModels,
 		AgentModels:     agentModels,
+		CheckpointStore: checkpointStore,
+		JobStore:        jobStore,
+		VectorIndex:     vectorIndex,
+		AnalyticsSink:   analyticsSink,
+		Notifier:        notifier,
+
+		MultimodalEmbeddingModel: multimodalModel,
+		MultimodalIndex:          multimodalIndex,
 	}
 
 	return cloud, err
@@ -25,7 +25,9 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
 	"go.opentelemetry.io/otel/metric"
 
 	"github.com/BurntSushi/toml"
@@ -42,6 +44,11 @@ const (
 	MaxRetries          = 3
 )
 
+// GenerateRetryPolicy is the retry policy for multi-modal requests, see GenerateMultiModalResponse.
+// Each attempt goes through the QuotaRetryPolicy of the model, and the whole request is repeated
+// by the retry policies of the command and its job queue, see [retry_policies] in .env.toml.
+var GenerateRetryPolicy = cor.NewRetryPolicy(MaxRetries+1, time.Second)
+
 // Simple utility to see if a file exists
 func fileExists(in string) bool {
 	_, err := os.Stat(in)
@@ -84,42 +91,50 @@ func LoadConfig(baseConfig interface{}) {
 }
 
 // GenerateMultiModalResponse A GenAI helper function for executing multi-modal requests with a retry limit.
+// The request is retried when the model returns an error or an empty response, tryCount is the number
+// of attempts already made by the caller.
 func GenerateMultiModalResponse(
 	ctx context.Context,
 	inputTokenCounter metric.Int64Counter,
 	outputTokenCounter metric.Int64Counter,
 	retryCounter metric.Int64Counter,
 	tryCount int,
-	model *QuotaAwareGenerativeAIModel,
+	model GenerativeModel,
 	systemInstruction string,
 	contents []*genai.Content,
 	outputSchema *genai.Schema) (value string, err error) {
-	resp, err := model.GenerateContent(ctx, systemInstruction, contents, outputSchema)
-	inputTokenCounter.Add(ctx, int64(resp.UsageMetadata.PromptTokenCount))
-	outputTokenCounter.Add(ctx, int64(resp.UsageMetadata.CandidatesTokenCount))
-	if err != nil {
-		if tryCount < MaxRetries {
+	policy := *GenerateRetryPolicy
+	policy.MaxAttempts = MaxRetries + 1 - tryCount
+	err = policy.Do(ctx, func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
 			retryCounter.Add(ctx, 1)
-			return GenerateMultiModalResponse(ctx, inputTokenCounter, outputTokenCounter, retryCounter, tryCount+1, model, systemInstruction, contents, outputSchema)
-		} else {
-			return "", err
 		}
-	}
-	for _, candidate := range resp.Candidates {
-		if candidate.Content != nil {
-			for _, part := range candidate.Content.Parts {
-				value += fmt.Sprint(part.Text)
+		resp, err := model.GenerateContent(ctx, systemInstruction, contents, outputSchema)
+		if resp != nil && resp.UsageMetadata != nil {
+			inputTokenCounter.Add(ctx, int64(resp.UsageMetadata.PromptTokenCount))
+			outputTokenCounter.Add(ctx, int64(resp.UsageMetadata.CandidatesTokenCount))
+		}
+		if err != nil {
+			return err
+		}
+		value = ""
+		for _, candidate := range resp.Candidates {
+			if candidate.Content != nil {
+				for _, part := range candidate.Content.Parts {
+					value += fmt.Sprint(part.Text)
+				}
 			}
 		}
-	}
-	if len(value) == 0 {
-		log.Println("Empty response from model, retrying...")
-		if tryCount < MaxRetries {
-			retryCounter.Add(ctx, 1)
-			return GenerateMultiModalResponse(ctx, inputTokenCounter, outputTokenCounter, retryCounter, tryCount+1, model, systemInstruction, contents, outputSchema)
-		} else {
-			return "", errors.New("no candidates returned from model after retries")
+		if len(value) == 0 {
+			if attempt < policy.MaxAttempts {
+				log.Printf("Empty response from model on attempt %d of %d, retrying...", attempt, policy.MaxAttempts)
+			}
+			return fmt.Errorf("no candidates returned from model on attempt %d of %d", attempt, policy.MaxAttempts)
 		}
+		return nil
+	})
+	if err != nil {
+		return "", err
 	}
 	return value, nil
 }
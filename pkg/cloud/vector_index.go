@@ -0,0 +1,249 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/api/iterator"
+)
+
+// Metric is the distance metric of a vector index, smaller distances are nearer.
+type Metric string
+
+const (
+	MetricEuclidean Metric = "euclidean" // The euclidean distance.
+	MetricCosine    Metric = "cosine"    // One minus the cosine similarity.
+	MetricDot       Metric = "dot"       // The negative dot product.
+)
+
+// ParseMetric returns the metric by name, empty is euclidean.
+func ParseMetric(in string) (Metric, error) {
+	switch metric := Metric(strings.ToLower(in)); metric {
+	case "":
+		return MetricEuclidean, nil
+	case MetricEuclidean, MetricCosine, MetricDot:
+		return metric, nil
+	default:
+		return "", fmt.Errorf("unknown distance metric: %s", in)
+	}
+}
+
+// Distance returns the distance between the vectors, the vectors must have the same length.
+func (m Metric) Distance(a []float64, b []float64) float64 {
+	switch m {
+	case MetricCosine:
+		var dot, na, nb float64
+		for i := range a {
+			dot += a[i] * b[i]
+			na += a[i] * a[i]
+			nb += b[i] * b[i]
+		}
+		if na == 0 || nb == 0 {
+			return 1
+		}
+		return 1 - dot/(math.Sqrt(na)*math.Sqrt(nb))
+	case MetricDot:
+		var dot float64
+		for i := range a {
+			dot += a[i] * b[i]
+		}
+		return -dot
+	default:
+		var sum float64
+		for i := range a {
+			d := a[i] - b[i]
+			sum += d * d
+		}
+		return math.Sqrt(sum)
+	}
+}
+
+// Similarity converts a distance of the metric to a similarity, larger is more similar:
+// the cosine similarity for cosine, the dot product for dot and 1 / (1 + distance),
+// from 1 for identical vectors towards 0, for euclidean.
+func (m Metric) Similarity(distance float64) float64 {
+	switch m {
+	case MetricCosine:
+		return 1 - distance
+	case MetricDot:
+		return -distance
+	default:
+		return 1 / (1 + distance)
+	}
+}
+
+// VectorMatch is a scene embedding matched by a vector query.
+type VectorMatch struct {
+	MediaId        string  `json:"media_id" bigquery:"media_id"`
+	SequenceNumber int     `json:"sequence_number" bigquery:"sequence_number"`
+	Distance       float64 `json:"distance" bigquery:"distance"`
+	Similarity     float64 `json:"similarity" bigquery:"-"` // The similarity of the distance, see Metric.Similarity.
+}
+
+// MediaScope is a condition on a media table, restricting a vector query to the scenes
+// of the matching media. BigQuery indexes apply it as a subquery of the media table,
+// in-process indexes resolve it to the media ids.
+type MediaScope struct {
+	Table  string                    // The fully qualified media table.
+	Alias  string                    // The alias of the table in the condition.
+	Where  string                    // The condition on the media, empty is every media.
+	Params []bigquery.QueryParameter // The parameters of the condition.
+}
+
+// Select returns the query of the ids of the media in scope.
+func (s *MediaScope) Select() *QueryBuilder {
+	return Select(s.Alias+".id AS id").From(s.Table, s.Alias).Where(s.Where, s.Params...)
+}
+
+// MediaScopeResolver returns the ids of the media in scope.
+type MediaScopeResolver func(ctx context.Context, scope *MediaScope) ([]string, error)
+
+// BigQueryMediaScopeResolver resolves media scopes with queries of the client.
+func BigQueryMediaScopeResolver(client *bigquery.Client) MediaScopeResolver {
+	return func(ctx context.Context, scope *MediaScope) (out []string, err error) {
+		out = make([]string, 0)
+		q, err := scope.Select().Query(client)
+		if err != nil {
+			return out, err
+		}
+		itr, err := q.Read(ctx)
+		if err != nil {
+			return out, err
+		}
+		for {
+			var row struct {
+				Id string `bigquery:"id"`
+			}
+			err = itr.Next(&row)
+			if errors.Is(err, iterator.Done) {
+				return out, nil
+			}
+			if err != nil {
+				return out, err
+			}
+			out = append(out, row.Id)
+		}
+	}
+}
+
+// VectorQuery is a top-k nearest neighbour query.
+type VectorQuery struct {
+	Vector   []float64   // The query vector.
+	TopK     int         // The number of matches.
+	MediaIds []string    // Restricts the matches to the scenes of the media, nil is every media.
+	Scope    *MediaScope // Restricts the matches to the scenes of the media in scope, nil is every media.
+
+	ExcludeMediaIds []string // Excludes the scenes of the media from the matches.
+}
+
+// VectorIndex is the nearest neighbour index of the scene embeddings.
+type VectorIndex interface {
+	// Upsert adds the embeddings, replacing those of the same media and sequence number.
+	Upsert(ctx context.Context, embeddings []*model.SceneEmbedding) error
+	// Delete removes the embeddings of the media.
+	Delete(ctx context.Context, mediaId string) error
+	// Query returns the nearest embeddings to the query vector, nearest first.
+	Query(ctx context.Context, query *VectorQuery) ([]*VectorMatch, error)
+	// Get returns the embeddings of the media ordered by sequence number, none if it isn't indexed.
+	Get(ctx context.Context, mediaId string) ([]*model.SceneEmbedding, error)
+	// MediaIds returns the ids of the media with embeddings in the index.
+	MediaIds(ctx context.Context) ([]string, error)
+	// Unindexed returns a condition on the id column of a media query, with its parameters,
+	// matching the media without embeddings in the index.
+	Unindexed(ctx context.Context, column string) (string, []bigquery.QueryParameter, error)
+}
+
+// DefaultKeyframeEmbeddingTable is the table of the keyframe embeddings when none is configured.
+const DefaultKeyframeEmbeddingTable = "keyframe_embeddings"
+
+// The vector index backends of the VectorIndexConfig.
+const (
+	VectorIndexBigQuery = "bigquery"
+	VectorIndexHNSW     = "hnsw"
+)
+
+// NewVectorIndex creates the vector index configured for the scene embeddings of the model.
+func NewVectorIndex(config VectorIndexConfig, dataSource BigQueryDataSource, modelName string, client *bigquery.Client) (VectorIndex, error) {
+	metric, err := ParseMetric(config.Metric)
+	if err != nil {
+		return nil, err
+	}
+	switch config.Backend {
+	case "", VectorIndexBigQuery:
+		return NewBigQueryVectorIndex(client, dataSource.DatasetName, dataSource.EmbeddingTable, modelName, metric), nil
+	case VectorIndexHNSW:
+		index, err := NewHNSWVectorIndex(metric, HNSWParams{
+			M:              config.M,
+			EfConstruction: config.EfConstruction,
+			EfSearch:       config.EfSearch,
+		}, config.Path)
+		if err != nil {
+			return nil, err
+		}
+		index.ResolveScopes(BigQueryMediaScopeResolver(client))
+		return index, nil
+	default:
+		return nil, fmt.Errorf("unknown vector index: %s", config.Backend)
+	}
+}
+
+// NotifyingVectorIndex is a VectorIndex calling its listeners after embeddings are upserted or deleted,
+// so caches of query results can be invalidated when new embeddings land.
+type NotifyingVectorIndex struct {
+	VectorIndex
+	mu        sync.RWMutex
+	listeners []func()
+}
+
+// NewNotifyingVectorIndex wraps the index.
+func NewNotifyingVectorIndex(index VectorIndex) *NotifyingVectorIndex {
+	return &NotifyingVectorIndex{VectorIndex: index}
+}
+
+// OnChange adds a listener called after every change of the index.
+func (n *NotifyingVectorIndex) OnChange(listener func()) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.listeners = append(n.listeners, listener)
+}
+
+func (n *NotifyingVectorIndex) notify() {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	for _, listener := range n.listeners {
+		listener()
+	}
+}
+
+func (n *NotifyingVectorIndex) Upsert(ctx context.Context, embeddings []*model.SceneEmbedding) error {
+	// A failed upsert may have changed part of the index
+	defer n.notify()
+	return n.VectorIndex.Upsert(ctx, embeddings)
+}
+
+func (n *NotifyingVectorIndex) Delete(ctx context.Context, mediaId string) error {
+	defer n.notify()
+	return n.VectorIndex.Delete(ctx, mediaId)
+}
@@ -19,67 +19,45 @@ package cloud
 
 import (
 	"context"
-	"errors"
 	"log"
 	"time"
 
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
 	"golang.org/x/time/rate"
 	"google.golang.org/genai"
 )
 
-// QuotaAwareGenerativeAIModel wraps a genai.GenerativeModel with rate limiting.
+// QuotaRetryPolicy waits one minute for the quota to recover before retrying a failed generation.
+var QuotaRetryPolicy = &cor.RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Minute}
+
+// QuotaAwareGenerativeAIModel wraps a GenerativeModel with rate limiting.
 type QuotaAwareGenerativeAIModel struct {
-	GenerativeContentConfig *genai.GenerateContentConfig // The configuration for LLM content genration.
-	ModelName               string
-	ModelHandle             *genai.Models
-	RateLimit               rate.Limiter // The rate limiter for the LLM.
+	Model     GenerativeModel // The wrapped model.
+	ModelName string
+	RateLimit rate.Limiter // The rate limiter for the LLM.
 }
 
 // NewQuotaAwareModel creates a new QuotaAwareGenerativeAIModel with the given rate limit.
-func NewQuotaAwareModel(wrapped *genai.GenerateContentConfig, modelName string, modelHandle *genai.Models, requestsPerSecond int) *QuotaAwareGenerativeAIModel {
+func NewQuotaAwareModel(model GenerativeModel, modelName string, requestsPerSecond int) *QuotaAwareGenerativeAIModel {
 	return &QuotaAwareGenerativeAIModel{
-		GenerativeContentConfig: wrapped,
-		ModelName:               modelName,
-		ModelHandle:             modelHandle,
-		RateLimit:               *rate.NewLimiter(rate.Every(time.Second/1), requestsPerSecond),
+		Model:     model,
+		ModelName: modelName,
+		RateLimit: *rate.NewLimiter(rate.Every(time.Second/1), requestsPerSecond),
 	}
 }
 
 // GenerateContent generates content using the wrapped LLM with rate limiting.
 func (q *QuotaAwareGenerativeAIModel) GenerateContent(ctx context.Context, systemInstruction string, contents []*genai.Content, outputSchema *genai.Schema) (resp *genai.GenerateContentResponse, err error) {
-	// Create a copy of the generative content config to avoid modifying the original.
-	config := *q.GenerativeContentConfig
-
-	// set the desired output schema, take it from
-	if outputSchema != nil {
-		config.ResponseSchema = outputSchema
-	}
-
-	if systemInstruction != "" {
-		config.SystemInstruction = genai.NewContentFromText(systemInstruction, genai.RoleUser)
-	}
 	// Check if the rate limit allows a request.
 	if q.RateLimit.Allow() {
-		// If allowed, make the request to the LLM.
-		resp, err = q.ModelHandle.GenerateContent(ctx, q.ModelName, contents, &config)
-		if err != nil {
-			log.Printf("Error generating content: %v", err)
-			// If there's an error, check the retry count from the context.
-			retryCount, ok := ctx.Value("retry").(int)
-			if !ok {
-				// This is the first attempt.
-				retryCount = 0
-			}
-			if retryCount > 3 {
-				// If retry count exceeds the limit, return an error.
-				return nil, errors.New("failed generation on max retries")
+		// If allowed, make the request to the LLM, waiting for the quota to recover on errors.
+		err = QuotaRetryPolicy.Do(ctx, func(ctx context.Context, _ int) error {
+			resp, err = q.Model.GenerateContent(ctx, systemInstruction, contents, outputSchema)
+			if err != nil {
+				log.Printf("Error generating content: %v", err)
 			}
-			// If retries are allowed, wait for one minute and try again.
-			errCtx := context.WithValue(ctx, "retry", retryCount+1)
-			time.Sleep(time.Minute * 1)
-			return q.ModelHandle.GenerateContent(errCtx, q.ModelName, contents, &config)
-		}
-		// If successful, return the response.
+			return err
+		})
 		return resp, err
 	} else {
 		// If rate limit is exceeded, wait for 5 seconds and try again.
@@ -0,0 +1,213 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/api/iterator"
+)
+
+// QryUpsertActors merges the @actors into the actor table, the aliases of known actors are replaced
+// by those resolved, which include the known ones.
+const QryUpsertActors = "MERGE `%s` t USING UNNEST(@actors) r ON t.id = r.id " +
+	"WHEN MATCHED THEN UPDATE SET aliases = IFNULL(r.aliases, []), normalized_aliases = IFNULL(r.normalized_aliases, []) " +
+	"WHEN NOT MATCHED THEN INSERT (id, create_date, name, aliases, normalized_aliases, awards, nominations) " +
+	"VALUES (r.id, r.create_date, r.name, IFNULL(r.aliases, []), IFNULL(r.normalized_aliases, []), [], [])"
+
+// UnknownActorNames are the normalized names of cast members the model could not identify.
+var UnknownActorNames = map[string]bool{"": true, "unknown": true, "unidentified": true, "uncredited": true, "n a": true, "none": true}
+
+// ActorRegistry registers the actors of the cast of the media in the actor table,
+// resolving the actor names to known actors by their normalized name or aliases.
+type ActorRegistry struct {
+	cor.BaseCommand
+	client     *bigquery.Client
+	dataset    string
+	table      string
+	mediaParam string
+}
+
+func NewActorRegistry(name string, client *bigquery.Client, dataset string, table string, mediaParam string) *ActorRegistry {
+	return &ActorRegistry{BaseCommand: *cor.NewBaseCommand(name), client: client, dataset: dataset, table: table, mediaParam: mediaParam}
+}
+
+func (a *ActorRegistry) IsExecutable(context cor.Context) bool {
+	return context != nil && context.Get(a.mediaParam) != nil
+}
+
+func (a *ActorRegistry) GetRequiredParams() []string {
+	return []string{a.mediaParam}
+}
+
+func (a *ActorRegistry) GetProducedParams() []string {
+	return []string{}
+}
+
+func (a *ActorRegistry) Execute(context cor.Context) {
+	media, ok := cor.NewKey[*model.Media](a.mediaParam).MustGet(context, a.GetName())
+	if !ok {
+		a.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	names := make([]string, 0)
+	for _, member := range media.Cast {
+		names = append(names, member.ActorName)
+	}
+	if err := a.register(context.GetContext(), names); err != nil {
+		a.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(a.GetName(), err)
+		return
+	}
+	a.GetSuccessCounter().Add(context.GetContext(), 1)
+}
+
+func (a *ActorRegistry) fqTable() string {
+	return strings.Replace(a.client.Dataset(a.dataset).Table(a.table).FullyQualifiedName(), ":", ".", -1)
+}
+
+// register resolves the names against the known actors and upserts the new and changed actors.
+func (a *ActorRegistry) register(ctx context.Context, names []string) error {
+	ids := make([]string, 0)
+	normalized := make([]string, 0)
+	for _, name := range names {
+		ids = append(ids, model.NewActor(name).Id)
+		normalized = append(normalized, model.NormalizeActorName(name))
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	known, err := a.known(ctx, ids, normalized)
+	if err != nil {
+		return err
+	}
+	changed := ResolveActors(names, known)
+	if len(changed) == 0 {
+		return nil
+	}
+	rows := make([]model.Actor, len(changed))
+	for i, actor := range changed {
+		rows[i] = *actor
+	}
+	q := a.client.Query(fmt.Sprintf(QryUpsertActors, a.fqTable()))
+	q.Parameters = []bigquery.QueryParameter{{Name: "actors", Value: rows}}
+	job, err := q.Run(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
+
+// known returns the actors with one of the ids or one of the normalized names as a normalized alias.
+func (a *ActorRegistry) known(ctx context.Context, ids []string, names []string) (out []*model.Actor, err error) {
+	out = make([]*model.Actor, 0)
+	q, err := cloud.Select("*").From(a.fqTable(), "").
+		Where("id IN UNNEST(@ids) OR EXISTS (SELECT 1 FROM UNNEST(normalized_aliases) alias WHERE alias IN UNNEST(@names))",
+			bigquery.QueryParameter{Name: "ids", Value: ids},
+			bigquery.QueryParameter{Name: "names", Value: names}).
+		Query(a.client)
+	if err != nil {
+		return out, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		var actor = &model.Actor{}
+		err = itr.Next(actor)
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, actor)
+	}
+}
+
+// ResolveActors resolves the names to the known actors, by their normalized name or aliases,
+// and returns the actors to upsert: those created for unresolved names and the known actors
+// a new spelling was added to as an alias. Names of unknown actors are skipped. Only names
+// equal once normalized resolve to the same actor, misspellings are new actors.
+func ResolveActors(names []string, known []*model.Actor) []*model.Actor {
+	changed := make([]*model.Actor, 0)
+	isChanged := make(map[string]bool)
+	byName := make(map[string]*model.Actor)
+	for _, actor := range known {
+		// Actors registered before their aliases were normalized are upserted with them
+		stored := actor.NormalizedAliases
+		if actor.NormalizeAliases(); !slices.Equal(stored, actor.NormalizedAliases) {
+			isChanged[actor.Id] = true
+			changed = append(changed, actor)
+		}
+		byName[model.NormalizeActorName(actor.Name)] = actor
+		for _, alias := range actor.Aliases {
+			if _, ok := byName[model.NormalizeActorName(alias)]; !ok {
+				byName[model.NormalizeActorName(alias)] = actor
+			}
+		}
+	}
+
+	for _, name := range names {
+		key := model.NormalizeActorName(name)
+		if UnknownActorNames[key] {
+			continue
+		}
+		spelling := strings.Join(strings.Fields(name), " ")
+		actor, ok := byName[key]
+		if !ok {
+			actor = model.NewActor(name)
+			byName[key] = actor
+		} else if !hasSpelling(actor, spelling) {
+			actor.Aliases = append(actor.Aliases, spelling)
+			actor.NormalizeAliases()
+		} else {
+			continue
+		}
+		if !isChanged[actor.Id] {
+			isChanged[actor.Id] = true
+			changed = append(changed, actor)
+		}
+	}
+	return changed
+}
+
+// hasSpelling returns whether the spelling is the name or an alias of the actor.
+func hasSpelling(actor *model.Actor, spelling string) bool {
+	if actor.Name == spelling {
+		return true
+	}
+	for _, alias := range actor.Aliases {
+		if alias == spelling {
+			return true
+		}
+	}
+	return false
+}
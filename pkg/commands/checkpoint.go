@@ -0,0 +1,41 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands
+
+import (
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/google/uuid"
+)
+
+// CheckpointKey returns the checkpoint key of the media file being processed.
+// The key is the id of the media file and the generation of its GCS object,
+// so a file uploaded again under the same name is processed from the start.
+func CheckpointKey(context cor.Context) (string, error) {
+	gcsFile, err := cloud.GCSObjectKey.Get(context)
+	if err != nil {
+		return "", err
+	}
+	generation := gcsFile.Generation
+	if generation == "" {
+		generation = "0"
+	}
+	mediaId := uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("gs://%s/%s", gcsFile.Bucket, gcsFile.Name)))
+	return fmt.Sprintf("%s-%s", mediaId, generation), nil
+}
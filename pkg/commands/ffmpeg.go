@@ -22,7 +22,6 @@ import (
 	"io"
 	"log"
 	"path/filepath"
-	"time"
 
 	"os"
 	"os/exec"
@@ -47,47 +46,52 @@ type FFMpegCommand struct {
 	commandPath string
 	targetWidth string
 	config      *cloud.Config
+	blobStore   cloud.BlobStore
 }
 
-func NewFFMpegCommand(name string, commandPath string, targetWidth string, config *cloud.Config) *FFMpegCommand {
+func NewFFMpegCommand(name string, commandPath string, targetWidth string, config *cloud.Config, blobStore cloud.BlobStore) *FFMpegCommand {
 	return &FFMpegCommand{
 		BaseCommand: *cor.NewBaseCommand(name),
 		commandPath: commandPath,
 		targetWidth: targetWidth,
-		config:      config}
+		config:      config,
+		blobStore:   blobStore}
 }
 
 // Execute executes the business logic of the command
 func (c *FFMpegCommand) Execute(context cor.Context) {
-	msg := context.Get(c.GetInputParam()).(*cloud.GCSObject)
-	inputFileName := fmt.Sprintf("%s/%s/%s", c.config.Storage.GCSFuseMountPoint, msg.Bucket, msg.Name)
+	msg, ok := cor.NewKey[*cloud.GCSObject](c.GetInputParam()).MustGet(context, c.GetName())
+	if !ok {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	log.Printf("Received message for media file: %s/%s", msg.Bucket, msg.Name)
 
-	var err error
-	for i := range FileCheckRetries {
-		if _, err = os.Stat(inputFileName); err == nil {
-			break
-		}
-		log.Printf("waiting for file to appear: %s, attempt %d/%d", inputFileName, i+1, FileCheckRetries)
-		time.Sleep(FileCheckDelay)
+	if err := waitForBlob(context.GetContext(), c.blobStore, msg.Bucket, msg.Name); err != nil {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(c.GetName(), fmt.Errorf("file: %s/%s not found after several retries. Error: %w", msg.Bucket, msg.Name, err))
+		return
 	}
 
+	inputFileName, cleanup, err := cloud.CopyToLocalFile(context.GetContext(), c.blobStore, msg.Bucket, msg.Name)
 	if err != nil {
 		c.GetErrorCounter().Add(context.GetContext(), 1)
-		context.AddError(c.GetName(), fmt.Errorf("file: %s not found after several retries. Error: %w", inputFileName, err))
+		context.AddError(c.GetName(), err)
 		return
 	}
+	defer cleanup()
 
-	file, err := os.Open(inputFileName)
+	tempFile, err := os.CreateTemp("", TempFilePrefix)
 	if err != nil {
 		c.GetErrorCounter().Add(context.GetContext(), 1)
 		context.AddError(c.GetName(), err)
 		return
 	}
-	tempFile, _ := os.CreateTemp("", TempFilePrefix)
+	_ = tempFile.Close()
+	defer os.Remove(tempFile.Name())
 
-	args := fmt.Sprintf(DefaultFfmpegArgs, file.Name(), c.targetWidth, tempFile.Name())
-	cmd := exec.Command(c.commandPath, strings.Split(args, CommandSeparator)...)
+	args := fmt.Sprintf(DefaultFfmpegArgs, inputFileName, c.targetWidth, tempFile.Name())
+	cmd := exec.CommandContext(context.GetContext(), c.commandPath, strings.Split(args, CommandSeparator)...)
 	cmd.Stderr = os.Stderr
 
 	if err := cmd.Run(); err != nil {
@@ -101,12 +105,14 @@ func (c *FFMpegCommand) Execute(context cor.Context) {
 		outputName = strings.TrimSuffix(outputName, ext) + ".mp4"
 	}
 
-	outputFile := fmt.Sprintf("%s/%s/%s", c.config.Storage.GCSFuseMountPoint, c.config.Storage.LowResOutputBucket, outputName)
-
-	MoveFile(tempFile.Name(), outputFile)
+	outputBucket := c.config.Storage.LowResOutputBucket
+	if err := cloud.CopyFromLocalFile(context.GetContext(), c.blobStore, tempFile.Name(), outputBucket, outputName, "video/mp4"); err != nil {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(c.GetName(), fmt.Errorf("error writing resized file: %w", err))
+		return
+	}
 	c.GetSuccessCounter().Add(context.GetContext(), 1)
-	context.AddTempFile(outputFile)
-	context.Add(cor.CtxOut, outputFile)
+	context.Add(cor.CtxOut, fmt.Sprintf("%s/%s", outputBucket, outputName))
 }
 
 func MoveFile(sourcePath, destPath string) error {
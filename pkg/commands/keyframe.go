@@ -0,0 +1,98 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// DefaultKeyframeArgs seeks to the offset, in seconds, of the input and writes a single JPEG frame to stdout.
+const DefaultKeyframeArgs = "-hide_banner -loglevel error -ss %.3f -i %s -frames:v 1 -f image2 -c:v mjpeg pipe:1"
+
+// ParseMediaUrl returns the bucket and object name of a media URL, see MediaUrlFormat.
+func ParseMediaUrl(mediaUrl string) (bucket string, name string, err error) {
+	prefix := strings.TrimSuffix(MediaUrlFormat, "%s/%s")
+	path, found := strings.CutPrefix(mediaUrl, prefix)
+	if !found {
+		return "", "", fmt.Errorf("not a media url: %s", mediaUrl)
+	}
+	bucket, name, found = strings.Cut(path, "/")
+	if !found || bucket == "" || name == "" {
+		return "", "", fmt.Errorf("not a media url: %s", mediaUrl)
+	}
+	return bucket, name, nil
+}
+
+// ParseTimestamp returns the offset of a scene timestamp, formatted as HH:MM:SS.
+func ParseTimestamp(timestamp string) (time.Duration, error) {
+	parts := strings.Split(timestamp, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid timestamp: %s", timestamp)
+	}
+	var seconds int
+	for _, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return 0, fmt.Errorf("invalid timestamp: %s", timestamp)
+		}
+		seconds = seconds*60 + value
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// KeyframeOffset returns the offset of the keyframe of the scene, the middle of the scene.
+func KeyframeOffset(scene *model.Scene) (time.Duration, error) {
+	start, err := ParseTimestamp(scene.Start)
+	if err != nil {
+		return 0, err
+	}
+	end, err := ParseTimestamp(scene.End)
+	if err != nil || end < start {
+		return start, nil
+	}
+	return start + (end-start)/2, nil
+}
+
+// ExtractKeyframe returns the frame of the video file at the offset as a JPEG.
+func ExtractKeyframe(ctx context.Context, commandPath string, inputFileName string, offset time.Duration) ([]byte, error) {
+	args := strings.Split(fmt.Sprintf(DefaultKeyframeArgs, offset.Seconds(), "%s"), CommandSeparator)
+	for i, arg := range args {
+		// The file name may contain the separator, so it is set after splitting
+		if arg == "%s" {
+			args[i] = inputFileName
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, commandPath, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running ffmpeg: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if stdout.Len() == 0 {
+		return nil, fmt.Errorf("no frame at %s of %s", offset, inputFileName)
+	}
+	return stdout.Bytes(), nil
+}
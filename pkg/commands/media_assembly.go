@@ -36,34 +36,54 @@ const (
 
 type MediaAssembly struct {
 	cor.BaseCommand
-	summaryParam     string
-	sceneParam       string
+	summaryKey       cor.Key[*model.MediaSummary]
+	sceneKey         cor.Key[[]string]
 	mediaObjectParam string
-	mediaLengthParam string
+	mediaLengthKey   cor.Key[int]
 }
 
 // NewMediaAssembly default constructor for MediaAssembly
 func NewMediaAssembly(name string, summaryParam string, sceneParam string, mediaObjectParam string, mediaLengthParam string) *MediaAssembly {
 	return &MediaAssembly{
 		BaseCommand:      *cor.NewBaseCommand(name),
-		summaryParam:     summaryParam,
-		sceneParam:       sceneParam,
+		summaryKey:       cor.NewKey[*model.MediaSummary](summaryParam),
+		sceneKey:         cor.NewKey[[]string](sceneParam),
 		mediaObjectParam: mediaObjectParam,
-		mediaLengthParam: mediaLengthParam,
+		mediaLengthKey:   cor.NewKey[int](mediaLengthParam),
 	}
 }
 
 // IsExecutable overrides the default to verify the summary param and scene param are in the context
 func (m *MediaAssembly) IsExecutable(context cor.Context) bool {
 	return context != nil &&
-		context.Get(m.summaryParam) != nil &&
-		context.Get(m.sceneParam) != nil
+		context.Get(m.summaryKey.Name()) != nil &&
+		context.Get(m.sceneKey.Name()) != nil
+}
+
+func (m *MediaAssembly) GetRequiredParams() []string {
+	return []string{m.summaryKey.Name(), m.sceneKey.Name(), m.mediaLengthKey.Name()}
+}
+
+func (m *MediaAssembly) GetProducedParams() []string {
+	return []string{m.mediaObjectParam, cor.CtxOut}
 }
 
 func (m *MediaAssembly) Execute(context cor.Context) {
-	summary := context.Get(m.summaryParam).(*model.MediaSummary)
-	jsonScenes := context.Get(m.sceneParam).([]string)
-	mediaLengthInSeconds := context.Get(m.mediaLengthParam).(int)
+	summary, ok := m.summaryKey.MustGet(context, m.GetName())
+	if !ok {
+		m.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	jsonScenes, ok := m.sceneKey.MustGet(context, m.GetName())
+	if !ok {
+		m.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	mediaLengthInSeconds, ok := m.mediaLengthKey.MustGet(context, m.GetName())
+	if !ok {
+		m.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	sceneValues := fmt.Sprintf("[ %s ]", strings.Join(jsonScenes, ","))
 
 	scenes := make([]*model.Scene, 0)
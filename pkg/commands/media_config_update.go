@@ -39,8 +39,21 @@ func NewMediaConfigUpdateCommand(name string, config *cloud.Config, templateServ
 		templateService: templateService}
 }
 
+func (m *MediaConfigUpdateCommand) GetRequiredParams() []string {
+	return []string{m.GetInputParam(), cloud.GCSObjectKey.Name()}
+}
+
+// GetProducedParams the command updates the configuration in place and produces no parameters.
+func (m *MediaConfigUpdateCommand) GetProducedParams() []string {
+	return []string{}
+}
+
 func (m *MediaConfigUpdateCommand) Execute(context cor.Context) {
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, m.GetName())
+	if !ok {
+		m.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	configurationFilePrefix := os.Getenv(cloud.EnvConfigFilePrefix)
 	if len(configurationFilePrefix) > 0 && !strings.HasSuffix(configurationFilePrefix, string(os.PathSeparator)) {
 		configurationFilePrefix = configurationFilePrefix + string(os.PathSeparator)
@@ -32,7 +32,7 @@ type MediaContentTypeCommand struct {
 	cor.BaseCommand
 	templateService          *cloud.TemplateService
 	config                   *cloud.Config
-	generativeAIModel        *cloud.QuotaAwareGenerativeAIModel
+	generativeAIModel        cloud.GenerativeModel
 	geminiInputTokenCounter  metric.Int64Counter
 	geminiOutputTokenCounter metric.Int64Counter
 	geminiRetryCounter       metric.Int64Counter
@@ -41,7 +41,7 @@ type MediaContentTypeCommand struct {
 func NewMediaContentTypeCommand(
 	name string,
 	config *cloud.Config,
-	generativeAIModel *cloud.QuotaAwareGenerativeAIModel,
+	generativeAIModel cloud.GenerativeModel,
 	templateService *cloud.TemplateService,
 	outputParamName string) *MediaContentTypeCommand {
 
@@ -60,8 +60,20 @@ func NewMediaContentTypeCommand(
 	return &out
 }
 
+func (c *MediaContentTypeCommand) GetRequiredParams() []string {
+	return []string{c.GetInputParam(), cloud.GCSObjectKey.Name()}
+}
+
+func (c *MediaContentTypeCommand) GetProducedParams() []string {
+	return []string{c.GetOutputParam(), cor.CtxOut}
+}
+
 func (c *MediaContentTypeCommand) Execute(context cor.Context) {
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, c.GetName())
+	if !ok {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	gcsFileLink := fmt.Sprintf("gs://%s/%s", gcsFile.Bucket, gcsFile.Name)
 
 	params := make(map[string]interface{})
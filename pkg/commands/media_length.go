@@ -17,6 +17,7 @@
 package commands
 
 import (
+	goctx "context"
 	"fmt"
 	"log"
 	"os"
@@ -35,46 +36,63 @@ const (
 	FileCheckRetries = 5
 	// FileCheckDelay is the time to wait between file existence checks.
 	FileCheckDelay = 10 * time.Second
+	// ProbeURLExpiry is the lifetime of the signed URL ffprobe reads a remote media file from.
+	ProbeURLExpiry = 15 * time.Minute
 )
 
+// FileCheckRetryPolicy polls for a file to appear in the blob store, e.g. on a GCS FUSE mount.
+var FileCheckRetryPolicy = &cor.RetryPolicy{MaxAttempts: FileCheckRetries, InitialBackoff: FileCheckDelay}
+
 type MediaLengthCommand struct {
 	cor.BaseCommand
 	commandPath string
 	config      *cloud.Config
+	blobStore   cloud.BlobStore
 }
 
-func NewMediaLengthCommand(name string, commandPath string, outputParamName string, config *cloud.Config) *MediaLengthCommand {
+func NewMediaLengthCommand(name string, commandPath string, outputParamName string, config *cloud.Config, blobStore cloud.BlobStore) *MediaLengthCommand {
 	out := MediaLengthCommand{
 		BaseCommand: *cor.NewBaseCommand(name),
 		commandPath: commandPath,
 		config:      config,
+		blobStore:   blobStore,
 	}
 	out.OutputParamName = outputParamName
 	return &out
 }
 
+func (c *MediaLengthCommand) GetRequiredParams() []string {
+	return []string{c.GetInputParam(), cloud.GCSObjectKey.Name()}
+}
+
+func (c *MediaLengthCommand) GetProducedParams() []string {
+	return []string{c.GetOutputParam(), cor.CtxOut}
+}
+
 func (c *MediaLengthCommand) Execute(context cor.Context) {
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
-	inputFileName := fmt.Sprintf("%s/%s/%s", c.config.Storage.GCSFuseMountPoint, gcsFile.Bucket, gcsFile.Name)
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, c.GetName())
+	if !ok {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	log.Printf("Received message for media file: %s/%s", gcsFile.Bucket, gcsFile.Name)
 
-	var err error
-	for i := range FileCheckRetries {
-		if _, err = os.Stat(inputFileName); err == nil {
-			break
-		}
-		log.Printf("waiting for file to appear: %s, attempt %d/%d", inputFileName, i+1, FileCheckRetries)
-		time.Sleep(FileCheckDelay)
+	if err := waitForBlob(context.GetContext(), c.blobStore, gcsFile.Bucket, gcsFile.Name); err != nil {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(c.GetName(), fmt.Errorf("file: %s/%s not found after several retries. Error: %w", gcsFile.Bucket, gcsFile.Name, err))
+		return
 	}
 
+	input, cleanup, err := probeInput(context.GetContext(), c.blobStore, gcsFile.Bucket, gcsFile.Name)
 	if err != nil {
 		c.GetErrorCounter().Add(context.GetContext(), 1)
-		context.AddError(c.GetName(), fmt.Errorf("file: %s not found after several retries. Error: %w", inputFileName, err))
+		context.AddError(c.GetName(), err)
 		return
 	}
+	defer cleanup()
 
-	args := fmt.Sprintf(DefaultVideoDurationCmdArgs, inputFileName)
-	cmd := exec.Command(c.commandPath, strings.Split(args, CommandSeparator)...)
+	args := fmt.Sprintf(DefaultVideoDurationCmdArgs, input)
+	cmd := exec.CommandContext(context.GetContext(), c.commandPath, strings.Split(args, CommandSeparator)...)
 	cmd.Stderr = os.Stderr
 	output, err := cmd.Output()
 	if err != nil {
@@ -104,3 +122,30 @@ func extractVideoLengthToFullSeconds(output []byte) (int, error) {
 	}
 	return 0, fmt.Errorf("got invalid video duration: %s", s)
 }
+
+// probeInput returns the input of ffprobe for the blob. Remote blobs are read from a signed URL,
+// ffprobe only fetches the ranges holding the container headers rather than the whole file;
+// they are downloaded if the URL can't be signed, e.g. with credentials unable to sign.
+func probeInput(ctx goctx.Context, blobStore cloud.BlobStore, bucket string, name string) (string, func(), error) {
+	if local, ok := blobStore.(cloud.LocalBlobStorer); ok {
+		path, err := local.LocalPath(bucket, name)
+		return path, func() {}, err
+	}
+	url, err := blobStore.SignedURL(ctx, bucket, name, "GET", ProbeURLExpiry)
+	if err == nil {
+		return url, func() {}, nil
+	}
+	log.Printf("failed to sign the URL of %s/%s, downloading it: %v", bucket, name, err)
+	return cloud.CopyToLocalFile(ctx, blobStore, bucket, name)
+}
+
+// waitForBlob waits for the blob to appear using the FileCheckRetryPolicy.
+func waitForBlob(ctx goctx.Context, blobStore cloud.BlobStore, bucket string, name string) error {
+	return FileCheckRetryPolicy.Do(ctx, func(ctx goctx.Context, attempt int) error {
+		_, err := blobStore.Stat(ctx, bucket, name)
+		if err != nil {
+			log.Printf("waiting for file to appear: %s/%s, attempt %d/%d", bucket, name, attempt, FileCheckRetryPolicy.MaxAttempts)
+		}
+		return err
+	})
+}
@@ -41,10 +41,26 @@ func (s *MediaPersistToBigQuery) IsExecutable(context cor.Context) bool {
 	return context != nil && context.Get(s.mediaParam) != nil
 }
 
+func (s *MediaPersistToBigQuery) GetRequiredParams() []string {
+	return []string{s.mediaParam, cloud.GCSObjectKey.Name()}
+}
+
+func (s *MediaPersistToBigQuery) GetProducedParams() []string {
+	return []string{cor.CtxOut}
+}
+
 func (s *MediaPersistToBigQuery) Execute(context cor.Context) {
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	log.Printf("Persisting data for: %s/%s", gcsFile.Bucket, gcsFile.Name)
-	media := context.Get(s.mediaParam).(*model.Media)
+	media, ok := cor.NewKey[*model.Media](s.mediaParam).MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	i := s.client.Dataset(s.dataset).Table(s.table).Inserter()
 	if err := i.Put(context.GetContext(), media); err != nil {
 		log.Printf("failed to write media to database. title %s error %s\n", media.Title, err)
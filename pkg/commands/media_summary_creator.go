@@ -34,7 +34,7 @@ import (
 type MediaSummaryCreator struct {
 	cor.BaseCommand
 	config                     *cloud.Config
-	generativeAIModel          *cloud.QuotaAwareGenerativeAIModel
+	generativeAIModel          cloud.GenerativeModel
 	templateService            *cloud.TemplateService
 	contentTypeParamName       string
 	mediaLengthOutputParamName string
@@ -46,7 +46,7 @@ type MediaSummaryCreator struct {
 func NewMediaSummaryCreator(
 	name string,
 	config *cloud.Config,
-	generativeAIModel *cloud.QuotaAwareGenerativeAIModel,
+	generativeAIModel cloud.GenerativeModel,
 	templateService *cloud.TemplateService,
 	mediaLengthOutputParamName string,
 	contentTypeParamName string) *MediaSummaryCreator {
@@ -67,8 +67,15 @@ func NewMediaSummaryCreator(
 	return out
 }
 
-func (t *MediaSummaryCreator) GenerateParams(context cor.Context) map[string]interface{} {
-	mediaLengthInSeconds := context.Get(t.mediaLengthOutputParamName).(int)
+func (t *MediaSummaryCreator) GetRequiredParams() []string {
+	return []string{t.GetInputParam(), cloud.GCSObjectKey.Name(), t.contentTypeParamName, t.mediaLengthOutputParamName}
+}
+
+func (t *MediaSummaryCreator) GenerateParams(context cor.Context) (map[string]interface{}, error) {
+	mediaLengthInSeconds, err := cor.NewKey[int](t.mediaLengthOutputParamName).Get(context)
+	if err != nil {
+		return nil, err
+	}
 	params := make(map[string]interface{})
 
 	// Create a string representation of the categories
@@ -81,16 +88,37 @@ func (t *MediaSummaryCreator) GenerateParams(context cor.Context) map[string]int
 	exampleSummary, _ := json.Marshal(model.GetExampleSummary())
 	params["EXAMPLE_JSON"] = string(exampleSummary)
 	params["VIDEO_LENGTH"] = fmt.Sprintf("%d", mediaLengthInSeconds)
-	return params
+	return params, nil
 }
 
 func (t *MediaSummaryCreator) Execute(context cor.Context) {
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, t.GetName())
+	if !ok {
+		t.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	gcsFileLink := fmt.Sprintf("gs://%s/%s", gcsFile.Bucket, gcsFile.Name)
-	mediaType := context.Get(t.contentTypeParamName).(string)
+	mediaType, ok := cor.NewKey[string](t.contentTypeParamName).MustGet(context, t.GetName())
+	if !ok {
+		t.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	promptTemplate := t.templateService.GetTemplateBy(mediaType)
+	if promptTemplate == nil {
+		t.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(t.GetName(), fmt.Errorf("no prompt template for media type: %s", mediaType))
+		return
+	}
+
+	params, err := t.GenerateParams(context)
+	if err != nil {
+		t.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(t.GetName(), err)
+		return
+	}
 
 	var buffer bytes.Buffer
-	err := t.templateService.GetTemplateBy(mediaType).SummaryPrompt.Execute(&buffer, t.GenerateParams(context))
+	err = promptTemplate.SummaryPrompt.Execute(&buffer, params)
 	if err != nil {
 		t.GetErrorCounter().Add(context.GetContext(), 1)
 		context.AddError(t.GetName(), err)
@@ -106,7 +134,7 @@ func (t *MediaSummaryCreator) Execute(context cor.Context) {
 	}
 
 	// Get the response
-	out, err := cloud.GenerateMultiModalResponse(context.GetContext(), t.geminiInputTokenCounter, t.geminiOutputTokenCounter, t.geminiRetryCounter, 0, t.generativeAIModel, t.templateService.GetTemplateBy(mediaType).SystemInstructions, contents, model.NewMediaSummarySchema())
+	out, err := cloud.GenerateMultiModalResponse(context.GetContext(), t.geminiInputTokenCounter, t.geminiOutputTokenCounter, t.geminiRetryCounter, 0, t.generativeAIModel, promptTemplate.SystemInstructions, contents, model.NewMediaSummarySchema())
 	if err != nil {
 		t.GetErrorCounter().Add(context.GetContext(), 1)
 		context.AddError(t.GetName(), err)
@@ -26,6 +26,9 @@ import (
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
 )
 
+// MediaUrlFormat is the URL of the media file of a summary, formatted with the bucket and object name.
+const MediaUrlFormat = "https://storage.mtls.cloud.google.com/%s/%s"
+
 type MediaSummaryJsonToStruct struct {
 	cor.BaseCommand
 }
@@ -36,9 +39,25 @@ func NewMediaSummaryJsonToStruct(name string, outputParamName string) *MediaSumm
 	return &out
 }
 
+func (s *MediaSummaryJsonToStruct) GetRequiredParams() []string {
+	return []string{s.GetInputParam(), cloud.GCSObjectKey.Name()}
+}
+
+func (s *MediaSummaryJsonToStruct) GetProducedParams() []string {
+	return []string{s.GetOutputParam(), cor.CtxOut}
+}
+
 func (s *MediaSummaryJsonToStruct) Execute(context cor.Context) {
-	in := context.Get(s.GetInputParam()).(string)
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
+	in, ok := cor.NewKey[string](s.GetInputParam()).MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 
 	doc := &model.MediaSummary{}
 	err := json.Unmarshal([]byte(in), &doc)
@@ -48,7 +67,7 @@ func (s *MediaSummaryJsonToStruct) Execute(context cor.Context) {
 		return
 	}
 	s.GetSuccessCounter().Add(context.GetContext(), 1)
-	doc.MediaUrl = fmt.Sprintf("https://storage.mtls.cloud.google.com/%s/%s", gcsFile.Bucket, gcsFile.Name)
+	doc.MediaUrl = fmt.Sprintf(MediaUrlFormat, gcsFile.Bucket, gcsFile.Name)
 	context.Add(s.GetOutputParam(), doc)
 	context.Add(cor.CtxOut, doc)
 }
@@ -32,8 +32,17 @@ func NewMediaTriggerToGCSObject(name string) *MediaTriggerToGCSObject {
 	return &MediaTriggerToGCSObject{BaseCommand: *cor.NewBaseCommand(name)}
 }
 
+// GetProducedParams the command produces the GCS object and its output parameter.
+func (c *MediaTriggerToGCSObject) GetProducedParams() []string {
+	return []string{cloud.GCSObjectKey.Name(), c.GetOutputParam()}
+}
+
 func (c *MediaTriggerToGCSObject) Execute(context cor.Context) {
-	in := context.Get(c.GetInputParam()).(string)
+	in, ok := cor.NewKey[string](c.GetInputParam()).MustGet(context, c.GetName())
+	if !ok {
+		c.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	var out cloud.GCSPubSubNotification
 	err := json.Unmarshal([]byte(in), &out)
 	if err != nil {
@@ -44,7 +53,7 @@ func (c *MediaTriggerToGCSObject) Execute(context cor.Context) {
 
 	c.GetSuccessCounter().Add(context.GetContext(), 1)
 
-	msg := &cloud.GCSObject{Bucket: out.Bucket, Name: out.Name, MIMEType: out.ContentType}
-	context.Add(cloud.GetGCSObjectName(), msg)
+	msg := &cloud.GCSObject{Bucket: out.Bucket, Name: out.Name, Generation: out.Generation, MIMEType: out.ContentType}
+	cloud.GCSObjectKey.Set(context, msg)
 	context.Add(c.GetOutputParam(), msg)
 }
@@ -0,0 +1,61 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands
+
+import (
+	"encoding/json"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// SavedSearchAlertCommand sends the saved search alert of its input, a JSON payload, to the notifier.
+// Run on a job queue, alerts the notifier fails to send are retried.
+type SavedSearchAlertCommand struct {
+	cor.BaseCommand
+	notifier cloud.Notifier
+}
+
+func NewSavedSearchAlertCommand(name string, notifier cloud.Notifier) *SavedSearchAlertCommand {
+	return &SavedSearchAlertCommand{BaseCommand: *cor.NewBaseCommand(name), notifier: notifier}
+}
+
+// GetProducedParams the command notifies the alert and produces no parameters.
+func (s *SavedSearchAlertCommand) GetProducedParams() []string {
+	return []string{}
+}
+
+func (s *SavedSearchAlertCommand) Execute(context cor.Context) {
+	payload, ok := cor.NewKey[string](s.GetInputParam()).MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	alert := &model.SavedSearchAlert{}
+	if err := json.Unmarshal([]byte(payload), alert); err != nil {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(s.GetName(), cor.Permanent(err))
+		return
+	}
+	if err := s.notifier.Notify(context.GetContext(), alert); err != nil {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(s.GetName(), err)
+		return
+	}
+	s.GetSuccessCounter().Add(context.GetContext(), 1)
+}
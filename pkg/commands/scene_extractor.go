@@ -40,7 +40,7 @@ import (
 
 type SceneExtractor struct {
 	cor.BaseCommand
-	generativeAIModel        *cloud.QuotaAwareGenerativeAIModel
+	generativeAIModel        cloud.GenerativeModel
 	templateService          *cloud.TemplateService
 	numberOfWorkers          int
 	geminiInputTokenCounter  metric.Int64Counter
@@ -51,7 +51,7 @@ type SceneExtractor struct {
 
 func NewSceneExtractor(
 	name string,
-	model *cloud.QuotaAwareGenerativeAIModel,
+	model cloud.GenerativeModel,
 	templateService *cloud.TemplateService,
 	numberOfWorkers int,
 	contentTypeParamName string) *SceneExtractor {
@@ -72,14 +72,40 @@ func NewSceneExtractor(
 func (s *SceneExtractor) IsExecutable(context cor.Context) bool {
 	return context != nil &&
 		context.Get(s.GetInputParam()) != nil &&
-		context.Get(cloud.GetGCSObjectName()) != nil
+		context.Get(cloud.GCSObjectKey.Name()) != nil
+}
+
+func (s *SceneExtractor) GetRequiredParams() []string {
+	return []string{s.GetInputParam(), cloud.GCSObjectKey.Name(), s.contentTypeParamName}
+}
+
+func (s *SceneExtractor) GetProducedParams() []string {
+	return []string{s.GetOutputParam(), cor.CtxOut}
 }
 
 func (s *SceneExtractor) Execute(context cor.Context) {
-	summary := context.Get(s.GetInputParam()).(*model.MediaSummary)
-	gcsFile := context.Get(cloud.GetGCSObjectName()).(*cloud.GCSObject)
+	summary, ok := cor.NewKey[*model.MediaSummary](s.GetInputParam()).MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	gcsFile, ok := cloud.GCSObjectKey.MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
 	gcsFileLink := fmt.Sprintf("gs://%s/%s", gcsFile.Bucket, gcsFile.Name)
-	mediaType := context.Get(s.contentTypeParamName).(string)
+	mediaType, ok := cor.NewKey[string](s.contentTypeParamName).MustGet(context, s.GetName())
+	if !ok {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		return
+	}
+	promptTemplate := s.templateService.GetTemplateBy(mediaType)
+	if promptTemplate == nil {
+		s.GetErrorCounter().Add(context.GetContext(), 1)
+		context.AddError(s.GetName(), fmt.Errorf("no prompt template for media type: %s", mediaType))
+		return
+	}
 	videoFile := &genai.FileData{
 		FileURI:  gcsFileLink,
 		MIMEType: gcsFile.MIMEType,
@@ -108,7 +134,7 @@ func (s *SceneExtractor) Execute(context cor.Context) {
 
 	// Execute all scenes against the worker pool
 	for i, ts := range summary.SceneTimeStamps {
-		job := CreateJob(context.GetContext(), s.Tracer, s.geminiInputTokenCounter, s.geminiOutputTokenCounter, s.geminiRetryCounter, i, s.GetName(), summaryText, exampleText, *s.templateService.GetTemplateBy(mediaType).ScenePrompt, videoFile, s.generativeAIModel, ts)
+		job := CreateJob(context.GetContext(), s.Tracer, s.geminiInputTokenCounter, s.geminiOutputTokenCounter, s.geminiRetryCounter, i, s.GetName(), summaryText, exampleText, *promptTemplate.ScenePrompt, videoFile, s.generativeAIModel, ts)
 		jobs <- job
 	}
 
@@ -121,7 +147,7 @@ func (s *SceneExtractor) Execute(context cor.Context) {
 	for r := range results {
 		if r.err != nil {
 			s.GetErrorCounter().Add(context.GetContext(), 1)
-			context.AddError(s.GetName(), r.err)
+			context.AppendError(s.GetName(), r.err)
 		} else {
 
 			sceneData = append(sceneData, r.value)
@@ -150,7 +176,7 @@ type SceneJob struct {
 	timeSpan                 *model.TimeSpan
 	span                     trace.Span
 	contents                 []*genai.Content
-	model                    *cloud.QuotaAwareGenerativeAIModel
+	model                    cloud.GenerativeModel
 	err                      error
 }
 
@@ -171,7 +197,7 @@ func CreateJob(
 	exampleText string,
 	template template.Template,
 	videoFile *genai.FileData,
-	model *cloud.QuotaAwareGenerativeAIModel,
+	model cloud.GenerativeModel,
 	timeSpan *model.TimeSpan,
 ) *SceneJob {
 	sceneCtx, sceneSpan := tracer.Start(ctx, fmt.Sprintf("%s_genai", commandName))
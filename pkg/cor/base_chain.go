@@ -101,3 +101,32 @@ func (c *BaseChain) Execute(chCtx Context) {
 	}
 	chainSpan.End()
 }
+
+// Validate checks the wiring of the chain, the chain input (CtxIn) is expected
+// to be provided by the caller. See ValidationReport.
+func (c *BaseChain) Validate() *ValidationReport {
+	v := newValidation(c.GetName())
+	c.validate(map[string]bool{CtxIn: true}, v)
+	return v.complete()
+}
+
+// validate walks the commands in order, mapping each command's CtxOut to the next CtxIn.
+func (c *BaseChain) validate(available map[string]bool, v *validation) []string {
+	current := make(map[string]bool, len(available))
+	for param := range available {
+		current[param] = true
+	}
+	produced := make([]string, 0)
+	for _, command := range c.commands {
+		out := v.command(command, current)
+		for _, param := range out {
+			if !isPipeParam(param) {
+				current[param] = true
+				produced = append(produced, param)
+			}
+		}
+		// Flipflop input/output
+		current[CtxIn] = contains(out, CtxOut)
+	}
+	return produced
+}
@@ -83,6 +83,19 @@ func (c *BaseCommand) GetOutputParam() string {
 	return c.OutputParamName
 }
 
+// GetRequiredParams the names of the parameters the command reads from the context,
+// the default is the input parameter matching the default IsExecutable. Commands reading
+// additional parameters override this so a chain can validate its wiring.
+func (c *BaseCommand) GetRequiredParams() []string {
+	return []string{c.GetInputParam()}
+}
+
+// GetProducedParams the names of the parameters the command writes to the context,
+// the default is the output parameter.
+func (c *BaseCommand) GetProducedParams() []string {
+	return []string{c.GetOutputParam()}
+}
+
 func (c *BaseCommand) GetTracer() trace.Tracer {
 	return c.Tracer
 }
@@ -18,10 +18,15 @@ package cor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
+	"slices"
 )
 
+// BaseContext is the default implementation of Context, it is not safe for
+// concurrent writes, see ConcurrentContext.
 type BaseContext struct {
 	data      map[string]interface{}
 	errors    map[string]error
@@ -60,6 +65,16 @@ func (c *BaseContext) Add(key string, value interface{}) Context {
 	return c
 }
 
+// GetOrAdd returns the existing value for the key if present, otherwise it adds
+// and returns the given value. The loaded result is true if the value was loaded.
+func (c *BaseContext) GetOrAdd(key string, value interface{}) (actual interface{}, loaded bool) {
+	if existing, ok := c.data[key]; ok && existing != nil {
+		return existing, true
+	}
+	c.data[key] = value
+	return value, false
+}
+
 func (c *BaseContext) AddTempFile(file string) {
 	c.tempFiles = append(c.tempFiles, file)
 }
@@ -72,6 +87,11 @@ func (c *BaseContext) AddError(key string, err error) {
 	c.errors[key] = err
 }
 
+// AppendError joins the error with any error already recorded for the key.
+func (c *BaseContext) AppendError(key string, err error) {
+	c.errors[key] = appendError(c.errors[key], err)
+}
+
 func (c *BaseContext) GetErrors() map[string]error {
 	return c.errors
 }
@@ -87,3 +107,26 @@ func (c *BaseContext) Remove(key string) {
 func (c *BaseContext) HasErrors() bool {
 	return len(c.errors) > 0
 }
+
+// JoinErrors joins the errors of a context, as returned by GetErrors, in command name
+// order, each prefixed with its command name. It is nil if there are none.
+func JoinErrors(errs map[string]error) error {
+	names := make([]string, 0, len(errs))
+	for name := range errs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	out := make([]error, 0, len(names))
+	for _, name := range names {
+		out = append(out, fmt.Errorf("%s: %w", name, errs[name]))
+	}
+	return errors.Join(out...)
+}
+
+// appendError joins err to existing, keeping the original error when it is the first.
+func appendError(existing error, err error) error {
+	if existing == nil {
+		return err
+	}
+	return errors.Join(existing, err)
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CheckpointStore persists the outputs of completed commands so an interrupted
+// chain can resume from the last completed command. Checkpoints are grouped by key,
+// typically the identity of the work item, and named by the command.
+type CheckpointStore interface {
+	// Load returns the checkpoint data and true if the checkpoint exists.
+	Load(ctx context.Context, key string, name string) (data []byte, ok bool, err error)
+	// Save stores the checkpoint data, replacing any existing checkpoint.
+	Save(ctx context.Context, key string, name string, data []byte) error
+	// Clear removes all checkpoints for the key.
+	Clear(ctx context.Context, key string) error
+}
+
+// CheckpointKeyFunc returns the checkpoint key for the work item in the context.
+type CheckpointKeyFunc func(context Context) (string, error)
+
+// CheckpointCommand wraps a command, saving its output parameter as JSON when it
+// completes without errors. When a checkpoint exists the command is not executed,
+// instead the output parameter and CtxOut are restored from the checkpoint.
+// Checkpoint store failures are logged and never fail the command.
+type CheckpointCommand[T any] struct {
+	Command
+	store CheckpointStore
+	key   CheckpointKeyFunc
+}
+
+// NewCheckpointCommand wraps the command, T is the type of its output parameter.
+func NewCheckpointCommand[T any](command Command, store CheckpointStore, key CheckpointKeyFunc) *CheckpointCommand[T] {
+	return &CheckpointCommand[T]{Command: command, store: store, key: key}
+}
+
+func (c *CheckpointCommand[T]) Execute(chCtx Context) {
+	ctx := chCtx.GetContext()
+	key, err := c.key(chCtx)
+	if err != nil {
+		chCtx.AddError(c.GetName(), fmt.Errorf("failed to create checkpoint key: %w", err))
+		return
+	}
+
+	if value, ok := c.restore(ctx, key); ok {
+		trace.SpanFromContext(ctx).AddEvent("checkpoint-restored", trace.WithAttributes(
+			attribute.String("command", c.GetName()),
+			attribute.String("key", key),
+		))
+		chCtx.Add(c.GetOutputParam(), value)
+		chCtx.Add(CtxOut, value)
+		return
+	}
+
+	// Execute against a view so partial output of a failed execution is not checkpointed
+	view := newContextView(chCtx)
+	c.Command.Execute(view)
+	if view.err() == nil {
+		c.save(ctx, key, view)
+	}
+	view.mergeInto(chCtx)
+	if out, ok := view.output(); ok {
+		chCtx.Add(CtxOut, out)
+	}
+}
+
+func (c *CheckpointCommand[T]) restore(ctx context.Context, key string) (value T, ok bool) {
+	data, ok, err := c.store.Load(ctx, key, c.GetName())
+	if err != nil {
+		log.Printf("failed to load checkpoint %s/%s: %v", key, c.GetName(), err)
+		return value, false
+	}
+	if !ok {
+		return value, false
+	}
+	if err = json.Unmarshal(data, &value); err != nil {
+		log.Printf("failed to decode checkpoint %s/%s: %v", key, c.GetName(), err)
+		return value, false
+	}
+	return value, true
+}
+
+func (c *CheckpointCommand[T]) save(ctx context.Context, key string, view *contextView) {
+	value, err := NewKey[T](c.GetOutputParam()).Get(view)
+	if err != nil {
+		log.Printf("failed to read checkpoint value %s/%s: %v", key, c.GetName(), err)
+		return
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("failed to encode checkpoint %s/%s: %v", key, c.GetName(), err)
+		return
+	}
+	if err = c.store.Save(ctx, key, c.GetName(), data); err != nil {
+		log.Printf("failed to save checkpoint %s/%s: %v", key, c.GetName(), err)
+	}
+}
+
+// validate delegates to the wrapped command so wrapped chains are validated in full.
+func (c *CheckpointCommand[T]) validate(available map[string]bool, v *validation) []string {
+	return v.command(c.Command, available)
+}
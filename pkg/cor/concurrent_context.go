@@ -0,0 +1,141 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+)
+
+// ConcurrentContext is a Context that is safe for use by multiple goroutines.
+// All reads and writes are guarded by a read/write mutex, and GetOrAdd and AppendError
+// allow commands running at the same time to share values and errors atomically.
+type ConcurrentContext struct {
+	mu        sync.RWMutex
+	data      map[string]interface{}
+	errors    map[string]error
+	tempFiles []string
+	context   context.Context
+}
+
+func NewConcurrentContext() Context {
+	return &ConcurrentContext{
+		data:      make(map[string]interface{}),
+		errors:    make(map[string]error),
+		tempFiles: make([]string, 0),
+	}
+}
+
+func (c *ConcurrentContext) SetContext(context context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.context = context
+}
+
+func (c *ConcurrentContext) GetContext() context.Context {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.context
+}
+
+func (c *ConcurrentContext) Close() {
+	// Clean up any temp files created along the way
+	for _, file := range c.GetTempFiles() {
+		err := os.Remove(file)
+		if err != nil {
+			log.Printf("failed to remove file %v\n", err)
+		}
+	}
+}
+
+func (c *ConcurrentContext) Add(key string, value interface{}) Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	return c
+}
+
+// GetOrAdd returns the existing value for the key if present, otherwise it adds
+// and returns the given value. The loaded result is true if the value was loaded.
+func (c *ConcurrentContext) GetOrAdd(key string, value interface{}) (actual interface{}, loaded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, ok := c.data[key]; ok && existing != nil {
+		return existing, true
+	}
+	c.data[key] = value
+	return value, false
+}
+
+func (c *ConcurrentContext) AddTempFile(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tempFiles = append(c.tempFiles, file)
+}
+
+// GetTempFiles returns a copy of the temp files.
+func (c *ConcurrentContext) GetTempFiles() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]string, len(c.tempFiles))
+	copy(out, c.tempFiles)
+	return out
+}
+
+func (c *ConcurrentContext) AddError(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[key] = err
+}
+
+// AppendError joins the error with any error already recorded for the key.
+func (c *ConcurrentContext) AppendError(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[key] = appendError(c.errors[key], err)
+}
+
+// GetErrors returns a copy of the errors.
+func (c *ConcurrentContext) GetErrors() map[string]error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]error, len(c.errors))
+	for key, err := range c.errors {
+		out[key] = err
+	}
+	return out
+}
+
+func (c *ConcurrentContext) Get(key string) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.data[key]
+}
+
+func (c *ConcurrentContext) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+}
+
+func (c *ConcurrentContext) HasErrors() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.errors) > 0
+}
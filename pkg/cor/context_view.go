@@ -0,0 +1,195 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// contextView is a copy-on-write view of a parent Context. Reads fall through
+// to the parent, while writes, errors and temp files are kept locally
+// until they are merged back into the parent. This allows several commands
+// to share the same parent without writing to it at the same time.
+// The view is safe for concurrent use so a command may fan out its own work.
+type contextView struct {
+	mu        sync.RWMutex
+	parent    Context
+	data      map[string]interface{}
+	removed   map[string]bool
+	errors    map[string]error
+	tempFiles []string
+	context   context.Context
+}
+
+func newContextView(parent Context) *contextView {
+	return &contextView{
+		parent:    parent,
+		data:      make(map[string]interface{}),
+		removed:   make(map[string]bool),
+		errors:    make(map[string]error),
+		tempFiles: make([]string, 0),
+		context:   parent.GetContext(),
+	}
+}
+
+func (c *contextView) SetContext(context context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.context = context
+}
+
+func (c *contextView) GetContext() context.Context {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.context
+}
+
+// Close is a no-op, temp files are owned by the parent once merged.
+func (c *contextView) Close() {
+}
+
+func (c *contextView) Add(key string, value interface{}) Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = value
+	delete(c.removed, key)
+	return c
+}
+
+// GetOrAdd is atomic within the view, values added by commands running
+// in other views are not visible until the views are merged.
+func (c *contextView) GetOrAdd(key string, value interface{}) (actual interface{}, loaded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing := c.get(key); existing != nil {
+		return existing, true
+	}
+	c.data[key] = value
+	delete(c.removed, key)
+	return value, false
+}
+
+func (c *contextView) AddTempFile(file string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tempFiles = append(c.tempFiles, file)
+}
+
+func (c *contextView) GetTempFiles() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]string, len(c.tempFiles))
+	copy(out, c.tempFiles)
+	return out
+}
+
+func (c *contextView) AddError(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[key] = err
+}
+
+func (c *contextView) AppendError(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.errors[key] = appendError(c.errors[key], err)
+}
+
+// GetErrors returns only the errors raised against this view.
+func (c *contextView) GetErrors() map[string]error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make(map[string]error, len(c.errors))
+	for key, err := range c.errors {
+		out[key] = err
+	}
+	return out
+}
+
+func (c *contextView) Get(key string) interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.get(key)
+}
+
+func (c *contextView) get(key string) interface{} {
+	if c.removed[key] {
+		return nil
+	}
+	if value, ok := c.data[key]; ok {
+		return value
+	}
+	return c.parent.Get(key)
+}
+
+func (c *contextView) Remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.data, key)
+	c.removed[key] = true
+}
+
+// HasErrors reports whether errors were raised against this view.
+func (c *contextView) HasErrors() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.errors) > 0
+}
+
+// err joins the errors raised against this view, nil if there are none.
+func (c *contextView) err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	errs := make([]error, 0, len(c.errors))
+	for _, err := range c.errors {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// output returns the CtxOut written to this view, if any.
+func (c *contextView) output() (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	value, ok := c.data[CtxOut]
+	return value, ok
+}
+
+// mergeInto writes the local values, errors and temp files into the target context.
+// The pipe parameters (CtxIn, CtxOut) are not merged, the caller decides how they are carried.
+func (c *contextView) mergeInto(target Context) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for key := range c.removed {
+		if key != CtxIn && key != CtxOut {
+			target.Remove(key)
+		}
+	}
+	for key, value := range c.data {
+		if key != CtxIn && key != CtxOut {
+			target.Add(key, value)
+		}
+	}
+	for key, err := range c.errors {
+		target.AppendError(key, err)
+	}
+	for _, file := range c.tempFiles {
+		target.AddTempFile(file)
+	}
+}
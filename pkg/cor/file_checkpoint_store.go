@@ -0,0 +1,79 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// FileCheckpointStore is a CheckpointStore on the local filesystem,
+// each key is a directory and each checkpoint a JSON file within it.
+type FileCheckpointStore struct {
+	root string
+}
+
+func NewFileCheckpointStore(root string) *FileCheckpointStore {
+	return &FileCheckpointStore{root: root}
+}
+
+func (s *FileCheckpointStore) keyPath(key string) string {
+	return filepath.Join(s.root, url.PathEscape(key))
+}
+
+func (s *FileCheckpointStore) checkpointPath(key string, name string) string {
+	return filepath.Join(s.keyPath(key), url.PathEscape(name)+".json")
+}
+
+func (s *FileCheckpointStore) Load(_ context.Context, key string, name string) ([]byte, bool, error) {
+	data, err := os.ReadFile(s.checkpointPath(key, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Save writes to a temp file and renames it, so a partially written checkpoint is never loaded.
+func (s *FileCheckpointStore) Save(_ context.Context, key string, name string, data []byte) error {
+	dir := s.keyPath(key)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(dir, "checkpoint-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), s.checkpointPath(key, name))
+}
+
+func (s *FileCheckpointStore) Clear(_ context.Context, key string) error {
+	return os.RemoveAll(s.keyPath(key))
+}
@@ -30,12 +30,15 @@ const (
 
 // Context is an opinionated runtime context for Go Lang.
 // It's a bit more complex than other language versions due to the nature
-// of Filesystem behaviors.
+// of Filesystem behaviors. Use NewConcurrentContext when the context is
+// shared by commands or goroutines running at the same time.
 type Context interface {
 	SetContext(context context.Context)
 	GetContext() context.Context
 	Add(key string, value interface{}) Context
+	GetOrAdd(key string, value interface{}) (actual interface{}, loaded bool)
 	AddError(key string, err error)
+	AppendError(key string, err error)
 	GetErrors() map[string]error
 	Get(key string) interface{}
 	Remove(key string)
@@ -56,6 +59,8 @@ type Command interface {
 	GetName() string
 	GetInputParam() string
 	GetOutputParam() string
+	GetRequiredParams() []string
+	GetProducedParams() []string
 	IsExecutable(context Context) bool
 	GetTracer() trace.Tracer
 	GetMeter() metric.Meter
@@ -70,4 +75,5 @@ type Chain interface {
 	Command
 	ContinueOnFailure(bool) Chain
 	AddCommand(command Command) Chain
+	Validate() *ValidationReport
 }
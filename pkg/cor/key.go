@@ -0,0 +1,91 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMissingValue is returned when a key has no value in the context.
+	ErrMissingValue = errors.New("missing context value")
+	// ErrWrongType is returned when a key's value is not of the expected type.
+	ErrWrongType = errors.New("wrong context value type")
+)
+
+// Key is a typed name for a value in a Context. Reading a value through a key
+// checks the type of the value, so a misspelled parameter or a command wired
+// in the wrong order is reported as an error rather than a panic.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a typed key for the given parameter name.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: name}
+}
+
+// Name returns the parameter name of the key.
+func (k Key[T]) Name() string {
+	return k.name
+}
+
+// Lookup returns the value of the key and true if it is present with the expected type.
+func (k Key[T]) Lookup(context Context) (value T, ok bool) {
+	if context == nil {
+		return value, false
+	}
+	value, ok = context.Get(k.name).(T)
+	return value, ok
+}
+
+// Get returns the value of the key, or an error wrapping ErrMissingValue
+// or ErrWrongType if the value is absent or has the wrong type.
+func (k Key[T]) Get(context Context) (value T, err error) {
+	if context == nil {
+		return value, fmt.Errorf("%w: %s", ErrMissingValue, k.name)
+	}
+	raw := context.Get(k.name)
+	if raw == nil {
+		return value, fmt.Errorf("%w: %s", ErrMissingValue, k.name)
+	}
+	value, ok := raw.(T)
+	if !ok {
+		return value, fmt.Errorf("%w: %s is %T, expected %T", ErrWrongType, k.name, raw, value)
+	}
+	return value, nil
+}
+
+// MustGet returns the value of the key for a command that cannot continue without it.
+// If the value is absent or has the wrong type the error is added to the context
+// under the command name and false is returned.
+func (k Key[T]) MustGet(context Context, commandName string) (value T, ok bool) {
+	value, err := k.Get(context)
+	if err != nil {
+		if context != nil {
+			context.AddError(commandName, err)
+		}
+		return value, false
+	}
+	return value, true
+}
+
+// Set adds the value to the context under the key.
+func (k Key[T]) Set(context Context, value T) Context {
+	return context.Add(k.name, value)
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ParallelChain is a Chain that executes all of its commands at the same time,
+// fanning the chain input out to each of them. Every command runs against its own
+// view of the chain context, once all commands complete their outputs, errors and
+// temp files are merged back into the chain context in the order the commands were added.
+// The CtxOut of the last command that produced one becomes the CtxOut of the chain,
+// this preserves the PIPE behavior when a ParallelChain is nested in a BaseChain.
+type ParallelChain struct {
+	BaseChain
+}
+
+func NewParallelChain(name string) *ParallelChain {
+	return &ParallelChain{BaseChain: *NewBaseChain(name)}
+}
+
+func (c *ParallelChain) ContinueOnFailure(continueOnFailure bool) Chain {
+	c.continueOnFailure = continueOnFailure
+	return c
+}
+
+func (c *ParallelChain) AddCommand(command Command) Chain {
+	c.commands = append(c.commands, command)
+	return c
+}
+
+func (c *ParallelChain) Execute(chCtx Context) {
+	outerCtx, chainSpan := c.Tracer.Start(chCtx.GetContext(), fmt.Sprintf("%s_execute", c.GetName()))
+	defer chainSpan.End()
+
+	if chCtx.HasErrors() && !c.continueOnFailure {
+		chainSpan.SetStatus(codes.Error, "previous error on chain")
+		return
+	}
+
+	// The chain context is only read while the commands are running,
+	// all writes are made to the views and merged after the wait group completes.
+	views := make([]*contextView, len(c.commands))
+	var wg sync.WaitGroup
+	for i, command := range c.commands {
+		commandContext, commandSpan := c.Tracer.Start(outerCtx, command.GetName())
+		view := newContextView(chCtx)
+		view.SetContext(commandContext)
+
+		if !command.IsExecutable(view) {
+			commandSpan.SetStatus(codes.Error, fmt.Sprintf("command not executable: %s", command.GetName()))
+			commandSpan.End()
+			continue
+		}
+
+		views[i] = view
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer commandSpan.End()
+			command.Execute(view)
+			if view.HasErrors() {
+				commandSpan.SetStatus(codes.Error, "error after execute")
+			} else {
+				commandSpan.SetStatus(codes.Ok, command.GetName())
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Merge the views back in command order so the result is deterministic
+	var out interface{}
+	for _, view := range views {
+		if view == nil {
+			continue
+		}
+		view.mergeInto(chCtx)
+		if value, ok := view.output(); ok {
+			out = value
+		}
+	}
+	if out != nil {
+		chCtx.Add(CtxOut, out)
+	}
+
+	if !chCtx.HasErrors() {
+		chainSpan.SetStatus(codes.Ok, c.GetName())
+	} else {
+		chainSpan.SetStatus(codes.Error, "chain failed to execute")
+	}
+}
+
+// Validate checks the wiring of the chain, the chain input (CtxIn) is expected
+// to be provided by the caller. See ValidationReport.
+func (c *ParallelChain) Validate() *ValidationReport {
+	v := newValidation(c.GetName())
+	c.validate(map[string]bool{CtxIn: true}, v)
+	return v.complete()
+}
+
+// validate checks every command against the same parameters, since the commands
+// run at the same time they can not read the outputs of one another.
+func (c *ParallelChain) validate(available map[string]bool, v *validation) []string {
+	produced := make([]string, 0)
+	for _, command := range c.commands {
+		for _, param := range v.command(command, available) {
+			if param != CtxIn && !contains(produced, param) {
+				produced = append(produced, param)
+			}
+		}
+	}
+	return produced
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrPermanent marks an error that must not be retried, see Permanent.
+var ErrPermanent = errors.New("permanent error")
+
+// Permanent wraps an error so the default retry classifier does not retry it.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
+// DefaultRetryable is the default retryable-error classifier. Context wiring errors,
+// permanent errors and cancellation of the parent context are not retried.
+func DefaultRetryable(err error) bool {
+	return !errors.Is(err, ErrPermanent) &&
+		!errors.Is(err, ErrMissingValue) &&
+		!errors.Is(err, ErrWrongType) &&
+		!errors.Is(err, context.Canceled)
+}
+
+// RetryPolicy describes how an operation is retried, with exponential backoff and jitter
+// between attempts and an optional timeout for each attempt.
+type RetryPolicy struct {
+	MaxAttempts    int                  // The maximum number of attempts, including the first.
+	InitialBackoff time.Duration        // The wait before the first retry.
+	MaxBackoff     time.Duration        // The upper bound of the wait between retries, 0 for none.
+	Multiplier     float64              // The growth factor of the wait, values below 1 are treated as 1.
+	Jitter         float64              // The random fraction (0 to 1) added to or removed from each wait.
+	AttemptTimeout time.Duration        // The timeout of a single attempt, 0 for none.
+	Retryable      func(err error) bool // The error classifier, DefaultRetryable when nil.
+}
+
+// NewRetryPolicy creates a policy with the given attempts and initial backoff,
+// doubling the backoff on each retry with 20% jitter.
+func NewRetryPolicy(maxAttempts int, initialBackoff time.Duration) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    maxAttempts,
+		InitialBackoff: initialBackoff,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the wait after the given attempt, attempts start at 1.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	multiplier := math.Max(p.Multiplier, 1)
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 {
+		backoff = math.Min(backoff, float64(p.MaxBackoff))
+	}
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		backoff = backoff * (1 - jitter + 2*jitter*rand.Float64())
+	}
+	return time.Duration(backoff)
+}
+
+// IsRetryable classifies the error using the policy classifier.
+func (p *RetryPolicy) IsRetryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return DefaultRetryable(err)
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, the attempts
+// are exhausted, or the context is done. Each attempt receives its own context bounded by
+// the attempt timeout and its attempt number starting at 1. The last error is returned.
+func (p *RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context, attempt int) error) (err error) {
+	for attempt := 1; ; attempt++ {
+		err = p.attempt(ctx, attempt, fn)
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !p.IsRetryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(p.Backoff(attempt)):
+		}
+	}
+}
+
+func (p *RetryPolicy) attempt(ctx context.Context, attempt int, fn func(ctx context.Context, attempt int) error) error {
+	if p.AttemptTimeout <= 0 {
+		return fn(ctx, attempt)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, p.AttemptTimeout)
+	defer cancel()
+	return fn(attemptCtx, attempt)
+}
+
+// RetryCommand wraps a command with a retry policy. Each attempt executes against its
+// own view of the context, so the values and errors of a failed attempt are discarded
+// and only the final attempt is merged into the chain context.
+type RetryCommand struct {
+	Command
+	policy       *RetryPolicy
+	retryCounter metric.Int64Counter
+}
+
+// NewRetryCommand wraps the command with the policy.
+func NewRetryCommand(command Command, policy *RetryPolicy) *RetryCommand {
+	out := &RetryCommand{Command: command, policy: policy}
+	out.retryCounter, _ = command.GetMeter().Int64Counter(fmt.Sprintf("%s.counter.retry", command.GetName()))
+	return out
+}
+
+func (c *RetryCommand) Execute(chCtx Context) {
+	parentCtx := chCtx.GetContext()
+	var view *contextView
+	_ = c.policy.Do(parentCtx, func(ctx context.Context, attempt int) error {
+		if attempt > 1 {
+			c.retryCounter.Add(parentCtx, 1)
+			trace.SpanFromContext(parentCtx).AddEvent("retry", trace.WithAttributes(
+				attribute.String("command", c.GetName()),
+				attribute.Int("attempt", attempt),
+			))
+		}
+		view = newContextView(chCtx)
+		view.SetContext(ctx)
+		c.Command.Execute(view)
+		return view.err()
+	})
+
+	view.mergeInto(chCtx)
+	if out, ok := view.output(); ok {
+		chCtx.Add(CtxOut, out)
+	}
+}
+
+// validate delegates to the wrapped command so wrapped chains are validated in full.
+func (c *RetryCommand) validate(available map[string]bool, v *validation) []string {
+	return v.command(c.Command, available)
+}
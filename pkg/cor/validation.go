@@ -0,0 +1,129 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidChain is wrapped by the error of a ValidationReport with missing producers or collisions.
+var ErrInvalidChain = errors.New("invalid chain")
+
+// ValidationReport is the result of validating the wiring of a chain.
+// Missing producers and collisions will fail at runtime and are reported by Err,
+// dead outputs are informational since a chain may produce values for its caller.
+type ValidationReport struct {
+	Chain            string
+	MissingProducers []string // A command requires a parameter no earlier command produces.
+	DeadOutputs      []string // A command produces a parameter no later command requires.
+	Collisions       []string // More than one command produces the same parameter.
+}
+
+// Err returns an error describing the missing producers and collisions, or nil.
+func (r *ValidationReport) Err() error {
+	if len(r.MissingProducers) == 0 && len(r.Collisions) == 0 {
+		return nil
+	}
+	problems := append(append([]string{}, r.MissingProducers...), r.Collisions...)
+	return fmt.Errorf("%w %s: %s", ErrInvalidChain, r.Chain, strings.Join(problems, "; "))
+}
+
+// validator is implemented by the chains, a chain validates its commands against
+// the parameters available when it starts and returns the parameters it adds.
+type validator interface {
+	validate(available map[string]bool, v *validation) []string
+}
+
+// validation accumulates the producers and consumers while walking the chain.
+type validation struct {
+	report    *ValidationReport
+	producers map[string]string
+	consumed  map[string]bool
+}
+
+func newValidation(chainName string) *validation {
+	return &validation{
+		report:    &ValidationReport{Chain: chainName},
+		producers: make(map[string]string),
+		consumed:  make(map[string]bool),
+	}
+}
+
+func isPipeParam(param string) bool {
+	return param == CtxIn || param == CtxOut
+}
+
+// command validates a single command and returns the parameters it produces.
+func (v *validation) command(command Command, available map[string]bool) []string {
+	if chain, ok := command.(validator); ok {
+		return chain.validate(available, v)
+	}
+	for _, param := range unique(command.GetRequiredParams()) {
+		if !available[param] {
+			v.report.MissingProducers = append(v.report.MissingProducers, fmt.Sprintf("%s requires %s", command.GetName(), param))
+			continue
+		}
+		v.consumed[param] = true
+	}
+	produced := unique(command.GetProducedParams())
+	for _, param := range produced {
+		if isPipeParam(param) {
+			continue
+		}
+		if producer, ok := v.producers[param]; ok {
+			v.report.Collisions = append(v.report.Collisions, fmt.Sprintf("%s is produced by %s and %s", param, producer, command.GetName()))
+			continue
+		}
+		v.producers[param] = command.GetName()
+	}
+	return produced
+}
+
+// complete records the dead outputs and returns the report.
+func (v *validation) complete() *ValidationReport {
+	for param, producer := range v.producers {
+		if !v.consumed[param] {
+			v.report.DeadOutputs = append(v.report.DeadOutputs, fmt.Sprintf("%s produced by %s is never used", param, producer))
+		}
+	}
+	sort.Strings(v.report.DeadOutputs)
+	return v.report
+}
+
+func unique(params []string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0, len(params))
+	for _, param := range params {
+		if !seen[param] {
+			seen[param] = true
+			out = append(out, param)
+		}
+	}
+	return out
+}
+
+func contains(params []string, param string) bool {
+	for _, p := range params {
+		if p == param {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ErrJobNotFound is returned when a job does not exist in the store.
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrInvalidTransition is returned when a job is moved to a state not reachable from its current state.
+var ErrInvalidTransition = errors.New("invalid job state transition")
+
+// State is the life-cycle state of a job.
+type State string
+
+const (
+	StateQueued       State = "queued"        // Waiting for a worker, either new or waiting to be retried.
+	StateRunning      State = "running"       // Being executed by a worker.
+	StateSucceeded    State = "succeeded"     // Executed without errors.
+	StateFailed       State = "failed"        // Out of attempts, or not retryable, and not dead-lettered.
+	StateDeadLettered State = "dead-lettered" // Failed and published to the dead-letter topic.
+)
+
+// transitions are the states reachable from each state,
+// a running job returns to queued when it is retried or recovered after a restart.
+var transitions = map[State][]State{
+	StateQueued:  {StateRunning},
+	StateRunning: {StateQueued, StateSucceeded, StateFailed},
+	StateFailed:  {StateDeadLettered},
+}
+
+// IsTerminal returns true if no further transitions are expected from the state.
+func (s State) IsTerminal() bool {
+	return s == StateSucceeded || s == StateFailed || s == StateDeadLettered
+}
+
+// Job is a received message and the state of its processing.
+type Job struct {
+	Id              string            `json:"id"`
+	Queue           string            `json:"queue"`
+	Payload         string            `json:"payload"`
+	Attributes      map[string]string `json:"attributes,omitempty"`
+	State           State             `json:"state"`
+	Attempts        int               `json:"attempts"`
+	LastError       string            `json:"last_error,omitempty"`
+	CreateTime      time.Time         `json:"create_time"`
+	UpdateTime      time.Time         `json:"update_time"`
+	NextAttemptTime time.Time         `json:"next_attempt_time,omitempty"`
+}
+
+// NewJob creates a queued job.
+func NewJob(queue string, id string, payload string, attributes map[string]string) *Job {
+	now := time.Now()
+	return &Job{
+		Id:         id,
+		Queue:      queue,
+		Payload:    payload,
+		Attributes: attributes,
+		State:      StateQueued,
+		CreateTime: now,
+		UpdateTime: now,
+	}
+}
+
+// Transition moves the job to the given state.
+func (j *Job) Transition(to State) error {
+	if !slices.Contains(transitions[j.State], to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, j.State, to)
+	}
+	j.State = to
+	j.UpdateTime = time.Now()
+	return nil
+}
+
+// clone returns a copy of the job, so stored jobs are not shared between goroutines.
+func (j *Job) clone() *Job {
+	out := *j
+	out.Attributes = maps.Clone(j.Attributes)
+	return &out
+}
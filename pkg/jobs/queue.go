@@ -0,0 +1,329 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultRetryPolicy is used by queues created without a retry policy.
+var DefaultRetryPolicy = cor.NewRetryPolicy(3, 30*time.Second)
+
+// DeadLetterFunc publishes a job that has failed all of its attempts.
+type DeadLetterFunc func(ctx context.Context, job *Job) error
+
+// Queue executes a command for each enqueued job on a bounded pool of workers.
+// Jobs are recorded in the store before Enqueue returns, so the message they were
+// created from may be acknowledged immediately, and are retried with the retry
+// policy. Jobs out of attempts are passed to the dead-letter function, if set.
+type Queue struct {
+	name       string
+	command    cor.Command
+	store      Store
+	policy     *cor.RetryPolicy
+	workers    int
+	deadLetter DeadLetterFunc
+	claimLock  sync.Mutex
+
+	// The ids of the jobs ready to run, unbounded so dispatching never blocks,
+	// wake signals idle workers that the backlog isn't empty.
+	backlogLock sync.Mutex
+	backlog     []string
+	wake        chan struct{}
+	tracer      trace.Tracer
+
+	successCounter    metric.Int64Counter
+	errorCounter      metric.Int64Counter
+	deadLetterCounter metric.Int64Counter
+}
+
+// NewQueue creates a queue executing the command with the given number of workers,
+// the queue name is the name of the command.
+func NewQueue(command cor.Command, store Store, policy *cor.RetryPolicy, workers int) *Queue {
+	if policy == nil {
+		policy = DefaultRetryPolicy
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	name := command.GetName()
+	meter := otel.Meter(name)
+	successCounter, _ := meter.Int64Counter(fmt.Sprintf("%s.counter.job.success", name))
+	errorCounter, _ := meter.Int64Counter(fmt.Sprintf("%s.counter.job.error", name))
+	deadLetterCounter, _ := meter.Int64Counter(fmt.Sprintf("%s.counter.job.dead_letter", name))
+	return &Queue{
+		name:              name,
+		command:           command,
+		store:             store,
+		policy:            policy,
+		workers:           workers,
+		wake:              make(chan struct{}, 1),
+		tracer:            otel.Tracer(name),
+		successCounter:    successCounter,
+		errorCounter:      errorCounter,
+		deadLetterCounter: deadLetterCounter,
+	}
+}
+
+// SetDeadLetter sets the function receiving jobs that have failed all of their attempts.
+func (q *Queue) SetDeadLetter(deadLetter DeadLetterFunc) {
+	q.deadLetter = deadLetter
+}
+
+// Name returns the name of the queue.
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Start starts the workers and resumes the jobs left queued or running by a previous process.
+func (q *Queue) Start(ctx context.Context) error {
+	pending, err := q.store.List(ctx, Filter{Queue: q.name})
+	if err != nil {
+		return err
+	}
+	for range q.workers {
+		go q.work(ctx)
+	}
+
+	// Dispatch the oldest jobs first
+	slices.Reverse(pending)
+	resumed := 0
+	for _, job := range pending {
+		if job.State == StateRunning {
+			if err = job.Transition(StateQueued); err != nil {
+				return err
+			}
+			if err = q.store.Update(ctx, job); err != nil {
+				return err
+			}
+		}
+		if job.State == StateQueued {
+			q.schedule(ctx, job.Id, time.Until(job.NextAttemptTime))
+			resumed++
+		}
+	}
+	log.Printf("job queue %s started with %d workers, %d jobs resumed", q.name, q.workers, resumed)
+	return nil
+}
+
+// Enqueue records a job for the message and dispatches it to the workers, the job id
+// is the queue name and the message id. A message already enqueued, e.g. a redelivered
+// message, returns the existing job. Enqueue returns once the job is recorded, without
+// waiting for a worker.
+func (q *Queue) Enqueue(ctx context.Context, messageId string, payload string, attributes map[string]string) (*Job, error) {
+	id := fmt.Sprintf("%s-%s", q.name, messageId)
+	job, created, err := q.store.Create(ctx, NewJob(q.name, id, payload, attributes))
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		q.dispatch(job.Id)
+	}
+	return job, nil
+}
+
+// Get returns the job with the given id.
+func (q *Queue) Get(ctx context.Context, id string) (*Job, error) {
+	return q.store.Get(ctx, id)
+}
+
+// List returns the jobs of the queue in the given state, all jobs for an empty state.
+func (q *Queue) List(ctx context.Context, state State, limit int) ([]*Job, error) {
+	return q.store.List(ctx, Filter{Queue: q.name, State: state, Limit: limit})
+}
+
+// schedule dispatches the job after the delay.
+func (q *Queue) schedule(ctx context.Context, id string, delay time.Duration) {
+	go func() {
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			defer timer.Stop()
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+		q.dispatch(id)
+	}()
+}
+
+// dispatch adds the job to the backlog and wakes a worker.
+func (q *Queue) dispatch(id string) {
+	q.backlogLock.Lock()
+	q.backlog = append(q.backlog, id)
+	q.backlogLock.Unlock()
+	q.signal()
+}
+
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+		// A worker is already signalled
+	}
+}
+
+// next takes the oldest job of the backlog, signalling another worker if more remain.
+func (q *Queue) next() (string, bool) {
+	q.backlogLock.Lock()
+	defer q.backlogLock.Unlock()
+	if len(q.backlog) == 0 {
+		return "", false
+	}
+	id := q.backlog[0]
+	q.backlog = q.backlog[1:]
+	if len(q.backlog) > 0 {
+		q.signal()
+	}
+	return id, true
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		id, ok := q.next()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return
+			case <-q.wake:
+				continue
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err := q.execute(ctx, id); err != nil {
+			log.Printf("job %s: %v", id, err)
+		}
+	}
+}
+
+// claim moves a queued job to running, a job dispatched twice is only claimed once.
+func (q *Queue) claim(ctx context.Context, id string) (*Job, error) {
+	q.claimLock.Lock()
+	defer q.claimLock.Unlock()
+	job, err := q.store.Get(ctx, id)
+	if err != nil || job.State != StateQueued {
+		return nil, err
+	}
+	if err = job.Transition(StateRunning); err != nil {
+		return nil, err
+	}
+	job.Attempts++
+	if err = q.store.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (q *Queue) execute(ctx context.Context, id string) error {
+	job, err := q.claim(ctx, id)
+	if err != nil || job == nil {
+		return err
+	}
+
+	spanCtx, span := q.tracer.Start(ctx, "execute-job", trace.WithAttributes(
+		attribute.String("job", job.Id),
+		attribute.Int("attempt", job.Attempts),
+	))
+	defer span.End()
+
+	chainCtx := cor.NewConcurrentContext()
+	chainCtx.SetContext(spanCtx)
+	chainCtx.Add(cor.CtxIn, job.Payload)
+	q.command.Execute(chainCtx)
+
+	if !chainCtx.HasErrors() {
+		span.SetStatus(codes.Ok, "success")
+		q.successCounter.Add(spanCtx, 1)
+		job.LastError = ""
+		if err = job.Transition(StateSucceeded); err != nil {
+			return err
+		}
+		return q.store.Update(ctx, job)
+	}
+
+	// A job interrupted by the shutdown of the queue is requeued, without using up its attempt,
+	// to resume on restart. The store is updated past the cancellation.
+	if ctx.Err() != nil {
+		job.Attempts--
+		job.NextAttemptTime = time.Now()
+		if err = job.Transition(StateQueued); err != nil {
+			return err
+		}
+		log.Printf("job %s interrupted by shutdown, requeued", job.Id)
+		return q.store.Update(context.WithoutCancel(ctx), job)
+	}
+
+	jobErr := cor.JoinErrors(chainCtx.GetErrors())
+	span.SetStatus(codes.Error, "failed")
+	span.RecordError(jobErr)
+	q.errorCounter.Add(spanCtx, 1)
+	job.LastError = jobErr.Error()
+
+	if job.Attempts < q.policy.MaxAttempts && q.policy.IsRetryable(jobErr) {
+		backoff := q.policy.Backoff(job.Attempts)
+		job.NextAttemptTime = time.Now().Add(backoff)
+		if err = job.Transition(StateQueued); err != nil {
+			return err
+		}
+		if err = q.store.Update(ctx, job); err != nil {
+			return err
+		}
+		log.Printf("job %s failed attempt %d, retrying in %s: %v", job.Id, job.Attempts, backoff, jobErr)
+		q.schedule(ctx, job.Id, backoff)
+		return nil
+	}
+
+	if err = job.Transition(StateFailed); err != nil {
+		return err
+	}
+	if err = q.store.Update(ctx, job); err != nil {
+		return err
+	}
+	log.Printf("job %s failed after %d attempts: %v", job.Id, job.Attempts, jobErr)
+	return q.deadLetterJob(ctx, job)
+}
+
+// deadLetterJob publishes a failed job to the dead-letter function, the job
+// remains failed if there is none or publishing fails.
+func (q *Queue) deadLetterJob(ctx context.Context, job *Job) error {
+	if q.deadLetter == nil {
+		return nil
+	}
+	if err := q.deadLetter(ctx, job); err != nil {
+		return fmt.Errorf("failed to dead-letter job: %w", err)
+	}
+	q.deadLetterCounter.Add(ctx, 1)
+	if err := job.Transition(StateDeadLettered); err != nil {
+		return err
+	}
+	return q.store.Update(ctx, job)
+}
@@ -0,0 +1,227 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pruneInterval is the minimum interval between two prunes of a store with a retention.
+const pruneInterval = time.Minute
+
+// Filter selects the jobs returned by Store.List, empty fields match all jobs.
+type Filter struct {
+	Queue string // The queue of the jobs.
+	State State  // The state of the jobs.
+	Limit int    // The maximum number of jobs, 0 for all.
+}
+
+func (f Filter) matches(job *Job) bool {
+	return (f.Queue == "" || f.Queue == job.Queue) && (f.State == "" || f.State == job.State)
+}
+
+// Store persists jobs, the jobs it returns are copies owned by the caller.
+type Store interface {
+	// Create stores a new job, returning false and the existing job if the id is already stored.
+	Create(ctx context.Context, job *Job) (existing *Job, created bool, err error)
+	// Update replaces a stored job.
+	Update(ctx context.Context, job *Job) error
+	// Get returns the job or ErrJobNotFound.
+	Get(ctx context.Context, id string) (*Job, error)
+	// List returns the jobs matching the filter, most recently created first.
+	List(ctx context.Context, filter Filter) ([]*Job, error)
+}
+
+// MemoryStore is a Store that does not survive a restart, it is safe for concurrent use.
+type MemoryStore struct {
+	lock      sync.RWMutex
+	jobs      map[string]*Job
+	save      func(job *Job) error
+	remove    func(job *Job) error
+	retention time.Duration // How long terminal jobs are kept, 0 keeps them.
+	pruneTime time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]*Job)}
+}
+
+func (s *MemoryStore) put(job *Job) error {
+	if s.save != nil {
+		if err := s.save(job); err != nil {
+			return err
+		}
+	}
+	s.jobs[job.Id] = job.clone()
+	return nil
+}
+
+func (s *MemoryStore) Create(_ context.Context, job *Job) (*Job, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.retention > 0 && time.Since(s.pruneTime) > pruneInterval {
+		if err := s.prune(time.Now().Add(-s.retention)); err != nil {
+			return nil, false, err
+		}
+	}
+	if existing, ok := s.jobs[job.Id]; ok {
+		return existing.clone(), false, nil
+	}
+	if err := s.put(job); err != nil {
+		return nil, false, err
+	}
+	return job, true, nil
+}
+
+func (s *MemoryStore) Update(_ context.Context, job *Job) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.jobs[job.Id]; !ok {
+		return ErrJobNotFound
+	}
+	return s.put(job)
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (*Job, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job.clone(), nil
+}
+
+func (s *MemoryStore) List(_ context.Context, filter Filter) ([]*Job, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	out := make([]*Job, 0)
+	for _, job := range s.jobs {
+		if filter.matches(job) {
+			out = append(out, job.clone())
+		}
+	}
+	slices.SortFunc(out, func(a, b *Job) int {
+		if c := b.CreateTime.Compare(a.CreateTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+	return out, nil
+}
+
+// Prune removes the jobs in a terminal state last updated before the given time. Their ids
+// are forgotten, so a message redelivered after its job is pruned is executed again.
+func (s *MemoryStore) Prune(_ context.Context, before time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.prune(before)
+}
+
+func (s *MemoryStore) prune(before time.Time) error {
+	s.pruneTime = time.Now()
+	for id, job := range s.jobs {
+		if !job.State.IsTerminal() || !job.UpdateTime.Before(before) {
+			continue
+		}
+		if s.remove != nil {
+			if err := s.remove(job); err != nil {
+				return err
+			}
+		}
+		delete(s.jobs, id)
+	}
+	return nil
+}
+
+// NewFileStore creates a Store that writes each job as a JSON file in the directory,
+// jobs already in the directory are loaded so queued work survives a restart. Jobs in a
+// terminal state are deleted once not updated for the retention, 0 keeps them.
+func NewFileStore(dir string, retention time.Duration) (*MemoryStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	store := NewMemoryStore()
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		job := &Job{}
+		if err = json.Unmarshal(data, job); err != nil {
+			return nil, errors.Join(errors.New("invalid job file: "+file), err)
+		}
+		store.jobs[job.Id] = job
+	}
+	store.save = func(job *Job) error {
+		return writeJob(dir, job)
+	}
+	store.remove = func(job *Job) error {
+		err := os.Remove(filepath.Join(dir, fileName(job.Id)))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	store.retention = retention
+	if retention > 0 {
+		if err = store.prune(time.Now().Add(-retention)); err != nil {
+			return nil, err
+		}
+	}
+	return store, nil
+}
+
+// writeJob writes to a temp file and renames it, so a partially written job is never loaded.
+func writeJob(dir string, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(dir, "job-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), filepath.Join(dir, fileName(job.Id)))
+}
+
+func fileName(id string) string {
+	return strings.NewReplacer("/", "_", "\\", "_").Replace(id) + ".json"
+}
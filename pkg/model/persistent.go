@@ -17,7 +17,10 @@
 package model
 
 import (
+	"slices"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/google/uuid"
 )
@@ -35,6 +38,59 @@ type Actor struct {
 	Awards       []string  `json:"awards" bigquery:"awards"`
 	Nominations  []string  `json:"nominations" bigquery:"nominations"`
 	ImageURL     string    `json:"ima_url" bigquery:"img_url"`
+
+	// The normalized name and aliases, see NormalizeActorName, matched against the normalized names of casts.
+	NormalizedAliases []string `json:"-" bigquery:"normalized_aliases"`
+}
+
+// NewActor creates the actor of the name, the id is a UUID 5 of the normalized name
+// so every spelling normalizing to the same name is the same actor.
+func NewActor(name string) *Actor {
+	generatedID := uuid.NewSHA1(uuid.NameSpaceURL, ([]byte)("actor:"+NormalizeActorName(name)))
+	actor := &Actor{
+		Id:          generatedID.String(),
+		CreateDate:  time.Now(),
+		Name:        strings.Join(strings.Fields(name), " "),
+		Aliases:     make([]string, 0),
+		Awards:      make([]string, 0),
+		Nominations: make([]string, 0),
+	}
+	actor.NormalizeAliases()
+	return actor
+}
+
+// NormalizeAliases sets the normalized aliases of the actor from its name and aliases.
+func (a *Actor) NormalizeAliases() {
+	a.NormalizedAliases = make([]string, 0, len(a.Aliases)+1)
+	for _, name := range append([]string{a.Name}, a.Aliases...) {
+		normalized := NormalizeActorName(name)
+		if !slices.Contains(a.NormalizedAliases, normalized) {
+			a.NormalizedAliases = append(a.NormalizedAliases, normalized)
+		}
+	}
+}
+
+// NormalizeActorName returns the name in lower case without annotations in parentheses
+// or brackets, punctuation and repeated spaces, e.g. "Samuel L. Jackson (voice)" is "samuel l jackson".
+func NormalizeActorName(name string) string {
+	out := strings.Builder{}
+	depth := 0
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '(' || r == '[':
+			depth++
+		case r == ')' || r == ']':
+			if depth > 0 {
+				depth--
+			}
+		case depth > 0:
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			out.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			out.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(out.String()), " ")
 }
 
 // Media capture the highest level of metadata about a media file.
@@ -108,3 +164,43 @@ func NewSceneEmbedding(
 		Embeddings:     make([]float64, 0),
 	}
 }
+
+// The types of feedback on a search result.
+const (
+	FeedbackClick      = "click"       // The result was opened.
+	FeedbackThumbsUp   = "thumbs_up"   // The result was rated relevant.
+	FeedbackThumbsDown = "thumbs_down" // The result was rated irrelevant.
+)
+
+// SearchEvent records a search, its query is normalized, the filter is JSON and the results
+// are the scenes returned in rank order, each as <media_id>/<sequence_number>.
+type SearchEvent struct {
+	Id         string    `json:"id" bigquery:"id"`
+	CreateDate time.Time `json:"create_date" bigquery:"create_date"`
+	Query      string    `json:"query" bigquery:"query"`
+	Mode       string    `json:"mode" bigquery:"mode"`
+	Filter     string    `json:"filter" bigquery:"filter"`
+	LatencyMs  int64     `json:"latency_ms" bigquery:"latency_ms"`
+	ResultIds  []string  `json:"result_ids" bigquery:"result_ids"`
+}
+
+// NewSearchEvent creates the event of a search with a random id.
+func NewSearchEvent(query string, mode string) *SearchEvent {
+	return &SearchEvent{
+		Id:         uuid.New().String(),
+		CreateDate: time.Now(),
+		Query:      query,
+		Mode:       mode,
+		ResultIds:  make([]string, 0),
+	}
+}
+
+// FeedbackEvent records the feedback of a user on a scene returned by a search.
+type FeedbackEvent struct {
+	Id             string    `json:"id" bigquery:"id"`
+	CreateDate     time.Time `json:"create_date" bigquery:"create_date"`
+	SearchId       string    `json:"search_id" bigquery:"search_id"`
+	Type           string    `json:"type" bigquery:"type"`
+	MediaId        string    `json:"media_id" bigquery:"media_id"`
+	SequenceNumber int       `json:"sequence_number" bigquery:"sequence_number"`
+}
@@ -76,3 +76,48 @@ func NewSceneExtractorSchema() *genai.Schema {
 		Required: []string{"sequence", "start", "end", "script"},
 	}
 }
+
+func NewSceneExplanationSchema() *genai.Schema {
+	// Define the schema for a list of SceneExplanation
+	return &genai.Schema{
+		Type: "array",
+		Items: &genai.Schema{
+			Type: "object",
+			Properties: map[string]*genai.Schema{
+				"media_id":        {Type: "string"},
+				"sequence_number": {Type: "integer"},
+				"span":            {Type: "string"},
+				"reason":          {Type: "string"},
+			},
+			Required: []string{"media_id", "sequence_number", "span", "reason"},
+		},
+	}
+}
+
+func NewQueryInterpretationSchema() *genai.Schema {
+	// Define the schema of a search query split into its semantic part and media filters
+	list := &genai.Schema{Type: "array", Items: &genai.Schema{Type: "string"}}
+	return &genai.Schema{
+		Type: "object",
+		Properties: map[string]*genai.Schema{
+			"query": {Type: "string"},
+			"filter": {
+				Type: "object",
+				Properties: map[string]*genai.Schema{
+					"category": list,
+					"genre":    list,
+					"director": list,
+					"cast":     list,
+					"release_year": {
+						Type: "object",
+						Properties: map[string]*genai.Schema{
+							"min": {Type: "integer"},
+							"max": {Type: "integer"},
+						},
+					},
+				},
+			},
+		},
+		Required: []string{"query", "filter"},
+	}
+}
@@ -16,6 +16,8 @@
 
 package model
 
+import "time"
+
 // These objects are used in memory via workflows, but are not persisted to the dataset
 
 // MediaFormatFilter is a simple video format object expressing the intended output
@@ -44,7 +46,66 @@ type MediaSummary struct {
 	SceneTimeStamps []*TimeSpan   `json:"scene_time_stamps,omitempty"`
 }
 
+// SceneMatchResult is a scene matched by a search. The score ranks the scene in its search mode,
+// the similarity and distance are those of the vector search, the span and reason explain the match.
 type SceneMatchResult struct {
-	MediaId        string `json:"media_id" bigquery:"media_id"`
-	SequenceNumber int    `json:"sequence_number" bigquery:"sequence_number"`
+	MediaId        string  `json:"media_id" bigquery:"media_id"`
+	SequenceNumber int     `json:"sequence_number" bigquery:"sequence_number"`
+	Score          float64 `json:"score,omitempty" bigquery:"score"`
+	Similarity     float64 `json:"similarity,omitempty" bigquery:"-"`
+	Distance       float64 `json:"distance,omitempty" bigquery:"-"`
+	Span           string  `json:"span,omitempty" bigquery:"-"`
+	Reason         string  `json:"reason,omitempty" bigquery:"-"`
+}
+
+// MediaMatchResult is a media matched by a search, its scenes are the matched scenes
+// in rank order, matches holds the match of each scene and its score aggregates the ranks of the scenes.
+type MediaMatchResult struct {
+	*Media
+	Score   float64             `json:"score"`
+	Matches []*SceneMatchResult `json:"matches"`
+}
+
+// SceneExplanation explains why a scene matches a query.
+type SceneExplanation struct {
+	MediaId        string `json:"media_id"`
+	SequenceNumber int    `json:"sequence_number"`
+	Span           string `json:"span"`
+	Reason         string `json:"reason"`
+}
+
+// QueryStats aggregates the searches of a query and the feedback on their results. The click-through
+// rate is the fraction of the searches with results having at least one result clicked.
+type QueryStats struct {
+	Query            string  `json:"query" bigquery:"query"`
+	Searches         int     `json:"searches" bigquery:"searches"`
+	ZeroResults      int     `json:"zero_results" bigquery:"zero_results"`
+	ClickedSearches  int     `json:"clicked_searches" bigquery:"clicked_searches"`
+	ClickThroughRate float64 `json:"click_through_rate" bigquery:"-"`
+	ThumbsUp         int     `json:"thumbs_up" bigquery:"thumbs_up"`
+	ThumbsDown       int     `json:"thumbs_down" bigquery:"thumbs_down"`
+	MeanLatencyMs    float64 `json:"mean_latency_ms" bigquery:"mean_latency_ms"`
+}
+
+// AnalyticsReport lists the queries searched since a time that found nothing,
+// and those whose results are seldom opened.
+type AnalyticsReport struct {
+	Since             time.Time     `json:"since"`
+	Searches          int           `json:"searches"`
+	Queries           int           `json:"queries"`
+	ZeroResultQueries []*QueryStats `json:"zero_result_queries"`
+	LowCTRQueries     []*QueryStats `json:"low_ctr_queries"`
+}
+
+// SavedSearchAlert notifies a saved search of the scenes of a newly embedded media matching
+// its query, the matches are scored with their similarity, most similar first.
+type SavedSearchAlert struct {
+	SavedSearchId      string              `json:"saved_search_id"`
+	SavedSearchVersion int                 `json:"saved_search_version"`
+	Name               string              `json:"name"`
+	Query              string              `json:"query"`
+	MediaId            string              `json:"media_id"`
+	Title              string              `json:"title"`
+	Matches            []*SceneMatchResult `json:"matches"`
+	CreateDate         time.Time           `json:"create_date"`
 }
@@ -0,0 +1,140 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/api/iterator"
+)
+
+// ErrActorNotFound is returned for an actor id that isn't in the registry.
+var ErrActorNotFound = errors.New("actor not found")
+
+// ActorService serves the actor registry and the filmography of its actors.
+type ActorService struct {
+	BigqueryClient *bigquery.Client
+	DatasetName    string
+	ActorTable     string
+	Media          *MediaService // The media of the filmographies.
+}
+
+// GetFQN returns the fully qualified BQ Table Name
+func (s *ActorService) GetFQN() string {
+	return strings.Replace(s.BigqueryClient.Dataset(s.DatasetName).Table(s.ActorTable).FullyQualifiedName(), ":", ".", -1)
+}
+
+// actorCursor is the position after the last actor of a listing page.
+type actorCursor struct {
+	Name string `json:"n"`
+	Id   string `json:"i"`
+}
+
+// ActorPage is a page of an actor listing, the next cursor is empty on the last page.
+type ActorPage struct {
+	Actors     []*model.Actor
+	NextCursor string
+}
+
+// List returns a page of the actors sorted by name, an empty cursor is the first page.
+// A non-empty name restricts the actors to those with the name in their name or aliases.
+func (s *ActorService) List(ctx context.Context, name string, cursor string, pageSize int) (*ActorPage, error) {
+	pageSize = PageSize(pageSize)
+	b := cloud.Select("*").From(s.GetFQN(), "a")
+	if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+		b.Where("STRPOS(LOWER(a.name), @name) > 0 OR EXISTS (SELECT 1 FROM UNNEST(a.aliases) alias WHERE STRPOS(LOWER(alias), @name) > 0)",
+			bigquery.QueryParameter{Name: "name", Value: name})
+	}
+	if cursor != "" {
+		var position actorCursor
+		if err := decodeCursor(cursor, &position); err != nil {
+			return nil, err
+		}
+		b.Where("a.name > @cursor_name OR (a.name = @cursor_name AND a.id > @cursor_id)",
+			bigquery.QueryParameter{Name: "cursor_name", Value: position.Name},
+			bigquery.QueryParameter{Name: "cursor_id", Value: position.Id})
+	}
+	// One more actor than the page tells whether there is a next page
+	q, err := b.OrderBy("a.name", "a.id").Limit(pageSize + 1).Query(s.BigqueryClient)
+	if err != nil {
+		return nil, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ActorPage{Actors: make([]*model.Actor, 0)}
+	for {
+		actor := &model.Actor{}
+		err = itr.Next(actor)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Actors = append(page.Actors, actor)
+	}
+	if len(page.Actors) > pageSize {
+		page.Actors = page.Actors[:pageSize]
+		last := page.Actors[pageSize-1]
+		page.NextCursor = encodeCursor(&actorCursor{Name: last.Name, Id: last.Id})
+	}
+	return page, nil
+}
+
+// Get returns an actor by id, or ErrActorNotFound if it doesn't exist
+func (s *ActorService) Get(ctx context.Context, id string) (actor *model.Actor, err error) {
+	q, err := cloud.Select("*").From(s.GetFQN(), "").
+		Where("id = @id", bigquery.QueryParameter{Name: "id", Value: id}).
+		Query(s.BigqueryClient)
+	if err != nil {
+		return actor, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return actor, err
+	}
+	// Since this should only return a single result
+	actor = &model.Actor{}
+	err = itr.Next(actor)
+	if errors.Is(err, iterator.Done) {
+		return nil, fmt.Errorf("%w: %s", ErrActorNotFound, id)
+	}
+	return actor, err
+}
+
+// ActorFilter returns the filter of the media with the actor in their cast, by name or alias.
+func ActorFilter(actor *model.Actor) *SceneFilter {
+	return &SceneFilter{Cast: append([]string{actor.Name}, actor.Aliases...)}
+}
+
+// Filmography returns a page of the media with the actor in their cast, sorted by the sort.
+func (s *ActorService) Filmography(ctx context.Context, id string, sort MediaSort, cursor string, pageSize int) (*MediaPage, error) {
+	actor, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.Media.List(ctx, ActorFilter(actor), sort, cursor, pageSize)
+}
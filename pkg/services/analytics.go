@@ -0,0 +1,167 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidFeedback is returned for feedback without a search, a scene or a known type.
+var ErrInvalidFeedback = errors.New("invalid feedback")
+
+// The defaults of an analytics report.
+const (
+	DefaultReportMinSearches = 5
+	DefaultReportMaxCTR      = 0.1
+	DefaultReportLimit       = 20
+)
+
+// ReportOptions select the queries of an analytics report. Queries with fewer than MinSearches
+// searches with results aren't reported as low CTR, each list has at most Limit queries.
+type ReportOptions struct {
+	Since       time.Time
+	MinSearches int
+	MaxCTR      float64
+	Limit       int
+}
+
+// AnalyticsService records searches and the feedback on their results, and reports on them.
+type AnalyticsService struct {
+	Sink cloud.AnalyticsSink
+}
+
+// SearchEvent returns the event of a search of the query, with the results in rank order.
+func SearchEvent(query string, mode SearchMode, filter *SceneFilter, latency time.Duration, results []*model.SceneMatchResult) *model.SearchEvent {
+	event := model.NewSearchEvent(NormalizeQuery(query), string(mode))
+	if filter != nil {
+		if out, err := json.Marshal(filter); err == nil {
+			event.Filter = string(out)
+		}
+	}
+	event.LatencyMs = latency.Milliseconds()
+	for _, result := range results {
+		event.ResultIds = append(event.ResultIds, fmt.Sprintf("%s/%d", result.MediaId, result.SequenceNumber))
+	}
+	return event
+}
+
+// RecordedCursor returns the next cursor of a page of a recorded search carrying the id of the
+// search, so its next pages are attributed to the search instead of being recorded again.
+func RecordedCursor(cursor string, searchId string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	position := &searchCursor{}
+	if err := decodeCursor(cursor, position); err != nil {
+		return "", err
+	}
+	position.SearchId = searchId
+	return encodeCursor(position), nil
+}
+
+// CursorSearchId returns the id of the recorded search carried by the cursor of a search page,
+// empty for the first page and the pages of a search that wasn't recorded.
+func CursorSearchId(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	position := &searchCursor{}
+	if err := decodeCursor(cursor, position); err != nil {
+		return "", err
+	}
+	return position.SearchId, nil
+}
+
+// RecordSearch records the search event.
+func (s *AnalyticsService) RecordSearch(ctx context.Context, event *model.SearchEvent) error {
+	return s.Sink.RecordSearch(ctx, event)
+}
+
+// RecordFeedback validates and records the feedback, setting its id and date.
+func (s *AnalyticsService) RecordFeedback(ctx context.Context, event *model.FeedbackEvent) error {
+	switch event.Type {
+	case model.FeedbackClick, model.FeedbackThumbsUp, model.FeedbackThumbsDown:
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidFeedback, event.Type)
+	}
+	if event.SearchId == "" || event.MediaId == "" || event.SequenceNumber < 0 {
+		return fmt.Errorf("%w: a search_id, media_id and sequence_number are required", ErrInvalidFeedback)
+	}
+	event.Id = uuid.New().String()
+	event.CreateDate = time.Now()
+	return s.Sink.RecordFeedback(ctx, event)
+}
+
+// Report returns the queries searched since the time of the options that found nothing, most
+// often first, and those with a click-through rate of at most MaxCTR, most searched first.
+func (s *AnalyticsService) Report(ctx context.Context, options ReportOptions) (*model.AnalyticsReport, error) {
+	stats, err := s.Sink.QueryStats(ctx, options.Since)
+	if err != nil {
+		return nil, err
+	}
+	return AnalyticsReport(stats, options), nil
+}
+
+// AnalyticsReport builds the report of the options from the counts of the queries,
+// setting their click-through rates.
+func AnalyticsReport(stats []*model.QueryStats, options ReportOptions) *model.AnalyticsReport {
+	if options.Limit <= 0 {
+		options.Limit = DefaultReportLimit
+	}
+	report := &model.AnalyticsReport{
+		Since:             options.Since,
+		Queries:           len(stats),
+		ZeroResultQueries: make([]*model.QueryStats, 0),
+		LowCTRQueries:     make([]*model.QueryStats, 0),
+	}
+	for _, q := range stats {
+		report.Searches += q.Searches
+		if answered := q.Searches - q.ZeroResults; answered > 0 {
+			q.ClickThroughRate = float64(q.ClickedSearches) / float64(answered)
+			if answered >= options.MinSearches && q.ClickThroughRate <= options.MaxCTR {
+				report.LowCTRQueries = append(report.LowCTRQueries, q)
+			}
+		}
+		if q.ZeroResults > 0 {
+			report.ZeroResultQueries = append(report.ZeroResultQueries, q)
+		}
+	}
+	sort.SliceStable(report.ZeroResultQueries, func(i, j int) bool {
+		a, b := report.ZeroResultQueries[i], report.ZeroResultQueries[j]
+		return a.ZeroResults > b.ZeroResults || (a.ZeroResults == b.ZeroResults && a.Query < b.Query)
+	})
+	sort.SliceStable(report.LowCTRQueries, func(i, j int) bool {
+		a, b := report.LowCTRQueries[i], report.LowCTRQueries[j]
+		return a.Searches > b.Searches || (a.Searches == b.Searches && a.Query < b.Query)
+	})
+	if len(report.ZeroResultQueries) > options.Limit {
+		report.ZeroResultQueries = report.ZeroResultQueries[:options.Limit]
+	}
+	if len(report.LowCTRQueries) > options.Limit {
+		report.LowCTRQueries = report.LowCTRQueries[:options.Limit]
+	}
+	return report
+}
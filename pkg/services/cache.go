@@ -0,0 +1,138 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Cache is a bounded LRU cache whose entries expire after a time to live, safe for concurrent use.
+// A nil cache caches nothing. Hits and misses are counted by the OpenTelemetry meter.
+type Cache[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[K]*list.Element
+	order   *list.List // The entries, most recently used first.
+	now     func() time.Time
+
+	hitCounter  metric.Int64Counter
+	missCounter metric.Int64Counter
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// NewCache creates a cache of at most size entries, kept for the ttl, 0 keeps them until evicted.
+// The cache is nil, caching nothing, if the size isn't positive.
+func NewCache[K comparable, V any](name string, size int, ttl time.Duration) *Cache[K, V] {
+	if size <= 0 {
+		return nil
+	}
+	meter := otel.Meter("github.com/GoogleCloudPlatform/media-search-solution")
+	out := &Cache[K, V]{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[K]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+	out.hitCounter, _ = meter.Int64Counter(fmt.Sprintf("%s.counter.cache.hit", name))
+	out.missCounter, _ = meter.Int64Counter(fmt.Sprintf("%s.counter.cache.miss", name))
+	return out
+}
+
+// SetClock replaces the clock of the cache, for tests.
+func (c *Cache[K, V]) SetClock(now func() time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Get returns the value of the key, false if it isn't cached or has expired.
+func (c *Cache[K, V]) Get(ctx context.Context, key K) (value V, ok bool) {
+	if c == nil {
+		return value, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, found := c.entries[key]
+	if found {
+		entry := element.Value.(*cacheEntry[K, V])
+		if c.ttl <= 0 || c.now().Before(entry.expires) {
+			c.order.MoveToFront(element)
+			c.hitCounter.Add(ctx, 1)
+			return entry.value, true
+		}
+		c.order.Remove(element)
+		delete(c.entries, key)
+	}
+	c.missCounter.Add(ctx, 1)
+	return value, false
+}
+
+// Put caches the value of the key, evicting the least recently used entry when the cache is full.
+func (c *Cache[K, V]) Put(key K, value V) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &cacheEntry[K, V]{key: key, value: value, expires: c.now().Add(c.ttl)}
+	if element, found := c.entries[key]; found {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry[K, V]).key)
+	}
+}
+
+// Clear removes every entry.
+func (c *Cache[K, V]) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+// Len returns the number of entries, including those expired but not yet removed.
+func (c *Cache[K, V]) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
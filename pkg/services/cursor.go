@@ -0,0 +1,64 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidCursor is returned for a cursor that is malformed or belongs to another request.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// The page sizes of listings and searches.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// PageSize returns the page size bounded to 1 to MaxPageSize, 0 is the DefaultPageSize.
+func PageSize(pageSize int) int {
+	switch {
+	case pageSize == 0:
+		return DefaultPageSize
+	case pageSize < 0:
+		return 1
+	case pageSize > MaxPageSize:
+		return MaxPageSize
+	}
+	return pageSize
+}
+
+// encodeCursor encodes the position as an opaque, URL safe cursor.
+func encodeCursor(position any) string {
+	out, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(out)
+}
+
+// decodeCursor decodes the cursor into the position.
+func decodeCursor(cursor string, position any) error {
+	in, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err = json.Unmarshal(in, position); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return nil
+}
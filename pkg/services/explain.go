@@ -0,0 +1,81 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/genai"
+)
+
+// ExplainSystemInstruction instructs the model explaining search results.
+const ExplainSystemInstruction = "You explain the results of a video scene search. " +
+	"For each scene, quote the shortest passage of its script, copied verbatim, that matches the query as the span, " +
+	"and give a one sentence reason why the scene matches the query. Never invent text that is not in the script."
+
+// ExplainService asks a generative model why search results match their query.
+type ExplainService struct {
+	Model cloud.GenerativeModel
+}
+
+// Explain sets the span and reason of the scene matches of the results with a single
+// request to the model. Spans that are not found verbatim in their scene's script are dropped.
+func (e *ExplainService) Explain(ctx context.Context, query string, results []*model.MediaMatchResult) error {
+	scripts := make(map[SceneKey]string)
+	matches := make(map[SceneKey]*model.SceneMatchResult)
+	prompt := strings.Builder{}
+	prompt.WriteString(fmt.Sprintf("Query: %s\n\nScenes:\n", query))
+	for _, result := range results {
+		for i, match := range result.Matches {
+			key := SceneKey{MediaId: match.MediaId, SequenceNumber: match.SequenceNumber}
+			scripts[key] = result.Scenes[i].Script
+			matches[key] = match
+			prompt.WriteString(fmt.Sprintf("\nmedia_id: %s\nsequence_number: %d\ntitle: %s\nscript:\n%s\n",
+				match.MediaId, match.SequenceNumber, result.Title, result.Scenes[i].Script))
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+
+	contents := []*genai.Content{genai.NewContentFromText(prompt.String(), genai.RoleUser)}
+	resp, err := e.Model.GenerateContent(ctx, ExplainSystemInstruction, contents, model.NewSceneExplanationSchema())
+	if err != nil {
+		return err
+	}
+	explanations := make([]*model.SceneExplanation, 0)
+	if err = json.Unmarshal([]byte(resp.Text()), &explanations); err != nil {
+		return fmt.Errorf("failed to parse the explanation of the search results: %w", err)
+	}
+	for _, explanation := range explanations {
+		key := SceneKey{MediaId: explanation.MediaId, SequenceNumber: explanation.SequenceNumber}
+		match, ok := matches[key]
+		if !ok {
+			continue
+		}
+		match.Reason = explanation.Reason
+		if span := strings.TrimSpace(explanation.Span); span != "" && strings.Contains(scripts[key], span) {
+			match.Span = span
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"google.golang.org/api/iterator"
+)
+
+// ErrInvalidFacet is returned for an unknown facet field.
+var ErrInvalidFacet = errors.New("invalid facet")
+
+// The facet fields of the media of search results.
+const (
+	FacetGenre         = "genre"
+	FacetRating        = "rating"
+	FacetCategory      = "category"
+	FacetReleaseDecade = "release_decade"
+	FacetDirector      = "director"
+	FacetCast          = "cast"
+)
+
+// DefaultFacets are the facet fields counted when none are configured.
+var DefaultFacets = []string{FacetGenre, FacetRating, FacetCategory, FacetReleaseDecade, FacetDirector, FacetCast}
+
+// The candidates and buckets of the facet counts.
+const (
+	DefaultFacetDepth = 100 // The top scenes whose media are counted.
+	DefaultFacetSize  = 10  // The buckets of a facet, the largest first.
+)
+
+// facetValues are the values of the facet fields, an expression of the media table aliased m
+// and the tables joined to it.
+var facetValues = map[string]struct {
+	join  string
+	value string
+}{
+	FacetGenre:         {value: "m.genre"},
+	FacetRating:        {value: "m.rating"},
+	FacetCategory:      {value: "m.category"},
+	FacetReleaseDecade: {value: "IF(m.release_year > 0, FORMAT('%ds', DIV(m.release_year, 10) * 10), NULL)"},
+	FacetDirector:      {value: "m.director"},
+	FacetCast:          {join: ", UNNEST(m.cast) c", value: "c.actor_name"},
+}
+
+// QryFacetCounts keeps the largest @facet_size buckets of each facet of the union of the facet queries.
+const QryFacetCounts = "SELECT facet, value, count FROM (%s) WHERE TRUE " +
+	"QUALIFY ROW_NUMBER() OVER (PARTITION BY facet ORDER BY count DESC, value) <= @facet_size ORDER BY facet, count DESC, value"
+
+// QryFacetCount counts the media of @facet_media_ids per value of a facet, values differing
+// only in case are one bucket.
+const QryFacetCount = "SELECT '%[1]s' AS facet, ANY_VALUE(%[3]s) AS value, COUNT(DISTINCT m.id) AS count " +
+	"FROM `%[4]s` m%[2]s WHERE m.id IN UNNEST(@facet_media_ids) AND %[3]s IS NOT NULL AND %[3]s != '' GROUP BY LOWER(%[3]s)"
+
+// FacetBucket is the number of media of the search results with a value of a facet field.
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Facets are the buckets of the facet fields, the largest first.
+type Facets map[string][]*FacetBucket
+
+// ParseFacets returns the facet fields of a comma separated list, empty is the defaults.
+func ParseFacets(in string) ([]string, error) {
+	if strings.TrimSpace(in) == "" {
+		return DefaultFacets, nil
+	}
+	out := make([]string, 0)
+	for _, field := range strings.Split(in, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if _, ok := facetValues[field]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFacet, field)
+		}
+		out = append(out, field)
+	}
+	return out, nil
+}
+
+// FacetQuery returns the query counting the facet fields over the media table.
+func FacetQuery(table string, fields []string) (string, error) {
+	queries := make([]string, 0, len(fields))
+	for _, field := range fields {
+		facet, ok := facetValues[field]
+		if !ok {
+			return "", fmt.Errorf("%w: %s", ErrInvalidFacet, field)
+		}
+		queries = append(queries, fmt.Sprintf(QryFacetCount, field, facet.join, facet.value, table))
+	}
+	return fmt.Sprintf(QryFacetCounts, strings.Join(queries, " UNION ALL ")), nil
+}
+
+// FacetCounts returns the buckets of the facet fields over the media, every field has a
+// possibly empty list of buckets.
+func (s *SearchService) FacetCounts(ctx context.Context, mediaIds []string, fields []string) (Facets, error) {
+	out := make(Facets)
+	for _, field := range fields {
+		out[field] = make([]*FacetBucket, 0)
+	}
+	if len(mediaIds) == 0 || len(fields) == 0 {
+		return out, nil
+	}
+	fqMediaTable := strings.Replace(s.BigqueryClient.Dataset(s.DatasetName).Table(s.MediaTable).FullyQualifiedName(), ":", ".", -1)
+	sql, err := FacetQuery(fqMediaTable, fields)
+	if err != nil {
+		return out, err
+	}
+	size := s.FacetSize
+	if size <= 0 {
+		size = DefaultFacetSize
+	}
+	q := s.BigqueryClient.Query(sql)
+	q.Parameters = []bigquery.QueryParameter{
+		{Name: "facet_media_ids", Value: mediaIds},
+		{Name: "facet_size", Value: size},
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		var row struct {
+			Facet string `bigquery:"facet"`
+			Value string `bigquery:"value"`
+			Count int    `bigquery:"count"`
+		}
+		err = itr.Next(&row)
+		if errors.Is(err, iterator.Done) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out[row.Facet] = append(out[row.Facet], &FacetBucket{Value: row.Value, Count: row.Count})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// IntRange is an inclusive range of an integer field, a zero bound is unbounded.
+type IntRange struct {
+	Min int `json:"min,omitempty"`
+	Max int `json:"max,omitempty"`
+}
+
+// SceneFilter restricts a scene search to the scenes of media matching the filter.
+// A field with one value is an equality, a field with several values a set membership,
+// string fields are matched case-insensitively and the fields are combined with AND.
+type SceneFilter struct {
+	Category        []string `json:"category,omitempty"`
+	Genre           []string `json:"genre,omitempty"`
+	Rating          []string `json:"rating,omitempty"`
+	Director        []string `json:"director,omitempty"`
+	Cast            []string `json:"cast,omitempty"` // The actors, a media matches if any of its cast does.
+	ReleaseYear     IntRange `json:"release_year,omitempty"`
+	LengthInSeconds IntRange `json:"length_in_seconds,omitempty"`
+}
+
+// Where returns the condition of the filter on the media table of the given alias and
+// the query parameters it binds, the condition is empty if the filter matches every media.
+func (f *SceneFilter) Where(alias string) (string, []bigquery.QueryParameter) {
+	if f == nil {
+		return "", nil
+	}
+	conditions := make([]string, 0)
+	params := make([]bigquery.QueryParameter, 0)
+
+	in := func(column string, values []string) {
+		if len(values) == 0 {
+			return
+		}
+		lowered := make([]string, len(values))
+		for i, v := range values {
+			lowered[i] = strings.ToLower(strings.TrimSpace(v))
+		}
+		name := "filter_" + column
+		if len(lowered) == 1 {
+			conditions = append(conditions, fmt.Sprintf("LOWER(%s.%s) = @%s", alias, column, name))
+			params = append(params, bigquery.QueryParameter{Name: name, Value: lowered[0]})
+			return
+		}
+		conditions = append(conditions, fmt.Sprintf("LOWER(%s.%s) IN UNNEST(@%s)", alias, column, name))
+		params = append(params, bigquery.QueryParameter{Name: name, Value: lowered})
+	}
+	cast := func(values []string) {
+		if len(values) == 0 {
+			return
+		}
+		lowered := make([]string, len(values))
+		for i, v := range values {
+			lowered[i] = strings.ToLower(strings.TrimSpace(v))
+		}
+		conditions = append(conditions, fmt.Sprintf("EXISTS (SELECT 1 FROM UNNEST(%s.cast) c WHERE LOWER(c.actor_name) IN UNNEST(@filter_cast))", alias))
+		params = append(params, bigquery.QueryParameter{Name: "filter_cast", Value: lowered})
+	}
+	between := func(column string, r IntRange) {
+		if r.Min != 0 {
+			name := "filter_" + column + "_min"
+			conditions = append(conditions, fmt.Sprintf("%s.%s >= @%s", alias, column, name))
+			params = append(params, bigquery.QueryParameter{Name: name, Value: r.Min})
+		}
+		if r.Max != 0 {
+			name := "filter_" + column + "_max"
+			conditions = append(conditions, fmt.Sprintf("%s.%s <= @%s", alias, column, name))
+			params = append(params, bigquery.QueryParameter{Name: name, Value: r.Max})
+		}
+	}
+
+	in("category", f.Category)
+	in("genre", f.Genre)
+	in("rating", f.Rating)
+	in("director", f.Director)
+	cast(f.Cast)
+	between("release_year", f.ReleaseYear)
+	between("length_in_seconds", f.LengthInSeconds)
+
+	return strings.Join(conditions, " AND "), params
+}
+
+// Matches returns whether the media matches the filter, as the condition of Where would.
+func (f *SceneFilter) Matches(media *model.Media) bool {
+	if f == nil {
+		return true
+	}
+	in := func(value string, values []string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		value = strings.ToLower(value)
+		for _, v := range values {
+			if strings.ToLower(strings.TrimSpace(v)) == value {
+				return true
+			}
+		}
+		return false
+	}
+	cast := func(values []string) bool {
+		if len(values) == 0 {
+			return true
+		}
+		for _, member := range media.Cast {
+			if member != nil && in(member.ActorName, values) {
+				return true
+			}
+		}
+		return false
+	}
+	between := func(value int, r IntRange) bool {
+		return (r.Min == 0 || value >= r.Min) && (r.Max == 0 || value <= r.Max)
+	}
+	return in(media.Category, f.Category) &&
+		in(media.Genre, f.Genre) &&
+		in(media.Rating, f.Rating) &&
+		in(media.Director, f.Director) &&
+		cast(f.Cast) &&
+		between(media.ReleaseYear, f.ReleaseYear) &&
+		between(media.LengthInSeconds, f.LengthInSeconds)
+}
+
+// Merge returns the filter with the fields set in the override replacing its own.
+func (f *SceneFilter) Merge(override *SceneFilter) *SceneFilter {
+	out := &SceneFilter{}
+	if f != nil {
+		*out = *f
+	}
+	if override == nil {
+		return out
+	}
+	if len(override.Category) > 0 {
+		out.Category = override.Category
+	}
+	if len(override.Genre) > 0 {
+		out.Genre = override.Genre
+	}
+	if len(override.Rating) > 0 {
+		out.Rating = override.Rating
+	}
+	if len(override.Director) > 0 {
+		out.Director = override.Director
+	}
+	if len(override.Cast) > 0 {
+		out.Cast = override.Cast
+	}
+	if override.ReleaseYear != (IntRange{}) {
+		out.ReleaseYear = override.ReleaseYear
+	}
+	if override.LengthInSeconds != (IntRange{}) {
+		out.LengthInSeconds = override.LengthInSeconds
+	}
+	return out
+}
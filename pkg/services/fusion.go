@@ -0,0 +1,69 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// DefaultRRFK is the rank constant of reciprocal rank fusion, it dampens the
+// advantage of the top ranks so agreement between rankings counts more.
+const DefaultRRFK = 60
+
+// RankedList is a ranking of scenes and its weight in a fusion.
+type RankedList struct {
+	Results []*model.SceneMatchResult
+	Weight  float64
+}
+
+// ReciprocalRankFusion fuses the rankings, scoring each scene with the sum of
+// weight / (k + rank) over the rankings it appears in, ranks start at 1.
+// The fused results are ordered by score and carry it in their Score, along with the
+// similarity and distance of the vector ranking.
+func ReciprocalRankFusion(k float64, rankings ...RankedList) []*model.SceneMatchResult {
+	fused := make(map[string]*model.SceneMatchResult)
+	order := make([]*model.SceneMatchResult, 0)
+	for _, ranking := range rankings {
+		for i, result := range ranking.Results {
+			key := fmt.Sprintf("%s/%d", result.MediaId, result.SequenceNumber)
+			match, ok := fused[key]
+			if !ok {
+				match = &model.SceneMatchResult{MediaId: result.MediaId, SequenceNumber: result.SequenceNumber}
+				fused[key] = match
+				order = append(order, match)
+			}
+			if match.Similarity == 0 && result.Similarity != 0 {
+				match.Similarity, match.Distance = result.Similarity, result.Distance
+			}
+			match.Score += ranking.Weight / (k + float64(i+1))
+		}
+	}
+	// A stable sort keeps ties in the order of the first ranking they appear in
+	slices.SortStableFunc(order, func(a, b *model.SceneMatchResult) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		}
+		return 0
+	})
+	return order
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/genai"
+)
+
+// InterpretSystemInstruction instructs the model interpreting search queries.
+const InterpretSystemInstruction = "You interpret the queries of a video scene search. " +
+	"Move the constraints on the media of the scenes out of the query into the filter: the category, " +
+	"the genres, the directors, the actors of the cast and the release year range, e.g. the 90s are 1990 to 1999. " +
+	"Only set the filters the query states. The query keeps the description of the scenes, " +
+	"unchanged if the query states no filter, and is never empty."
+
+// QueryInterpretation is a search query split into the description of the scenes searched
+// and the filter of their media, shown to users so they can edit it.
+type QueryInterpretation struct {
+	Query  string      `json:"query"`
+	Filter SceneFilter `json:"filter"`
+}
+
+// InterpretService asks a generative model to interpret search queries.
+type InterpretService struct {
+	Model      cloud.GenerativeModel
+	Categories []string // The categories of the media, a category filter must be one of them.
+}
+
+// Interpret splits the query into the description of the scenes and the filter of their media.
+// Categories that are not one of the known categories are dropped.
+func (i *InterpretService) Interpret(ctx context.Context, query string) (*QueryInterpretation, error) {
+	prompt := fmt.Sprintf("Categories: %s\n\nQuery: %s", strings.Join(i.Categories, ", "), query)
+	contents := []*genai.Content{genai.NewContentFromText(prompt, genai.RoleUser)}
+	resp, err := i.Model.GenerateContent(ctx, InterpretSystemInstruction, contents, model.NewQueryInterpretationSchema())
+	if err != nil {
+		return nil, err
+	}
+	out := &QueryInterpretation{}
+	if err = json.Unmarshal([]byte(resp.Text()), out); err != nil {
+		return nil, fmt.Errorf("failed to parse the interpretation of the search query: %w", err)
+	}
+	if strings.TrimSpace(out.Query) == "" {
+		out.Query = query
+	}
+	categories := make([]string, 0)
+	for _, category := range out.Filter.Category {
+		for _, known := range i.Categories {
+			if strings.EqualFold(category, known) {
+				categories = append(categories, known)
+				break
+			}
+		}
+	}
+	out.Filter.Category = categories
+	return out, nil
+}
+
+// InterpretedCursor returns the next cursor of a page of the interpretation of the typed query
+// carrying the interpretation, so the next pages search it without interpreting the query again.
+func InterpretedCursor(cursor string, query string, interpretation *QueryInterpretation) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	position := &searchCursor{}
+	if err := decodeCursor(cursor, position); err != nil {
+		return "", err
+	}
+	position.Typed = NormalizeQuery(query)
+	position.Interpretation = interpretation
+	return encodeCursor(position), nil
+}
+
+// CursorInterpretation returns the interpretation carried by the cursor of a search page, nil for
+// the first page and the pages of a search that wasn't interpreted. A cursor of the interpretation
+// of another query is invalid.
+func CursorInterpretation(cursor string, query string) (*QueryInterpretation, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	position := &searchCursor{}
+	if err := decodeCursor(cursor, position); err != nil {
+		return nil, err
+	}
+	if position.Interpretation != nil && position.Typed != NormalizeQuery(query) {
+		return nil, fmt.Errorf("%w: the cursor belongs to another search", ErrInvalidCursor)
+	}
+	return position.Interpretation, nil
+}
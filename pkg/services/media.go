@@ -18,11 +18,18 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"google.golang.org/api/iterator"
 )
 
 type MediaService struct {
@@ -38,8 +45,12 @@ func (s *MediaService) GetFQN() string {
 
 // Get returns a media object by id, or an error if it doesn't exist
 func (s *MediaService) Get(ctx context.Context, id string) (media *model.Media, err error) {
-	queryText := fmt.Sprintf(QryFindMediaById, s.GetFQN(), id)
-	q := s.BigqueryClient.Query(queryText)
+	q, err := cloud.Select("*").From(s.GetFQN(), "").
+		Where("id = @id", bigquery.QueryParameter{Name: "id", Value: id}).
+		Query(s.BigqueryClient)
+	if err != nil {
+		return media, err
+	}
 	itr, err := q.Read(ctx)
 	if err != nil {
 		return media, err
@@ -52,9 +63,14 @@ func (s *MediaService) Get(ctx context.Context, id string) (media *model.Media,
 
 // GetScene returns a scene in a specified media type by its sequence number
 func (s *MediaService) GetScene(ctx context.Context, id string, sceneSequence int) (scene *model.Scene, err error) {
-	fqMediaTableName := strings.Replace(s.BigqueryClient.Dataset(s.DatasetName).Table(s.MediaTable).FullyQualifiedName(), ":", ".", -1)
-	queryText := fmt.Sprintf(QryGetScene, fqMediaTableName, id, sceneSequence)
-	q := s.BigqueryClient.Query(queryText)
+	q, err := cloud.Select("s.sequence", "s.start", "s.`end`", "s.script").
+		From(s.GetFQN(), "m").Unnest("m.scenes", "s").
+		Where("m.id = @id", bigquery.QueryParameter{Name: "id", Value: id}).
+		Where("s.sequence = @sequence", bigquery.QueryParameter{Name: "sequence", Value: sceneSequence}).
+		Query(s.BigqueryClient)
+	if err != nil {
+		return scene, err
+	}
 	itr, err := q.Read(ctx)
 	if err != nil {
 		return scene, err
@@ -64,3 +80,287 @@ func (s *MediaService) GetScene(ctx context.Context, id string, sceneSequence in
 	err = itr.Next(scene)
 	return scene, err
 }
+
+// SceneKey identifies a scene by its media id and sequence number.
+type SceneKey struct {
+	MediaId        string
+	SequenceNumber int
+}
+
+func (k SceneKey) String() string {
+	return fmt.Sprintf("%s/%d", k.MediaId, k.SequenceNumber)
+}
+
+// GetMany returns the media of the ids by id in a single query, ids that don't exist are absent.
+func (s *MediaService) GetMany(ctx context.Context, ids []string) (out map[string]*model.Media, err error) {
+	out = make(map[string]*model.Media)
+	if len(ids) == 0 {
+		return out, nil
+	}
+	q, err := cloud.Select("*").From(s.GetFQN(), "").
+		Where("id IN UNNEST(@ids)", bigquery.QueryParameter{Name: "ids", Value: ids}).
+		Query(s.BigqueryClient)
+	if err != nil {
+		return out, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		media := &model.Media{}
+		err = itr.Next(media)
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out[media.Id] = media
+	}
+}
+
+// GetScenes returns the scenes of the keys by key in a single query, scenes that don't exist are absent.
+func (s *MediaService) GetScenes(ctx context.Context, keys []SceneKey) (out map[SceneKey]*model.Scene, err error) {
+	out = make(map[SceneKey]*model.Scene)
+	if len(keys) == 0 {
+		return out, nil
+	}
+	mediaIds := make([]string, 0)
+	sceneKeys := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if !slices.Contains(mediaIds, k.MediaId) {
+			mediaIds = append(mediaIds, k.MediaId)
+		}
+		sceneKeys = append(sceneKeys, k.String())
+	}
+	q, err := cloud.Select("m.id AS media_id", "s.sequence", "s.start", "s.`end`", "s.script").
+		From(s.GetFQN(), "m").Unnest("m.scenes", "s").
+		Where("m.id IN UNNEST(@media_ids)", bigquery.QueryParameter{Name: "media_ids", Value: mediaIds}).
+		Where("CONCAT(m.id, '/', CAST(s.sequence AS STRING)) IN UNNEST(@scene_keys)", bigquery.QueryParameter{Name: "scene_keys", Value: sceneKeys}).
+		Query(s.BigqueryClient)
+	if err != nil {
+		return out, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return out, err
+	}
+	for {
+		var row struct {
+			MediaId  string `bigquery:"media_id"`
+			Sequence int    `bigquery:"sequence"`
+			Start    string `bigquery:"start"`
+			End      string `bigquery:"end"`
+			Script   string `bigquery:"script"`
+		}
+		err = itr.Next(&row)
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out[SceneKey{MediaId: row.MediaId, SequenceNumber: row.Sequence}] = &model.Scene{
+			SequenceNumber: row.Sequence,
+			Start:          row.Start,
+			End:            row.End,
+			Script:         row.Script,
+		}
+	}
+}
+
+// Hydrate returns the media of the ranked scene matches with two queries, see RankMedia.
+func (s *MediaService) Hydrate(ctx context.Context, matches []*model.SceneMatchResult) ([]*model.MediaMatchResult, error) {
+	mediaIds := make([]string, 0)
+	keys := make([]SceneKey, 0, len(matches))
+	for _, m := range matches {
+		if !slices.Contains(mediaIds, m.MediaId) {
+			mediaIds = append(mediaIds, m.MediaId)
+		}
+		keys = append(keys, SceneKey{MediaId: m.MediaId, SequenceNumber: m.SequenceNumber})
+	}
+
+	var media map[string]*model.Media
+	var scenes map[SceneKey]*model.Scene
+	var mediaErr, scenesErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		media, mediaErr = s.GetMany(ctx, mediaIds)
+	}()
+	go func() {
+		defer wg.Done()
+		scenes, scenesErr = s.GetScenes(ctx, keys)
+	}()
+	wg.Wait()
+	if err := errors.Join(mediaErr, scenesErr); err != nil {
+		return nil, err
+	}
+	return RankMedia(matches, media, scenes), nil
+}
+
+// RankMedia groups the ranked scene matches by media, keeping the ranking order: media are
+// ordered by their best ranked scene and hold their matched scenes in rank order. The score
+// of a media is the reciprocal rank fusion of its scene ranks, the sum of 1 / (DefaultRRFK + rank).
+// Matches of media or scenes absent from the maps are skipped.
+func RankMedia(matches []*model.SceneMatchResult, media map[string]*model.Media, scenes map[SceneKey]*model.Scene) []*model.MediaMatchResult {
+	out := make([]*model.MediaMatchResult, 0)
+	byId := make(map[string]*model.MediaMatchResult)
+	for rank, m := range matches {
+		scene, ok := scenes[SceneKey{MediaId: m.MediaId, SequenceNumber: m.SequenceNumber}]
+		if !ok {
+			continue
+		}
+		result, ok := byId[m.MediaId]
+		if !ok {
+			med, found := media[m.MediaId]
+			if !found {
+				continue
+			}
+			// Copy the media so its scenes can be replaced by the matched scenes
+			copied := *med
+			copied.Scenes = make([]*model.Scene, 0)
+			result = &model.MediaMatchResult{Media: &copied, Matches: make([]*model.SceneMatchResult, 0)}
+			byId[m.MediaId] = result
+			out = append(out, result)
+		}
+		result.Scenes = append(result.Scenes, scene)
+		result.Matches = append(result.Matches, m)
+		result.Score += 1 / (DefaultRRFK + float64(rank+1))
+	}
+	return out
+}
+
+// The sort fields of media listings.
+const (
+	MediaSortCreateDate  = "create_date"
+	MediaSortTitle       = "title"
+	MediaSortReleaseYear = "release_year"
+	// DefaultMediaSort lists the newest media first.
+	DefaultMediaSort = "-" + MediaSortCreateDate
+)
+
+// MediaSort is the order of a media listing, by a field and then by id.
+type MediaSort struct {
+	Field      string
+	Descending bool
+}
+
+// ParseMediaSort parses a sort field, descending when prefixed with "-", empty is the DefaultMediaSort.
+func ParseMediaSort(in string) (MediaSort, error) {
+	if in == "" {
+		in = DefaultMediaSort
+	}
+	sort := MediaSort{Field: strings.TrimPrefix(in, "-"), Descending: strings.HasPrefix(in, "-")}
+	switch sort.Field {
+	case MediaSortCreateDate, MediaSortTitle, MediaSortReleaseYear:
+		return sort, nil
+	default:
+		return sort, fmt.Errorf("invalid media sort: %s", in)
+	}
+}
+
+func (s MediaSort) String() string {
+	if s.Descending {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// value returns the value of the sort field of the media, formatted for a cursor.
+func (s MediaSort) value(media *model.Media) string {
+	switch s.Field {
+	case MediaSortTitle:
+		return media.Title
+	case MediaSortReleaseYear:
+		return strconv.Itoa(media.ReleaseYear)
+	default:
+		return media.CreateDate.Format(time.RFC3339Nano)
+	}
+}
+
+// parse returns the query parameter value of a cursor value of the sort field.
+func (s MediaSort) parse(value string) (any, error) {
+	switch s.Field {
+	case MediaSortTitle:
+		return value, nil
+	case MediaSortReleaseYear:
+		return strconv.Atoi(value)
+	default:
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
+
+// mediaCursor is the position after the last media of a listing page.
+type mediaCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Id    string `json:"i"`
+}
+
+// MediaPage is a page of a media listing, the next cursor is empty on the last page.
+type MediaPage struct {
+	Media      []*model.Media
+	NextCursor string
+}
+
+// List returns a page of the media matching the filter without their scenes, in the sort
+// order. The cursor of the next page is returned with the page, an empty cursor is the first
+// page. Pages are keyed by the sort value and id of the last media, so they stay stable when
+// media are added or removed.
+func (s *MediaService) List(ctx context.Context, filter *SceneFilter, sort MediaSort, cursor string, pageSize int) (*MediaPage, error) {
+	pageSize = PageSize(pageSize)
+	op, direction := ">", "ASC"
+	if sort.Descending {
+		op, direction = "<", "DESC"
+	}
+
+	where, params := filter.Where("m")
+	b := cloud.Select("m.* EXCEPT (scenes)").From(s.GetFQN(), "m").Where(where, params...)
+	if cursor != "" {
+		var position mediaCursor
+		if err := decodeCursor(cursor, &position); err != nil {
+			return nil, err
+		}
+		if position.Sort != sort.String() {
+			return nil, fmt.Errorf("%w: the cursor is sorted by %s", ErrInvalidCursor, position.Sort)
+		}
+		value, err := sort.parse(position.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		b.Where(fmt.Sprintf("m.%[1]s %[2]s @cursor_value OR (m.%[1]s = @cursor_value AND m.id %[2]s @cursor_id)", sort.Field, op),
+			bigquery.QueryParameter{Name: "cursor_value", Value: value},
+			bigquery.QueryParameter{Name: "cursor_id", Value: position.Id})
+	}
+	// One more media than the page tells whether there is a next page
+	q, err := b.OrderBy("m."+sort.Field+" "+direction, "m.id "+direction).Limit(pageSize + 1).Query(s.BigqueryClient)
+	if err != nil {
+		return nil, err
+	}
+	itr, err := q.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &MediaPage{Media: make([]*model.Media, 0)}
+	for {
+		media := &model.Media{}
+		err = itr.Next(media)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		page.Media = append(page.Media, media)
+	}
+	if len(page.Media) > pageSize {
+		page.Media = page.Media[:pageSize]
+		last := page.Media[pageSize-1]
+		page.NextCursor = encodeCursor(&mediaCursor{Sort: sort.String(), Value: sort.value(last), Id: last.Id})
+	}
+	return page, nil
+}
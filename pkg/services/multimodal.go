@@ -0,0 +1,75 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// ErrMultimodalDisabled is returned by image and clip searches without a multimodal embedding model.
+var ErrMultimodalDisabled = errors.New("image and clip search is not configured")
+
+// ErrUnsupportedMediaType is returned for a query that is neither an image nor a video clip.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// FindScenesByImage returns the scenes with keyframes nearest to the image, nearest first.
+// Scenes below the MultimodalMinSimilarity are dropped.
+func (s *SearchService) FindScenesByImage(ctx context.Context, image []byte, filter *SceneFilter, maxResults int) ([]*model.SceneMatchResult, error) {
+	if s.MultimodalModel == nil {
+		return make([]*model.SceneMatchResult, 0), ErrMultimodalDisabled
+	}
+	vector, err := s.MultimodalModel.EmbedImage(ctx, image)
+	if err != nil {
+		return make([]*model.SceneMatchResult, 0), err
+	}
+	return s.findScenesByKeyframe(ctx, vector, filter, maxResults)
+}
+
+// FindScenesByClip returns the scenes with keyframes nearest to the video clip, nearest first.
+func (s *SearchService) FindScenesByClip(ctx context.Context, clip []byte, filter *SceneFilter, maxResults int) ([]*model.SceneMatchResult, error) {
+	if s.MultimodalModel == nil {
+		return make([]*model.SceneMatchResult, 0), ErrMultimodalDisabled
+	}
+	vector, err := s.MultimodalModel.EmbedVideo(ctx, clip)
+	if err != nil {
+		return make([]*model.SceneMatchResult, 0), err
+	}
+	return s.findScenesByKeyframe(ctx, vector, filter, maxResults)
+}
+
+// FindScenesByMedia searches by image or by clip according to the MIME type of the query.
+func (s *SearchService) FindScenesByMedia(ctx context.Context, mimeType string, data []byte, filter *SceneFilter, maxResults int) ([]*model.SceneMatchResult, error) {
+	switch {
+	case strings.HasPrefix(mimeType, "image/"):
+		return s.FindScenesByImage(ctx, data, filter, maxResults)
+	case strings.HasPrefix(mimeType, "video/"):
+		return s.FindScenesByClip(ctx, data, filter, maxResults)
+	default:
+		return make([]*model.SceneMatchResult, 0), fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mimeType)
+	}
+}
+
+// findScenesByKeyframe returns the scenes of the multimodal index nearest to the vector.
+func (s *SearchService) findScenesByKeyframe(ctx context.Context, vector []float64, filter *SceneFilter, maxResults int) ([]*model.SceneMatchResult, error) {
+	return s.queryIndex(ctx, s.MultimodalIndex, &cloud.VectorQuery{Vector: vector, TopK: maxResults, Scope: s.mediaScope(filter)}, s.MultimodalMinSimilarity)
+}
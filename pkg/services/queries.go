@@ -16,8 +16,11 @@
 
 package services
 
+// The statement fragments of the service queries, values are bound as named parameters.
 const (
-	QrySequenceKnn   = "SELECT base.media_id, base.sequence_number FROM VECTOR_SEARCH(TABLE `%s`, 'embeddings', (SELECT [ %s ] as embed), top_k => %d, distance_type => 'EUCLIDEAN') ORDER BY distance asc"
-	QryFindMediaById = "SELECT * from `%s` WHERE id = '%s'"
-	QryGetScene      = "SELECT sequence, start, `end`, script FROM `%s`, UNNEST(scenes) as s WHERE id = '%s' and s.sequence = %d"
+	// QryKeywordMatch full-text matches @query against the scene scripts, media titles and cast
+	// of the media table aliased m, with the scenes unnested as s.
+	QryKeywordMatch = "SEARCH(s.script, @query) OR SEARCH(m.title, @query) OR SEARCH(m.cast, @query)"
+	// QryKeywordScore scores a keyword match with the number of matching fields.
+	QryKeywordScore = "IF(SEARCH(s.script, @query), 1, 0) + IF(SEARCH(m.title, @query), 1, 0) + IF(SEARCH(m.cast, @query), 1, 0) AS score"
 )
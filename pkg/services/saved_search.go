@@ -0,0 +1,379 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/google/uuid"
+)
+
+// ErrSavedSearchNotFound is returned for a saved search id that isn't in the store.
+var ErrSavedSearchNotFound = errors.New("saved search not found")
+
+// ErrInvalidSavedSearch is returned for a saved search without a query.
+var ErrInvalidSavedSearch = errors.New("invalid saved search")
+
+// DefaultAlertSimilarity is the cosine similarity at which a scene is alerted when neither
+// the saved search nor the service sets one.
+const DefaultAlertSimilarity = 0.7
+
+// SavedSearch is a standing query, newly embedded scenes of media matching the filter
+// with at least the similarity to the query are alerted.
+type SavedSearch struct {
+	Id            string       `json:"id"`
+	Version       int          `json:"version"` // Incremented by each update.
+	CreateDate    time.Time    `json:"create_date"`
+	Name          string       `json:"name"`
+	Query         string       `json:"query"`
+	Filter        *SceneFilter `json:"filter,omitempty"`
+	MinSimilarity float64      `json:"min_similarity,omitempty"` // 0 is the similarity of the service.
+}
+
+func (s *SavedSearch) clone() *SavedSearch {
+	out := *s
+	if s.Filter != nil {
+		filter := *s.Filter
+		out.Filter = &filter
+	}
+	return &out
+}
+
+// SavedSearchStore persists saved searches, the searches it returns are copies owned by the caller.
+type SavedSearchStore interface {
+	// Put stores the search, replacing the search of the same id.
+	Put(ctx context.Context, search *SavedSearch) error
+	// Get returns the search or ErrSavedSearchNotFound.
+	Get(ctx context.Context, id string) (*SavedSearch, error)
+	// Delete removes the search or returns ErrSavedSearchNotFound.
+	Delete(ctx context.Context, id string) error
+	// List returns the searches, oldest first.
+	List(ctx context.Context) ([]*SavedSearch, error)
+}
+
+// MemorySavedSearchStore is a SavedSearchStore that does not survive a restart, it is safe for concurrent use.
+type MemorySavedSearchStore struct {
+	lock     sync.RWMutex
+	searches map[string]*SavedSearch
+	save     func(search *SavedSearch) error
+	remove   func(id string) error
+}
+
+func NewMemorySavedSearchStore() *MemorySavedSearchStore {
+	return &MemorySavedSearchStore{searches: make(map[string]*SavedSearch)}
+}
+
+func (s *MemorySavedSearchStore) Put(_ context.Context, search *SavedSearch) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.save != nil {
+		if err := s.save(search); err != nil {
+			return err
+		}
+	}
+	s.searches[search.Id] = search.clone()
+	return nil
+}
+
+func (s *MemorySavedSearchStore) Get(_ context.Context, id string) (*SavedSearch, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	search, ok := s.searches[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSavedSearchNotFound, id)
+	}
+	return search.clone(), nil
+}
+
+func (s *MemorySavedSearchStore) Delete(_ context.Context, id string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.searches[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrSavedSearchNotFound, id)
+	}
+	if s.remove != nil {
+		if err := s.remove(id); err != nil {
+			return err
+		}
+	}
+	delete(s.searches, id)
+	return nil
+}
+
+func (s *MemorySavedSearchStore) List(_ context.Context) ([]*SavedSearch, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	out := make([]*SavedSearch, 0, len(s.searches))
+	for _, search := range s.searches {
+		out = append(out, search.clone())
+	}
+	slices.SortFunc(out, func(a, b *SavedSearch) int {
+		if c := a.CreateDate.Compare(b.CreateDate); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Id, b.Id)
+	})
+	return out, nil
+}
+
+// NewFileSavedSearchStore creates a SavedSearchStore that writes each search as a JSON file
+// in the directory, searches already in the directory are loaded.
+func NewFileSavedSearchStore(dir string) (*MemorySavedSearchStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	store := NewMemorySavedSearchStore()
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		search := &SavedSearch{}
+		if err = json.Unmarshal(data, search); err != nil {
+			return nil, errors.Join(errors.New("invalid saved search file: "+file), err)
+		}
+		store.searches[search.Id] = search
+	}
+	store.save = func(search *SavedSearch) error {
+		return writeSavedSearch(dir, search)
+	}
+	store.remove = func(id string) error {
+		err := os.Remove(filepath.Join(dir, id+".json"))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	return store, nil
+}
+
+// writeSavedSearch writes to a temp file and renames it, so a partially written search is never loaded.
+func writeSavedSearch(dir string, search *SavedSearch) error {
+	data, err := json.Marshal(search)
+	if err != nil {
+		return err
+	}
+	tempFile, err := os.CreateTemp(dir, "saved-search-*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), filepath.Join(dir, search.Id+".json"))
+}
+
+// DefaultSavedSearchPath is the directory of the local saved search store when none is configured.
+var DefaultSavedSearchPath = filepath.Join(os.TempDir(), "media-search-saved-searches")
+
+// NewSavedSearchStore creates the saved search store of the configuration, the default is the
+// durable "local" store; searches of the "memory" store are lost on restart.
+func NewSavedSearchStore(config cloud.SavedSearchConfig) (SavedSearchStore, error) {
+	switch config.Store {
+	case "", "local":
+		path := config.Path
+		if path == "" {
+			path = DefaultSavedSearchPath
+		}
+		return NewFileSavedSearchStore(path)
+	case "memory":
+		return NewMemorySavedSearchStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown saved search store: %s", config.Store)
+	}
+}
+
+// SavedSearchService manages saved searches and alerts them of the matching scenes of new media.
+type SavedSearchService struct {
+	Store         SavedSearchStore
+	Search        *SearchService // The embeddings of the saved queries.
+	MinSimilarity float64        // The cosine similarity at which a scene is alerted, 0 is DefaultAlertSimilarity.
+	Notifier      cloud.Notifier
+	Alerts        *jobs.Queue // The queue of a SavedSearchAlertCommand retrying the alerts, nil notifies directly.
+}
+
+func validateSavedSearch(search *SavedSearch) error {
+	search.Query = strings.TrimSpace(search.Query)
+	if search.Query == "" {
+		return fmt.Errorf("%w: a query is required", ErrInvalidSavedSearch)
+	}
+	if search.Name = strings.TrimSpace(search.Name); search.Name == "" {
+		search.Name = search.Query
+	}
+	return nil
+}
+
+// Create validates and stores a new search, setting its id, version and date, the name is the query if empty.
+func (s *SavedSearchService) Create(ctx context.Context, search *SavedSearch) error {
+	if err := validateSavedSearch(search); err != nil {
+		return err
+	}
+	search.Id = uuid.New().String()
+	search.Version = 1
+	search.CreateDate = time.Now()
+	return s.Store.Put(ctx, search)
+}
+
+// Update validates and replaces the stored search of the id, keeping its date and incrementing its version.
+func (s *SavedSearchService) Update(ctx context.Context, id string, search *SavedSearch) error {
+	existing, err := s.Store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err = validateSavedSearch(search); err != nil {
+		return err
+	}
+	search.Id = existing.Id
+	search.Version = existing.Version + 1
+	search.CreateDate = existing.CreateDate
+	return s.Store.Put(ctx, search)
+}
+
+// Get returns the search or ErrSavedSearchNotFound.
+func (s *SavedSearchService) Get(ctx context.Context, id string) (*SavedSearch, error) {
+	return s.Store.Get(ctx, id)
+}
+
+// Delete removes the search or returns ErrSavedSearchNotFound.
+func (s *SavedSearchService) Delete(ctx context.Context, id string) error {
+	return s.Store.Delete(ctx, id)
+}
+
+// List returns the searches, oldest first.
+func (s *SavedSearchService) List(ctx context.Context) ([]*SavedSearch, error) {
+	return s.Store.List(ctx)
+}
+
+// Evaluate alerts each saved search matching the media of the scenes whose embeddings are at least
+// as similar to its query as its similarity, one alert per search. The searches are all evaluated,
+// their errors are returned together.
+func (s *SavedSearchService) Evaluate(ctx context.Context, media *model.Media, embeddings []*model.SceneEmbedding) error {
+	searches, err := s.Store.List(ctx)
+	if err != nil {
+		return err
+	}
+	errs := make([]error, 0)
+	for _, search := range searches {
+		if !search.Filter.Matches(media) {
+			continue
+		}
+		matches, err := s.match(ctx, search, embeddings)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("saved search %s: %w", search.Id, err))
+			continue
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		alert := &model.SavedSearchAlert{
+			SavedSearchId:      search.Id,
+			SavedSearchVersion: search.Version,
+			Name:               search.Name,
+			Query:              search.Query,
+			MediaId:            media.Id,
+			Title:              media.Title,
+			Matches:            matches,
+			CreateDate:         time.Now(),
+		}
+		if err = s.notify(ctx, alert); err != nil {
+			errs = append(errs, fmt.Errorf("saved search %s: %w", search.Id, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// notify enqueues the alert as a job of the Alerts queue, where failed alerts are retried
+// and kept, or notifies it directly without a queue. Enqueuing the alert of a search version
+// and media again returns the job already recorded, so it isn't alerted twice, while an
+// updated search alerts the media again.
+func (s *SavedSearchService) notify(ctx context.Context, alert *model.SavedSearchAlert) error {
+	if s.Alerts == nil {
+		return s.Notifier.Notify(ctx, alert)
+	}
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	_, err = s.Alerts.Enqueue(ctx, AlertJobId(alert), string(payload), nil)
+	return err
+}
+
+// AlertJobId returns the message id of the job of the alert in the Alerts queue,
+// one per version of the saved search and media.
+func AlertJobId(alert *model.SavedSearchAlert) string {
+	return fmt.Sprintf("%s-v%d-%s", alert.SavedSearchId, alert.SavedSearchVersion, alert.MediaId)
+}
+
+// match returns the scenes of the embeddings similar enough to the query of the search, most similar first.
+// Scenes are compared by cosine similarity whatever the metric of the vector index, so the similarity
+// thresholds mean the same for every index and aren't shrunk by the euclidean 1 / (1 + distance).
+func (s *SavedSearchService) match(ctx context.Context, search *SavedSearch, embeddings []*model.SceneEmbedding) ([]*model.SceneMatchResult, error) {
+	minSimilarity := search.MinSimilarity
+	if minSimilarity == 0 {
+		minSimilarity = s.MinSimilarity
+	}
+	if minSimilarity == 0 {
+		minSimilarity = DefaultAlertSimilarity
+	}
+	vector, err := s.Search.embedQuery(ctx, search.Query)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.SceneMatchResult, 0)
+	for _, embedding := range embeddings {
+		// Embeddings of another model can't be compared
+		if len(embedding.Embeddings) != len(vector) {
+			continue
+		}
+		distance := cloud.MetricCosine.Distance(vector, embedding.Embeddings)
+		similarity := cloud.MetricCosine.Similarity(distance)
+		if similarity < minSimilarity {
+			continue
+		}
+		out = append(out, &model.SceneMatchResult{
+			MediaId:        embedding.Id,
+			SequenceNumber: embedding.SequenceNumber,
+			Score:          similarity,
+			Similarity:     similarity,
+			Distance:       distance,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Similarity > out[j].Similarity })
+	return out, nil
+}
@@ -19,16 +19,52 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"slices"
 	"strings"
+	"sync"
 
 	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
 	"google.golang.org/api/iterator"
 	"google.golang.org/genai"
 )
 
+// SearchMode selects how scenes are matched to a query.
+type SearchMode string
+
+const (
+	SearchModeVector  SearchMode = "vector"  // Nearest scene embeddings of the query embedding.
+	SearchModeKeyword SearchMode = "keyword" // Full-text match of scripts, titles and cast.
+	SearchModeHybrid  SearchMode = "hybrid"  // Reciprocal rank fusion of the vector and keyword rankings.
+)
+
+// ErrInvalidSearchMode is returned for an unknown search mode.
+var ErrInvalidSearchMode = errors.New("invalid search mode")
+
+// DefaultSearchMode is the mode of searches without one.
+const DefaultSearchMode = SearchModeVector
+
+// ParseSearchMode returns the search mode by name, empty is the DefaultSearchMode.
+func ParseSearchMode(in string) (SearchMode, error) {
+	switch mode := SearchMode(strings.ToLower(in)); mode {
+	case "":
+		return DefaultSearchMode, nil
+	case SearchModeVector, SearchModeKeyword, SearchModeHybrid:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrInvalidSearchMode, in)
+	}
+}
+
+// HybridCandidateFactor is the number of candidates taken from each ranking per hybrid search result.
+const HybridCandidateFactor = 2
+
 type SearchService struct {
 	BigqueryClient *bigquery.Client
 	EmbeddingModel *genai.Models
@@ -36,39 +72,333 @@ type SearchService struct {
 	DatasetName    string
 	MediaTable     string
 	EmbeddingTable string
+	Index          cloud.VectorIndex // The nearest neighbour index of the scene embeddings.
+	VectorWeight   *float64          // The weight of the vector ranking in hybrid search, nil is 1 and 0 switches it off.
+	KeywordWeight  *float64          // The weight of the keyword ranking in hybrid search, nil is 1 and 0 switches it off.
+	RRFK           float64           // The rank constant of hybrid search, 0 is DefaultRRFK.
+	MinSimilarity  float64           // The similarity below which vector matches are dropped, 0 keeps every match.
+	FacetDepth     int               // The top scenes whose media are counted by facets, 0 is DefaultFacetDepth.
+	FacetSize      int               // The buckets of a facet, 0 is DefaultFacetSize.
+
+	EmbeddingCache *Cache[string, []float64]  // The embeddings of queries, keyed by model and normalized query, nil disables it.
+	ResultCache    *Cache[string, *ScenePage] // The pages of searches, cleared by InvalidateResults, nil disables it.
+
+	MultimodalModel cloud.MultimodalEmbeddingModel // The embedding model of image and clip queries, nil disables them.
+	MultimodalIndex cloud.VectorIndex              // The nearest neighbour index of the scene keyframe embeddings.
+
+	MultimodalMinSimilarity float64 // The similarity below which keyframe matches are dropped, 0 keeps every match.
 }
 
-func (s *SearchService) FindScenes(ctx context.Context, query string, maxResults int) (out []*model.SceneMatchResult, err error) {
-	out = make([]*model.SceneMatchResult, 0)
+// MaxSearchDepth is the deepest rank a search pages to.
+const MaxSearchDepth = 1000
 
-	// Create contents from query
-	contents := []*genai.Content{
-		genai.NewContentFromText(query, genai.RoleUser),
+// ScenePage is a page of ranked scene matches, the next cursor is empty on the last page.
+// The facets, when requested, count the media of the top scenes of the search.
+type ScenePage struct {
+	Results    []*model.SceneMatchResult
+	NextCursor string
+	Facets     Facets
+}
+
+// searchCursor is the position after the last scene of a search page, bound to
+// the search by a fingerprint of its query, mode and filter.
+type searchCursor struct {
+	Fingerprint    string `json:"f"`
+	Offset         int    `json:"o"`
+	MediaId        string `json:"m"`
+	SequenceNumber int    `json:"n"`
+
+	// The query as typed and its interpretation, searched instead of the query, see InterpretedCursor.
+	Typed          string               `json:"t,omitempty"`
+	Interpretation *QueryInterpretation `json:"i,omitempty"`
+	// The id of the recorded search, see RecordedCursor.
+	SearchId string `json:"s,omitempty"`
+}
+
+func searchFingerprint(query string, mode SearchMode, filter *SceneFilter) string {
+	in, _ := json.Marshal([]any{NormalizeQuery(query), mode, filter})
+	sum := sha256.Sum256(in)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SearchScenes returns a page of the scenes of the media matching the filter that match
+// the query in the given mode, best match first. A nil filter matches every media.
+// The cursor of the next page is returned with the page, an empty cursor is the first page.
+// A page continues after the last scene of the previous page, or at its rank when the
+// scene is no longer found, so pages neither repeat nor skip scenes of a stable ranking.
+// The facet fields are counted over the media of the FacetDepth top scenes, the same for every page.
+func (s *SearchService) SearchScenes(ctx context.Context, query string, mode SearchMode, filter *SceneFilter, facets []string, cursor string, pageSize int) (*ScenePage, error) {
+	pageSize = PageSize(pageSize)
+	key, _ := json.Marshal([]any{s.ModelName, NormalizeQuery(query), mode, filter, facets, cursor, pageSize})
+	if page, ok := s.ResultCache.Get(ctx, string(key)); ok {
+		return page.clone(), nil
 	}
-	searchEmbeddings, _ := s.EmbeddingModel.EmbedContent(ctx, s.ModelName, contents, nil)
+	page, err := s.searchPage(ctx, query, mode, filter, facets, cursor, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	s.ResultCache.Put(string(key), page.clone())
+	return page, nil
+}
 
-	fqEmbeddingTable := strings.Replace(s.BigqueryClient.Dataset(s.DatasetName).Table(s.EmbeddingTable).FullyQualifiedName(), ":", ".", -1)
+// InvalidateResults clears the cached search pages, called when the embeddings of the index change.
+func (s *SearchService) InvalidateResults() {
+	s.ResultCache.Clear()
+}
 
-	var stringArray []string
-	for _, f := range searchEmbeddings.Embeddings[0].Values {
-		stringArray = append(stringArray, strconv.FormatFloat(float64(f), 'f', -1, 64))
+// NormalizeQuery returns the query in lower case with single spaces, queries differing
+// only in case and spacing share their cached embeddings and results.
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// EmbeddingCacheKey returns the key of the query embedding of the model in the EmbeddingCache.
+func EmbeddingCacheKey(modelName string, query string) string {
+	return modelName + "\x00" + NormalizeQuery(query)
+}
+
+// clone copies the page and its results, so cached pages aren't changed by their users.
+func (p *ScenePage) clone() *ScenePage {
+	out := &ScenePage{NextCursor: p.NextCursor, Facets: p.Facets, Results: make([]*model.SceneMatchResult, len(p.Results))}
+	for i, r := range p.Results {
+		result := *r
+		out.Results[i] = &result
+	}
+	return out
+}
+
+// searchPage returns the page of the search, see SearchScenes.
+func (s *SearchService) searchPage(ctx context.Context, query string, mode SearchMode, filter *SceneFilter, facets []string, cursor string, pageSize int) (*ScenePage, error) {
+	fingerprint := searchFingerprint(query, mode, filter)
+	var position *searchCursor
+	if cursor != "" {
+		position = &searchCursor{}
+		if err := decodeCursor(cursor, position); err != nil {
+			return nil, err
+		}
+		if position.Fingerprint != fingerprint {
+			return nil, fmt.Errorf("%w: the cursor belongs to another search", ErrInvalidCursor)
+		}
+	}
+
+	offset := 0
+	if position != nil {
+		offset = position.Offset
+	}
+	// One more result than the page tells whether there is a next page
+	depth := offset + pageSize + 1
+	facetDepth := s.FacetDepth
+	if facetDepth <= 0 {
+		facetDepth = DefaultFacetDepth
+	}
+	if len(facets) > 0 {
+		depth = max(depth, facetDepth)
+	}
+	results, err := s.searchScenes(ctx, query, mode, filter, min(depth, MaxSearchDepth))
+	if err != nil {
+		return nil, err
+	}
+	start := min(offset, len(results))
+	if position != nil {
+		for i, r := range results {
+			if r.MediaId == position.MediaId && r.SequenceNumber == position.SequenceNumber {
+				start = i + 1
+				break
+			}
+		}
+	}
+	end := min(start+pageSize, len(results))
+
+	page := &ScenePage{Results: results[start:end]}
+	if end < len(results) {
+		last := results[end-1]
+		page.NextCursor = encodeCursor(&searchCursor{
+			Fingerprint:    fingerprint,
+			Offset:         end,
+			MediaId:        last.MediaId,
+			SequenceNumber: last.SequenceNumber,
+		})
+	}
+	if len(facets) > 0 {
+		mediaIds := make([]string, 0)
+		for _, r := range results[:min(facetDepth, len(results))] {
+			if !slices.Contains(mediaIds, r.MediaId) {
+				mediaIds = append(mediaIds, r.MediaId)
+			}
+		}
+		if page.Facets, err = s.FacetCounts(ctx, mediaIds, facets); err != nil {
+			return nil, err
+		}
+	}
+	return page, nil
+}
+
+// searchScenes returns the top scenes of the search in the given mode.
+func (s *SearchService) searchScenes(ctx context.Context, query string, mode SearchMode, filter *SceneFilter, maxResults int) ([]*model.SceneMatchResult, error) {
+	switch mode {
+	case "":
+		return s.searchScenes(ctx, query, DefaultSearchMode, filter, maxResults)
+	case SearchModeVector:
+		return s.FindScenes(ctx, query, filter, maxResults)
+	case SearchModeKeyword:
+		return s.FindScenesByKeyword(ctx, query, filter, maxResults)
+	case SearchModeHybrid:
+		return s.findScenesHybrid(ctx, query, filter, maxResults)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSearchMode, mode)
 	}
+}
 
-	queryText := fmt.Sprintf(QrySequenceKnn, fqEmbeddingTable, strings.Join(stringArray, ","), maxResults)
+// FindScenesByKeyword returns the scenes whose script, media title or cast match the query,
+// scenes matching more of the fields first.
+func (s *SearchService) FindScenesByKeyword(ctx context.Context, query string, filter *SceneFilter, maxResults int) (out []*model.SceneMatchResult, err error) {
+	out = make([]*model.SceneMatchResult, 0)
+	fqMediaTable := strings.Replace(s.BigqueryClient.Dataset(s.DatasetName).Table(s.MediaTable).FullyQualifiedName(), ":", ".", -1)
 
-	q := s.BigqueryClient.Query(queryText)
+	where, params := filter.Where("m")
+	q, err := cloud.Select("m.id AS media_id", "s.sequence AS sequence_number", QryKeywordScore).
+		From(fqMediaTable, "m").Unnest("m.scenes", "s").
+		Where(QryKeywordMatch, bigquery.QueryParameter{Name: "query", Value: query}).
+		Where(where, params...).
+		OrderBy("score DESC", "media_id", "sequence_number").
+		Limit(maxResults).
+		Query(s.BigqueryClient)
+	if err != nil {
+		return out, err
+	}
 	itr, err := q.Read(ctx)
 	if err != nil {
 		return out, err
 	}
-
 	for {
 		var r = &model.SceneMatchResult{}
-		err := itr.Next(r)
+		err = itr.Next(r)
 		if err == iterator.Done {
-			break
+			return out, nil
+		}
+		if err != nil {
+			return out, err
 		}
 		out = append(out, r)
 	}
-	return out, err
+}
+
+// findScenesHybrid runs the vector and keyword searches at the same time and fuses their rankings,
+// a ranking with a weight of 0 isn't searched.
+func (s *SearchService) findScenesHybrid(ctx context.Context, query string, filter *SceneFilter, maxResults int) ([]*model.SceneMatchResult, error) {
+	candidates := maxResults * HybridCandidateFactor
+	vectorWeight, keywordWeight := weightOrDefault(s.VectorWeight), weightOrDefault(s.KeywordWeight)
+
+	var wg sync.WaitGroup
+	var vectorResults, keywordResults []*model.SceneMatchResult
+	var vectorErr, keywordErr error
+	if vectorWeight != 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vectorResults, vectorErr = s.FindScenes(ctx, query, filter, candidates)
+		}()
+	}
+	if keywordWeight != 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keywordResults, keywordErr = s.FindScenesByKeyword(ctx, query, filter, candidates)
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(vectorErr, keywordErr); err != nil {
+		return nil, err
+	}
+
+	k := s.RRFK
+	if k <= 0 {
+		k = DefaultRRFK
+	}
+	out := ReciprocalRankFusion(k,
+		RankedList{Results: vectorResults, Weight: vectorWeight},
+		RankedList{Results: keywordResults, Weight: keywordWeight})
+	if len(out) > maxResults {
+		out = out[:maxResults]
+	}
+	return out, nil
+}
+
+// weightOrDefault returns the weight, 1 if it isn't set.
+func weightOrDefault(weight *float64) float64 {
+	if weight == nil {
+		return 1
+	}
+	return *weight
+}
+
+// FindScenes returns the scenes nearest to the query embedding, the embeddings are
+// pre-filtered to the media matching the filter, a nil filter matches every media.
+// The scenes are scored with their similarity, scenes below the MinSimilarity are dropped.
+func (s *SearchService) FindScenes(ctx context.Context, query string, filter *SceneFilter, maxResults int) (out []*model.SceneMatchResult, err error) {
+	out = make([]*model.SceneMatchResult, 0)
+
+	vector, err := s.embedQuery(ctx, query)
+	if err != nil {
+		return out, err
+	}
+	return s.queryIndex(ctx, s.Index, &cloud.VectorQuery{Vector: vector, TopK: maxResults, Scope: s.mediaScope(filter)}, s.MinSimilarity)
+}
+
+// embedQuery returns the embedding of the query, cached by model and normalized query.
+func (s *SearchService) embedQuery(ctx context.Context, query string) ([]float64, error) {
+	key := EmbeddingCacheKey(s.ModelName, query)
+	if vector, ok := s.EmbeddingCache.Get(ctx, key); ok {
+		return vector, nil
+	}
+	// Create contents from query
+	contents := []*genai.Content{
+		genai.NewContentFromText(query, genai.RoleUser),
+	}
+	searchEmbeddings, err := s.EmbeddingModel.EmbedContent(ctx, s.ModelName, contents, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed the search query: %w", err)
+	}
+	if len(searchEmbeddings.Embeddings) == 0 {
+		return nil, errors.New("failed to embed the search query: no embeddings returned")
+	}
+	vector := make([]float64, 0, len(searchEmbeddings.Embeddings[0].Values))
+	for _, f := range searchEmbeddings.Embeddings[0].Values {
+		vector = append(vector, float64(f))
+	}
+	s.EmbeddingCache.Put(key, vector)
+	return vector, nil
+}
+
+// queryIndex returns the scenes of the index matching the vector query, scored with their
+// similarity, scenes below the minimum similarity of the index are dropped.
+func (s *SearchService) queryIndex(ctx context.Context, index cloud.VectorIndex, vectorQuery *cloud.VectorQuery, minSimilarity float64) (out []*model.SceneMatchResult, err error) {
+	out = make([]*model.SceneMatchResult, 0)
+	matches, err := index.Query(ctx, vectorQuery)
+	if err != nil {
+		return out, err
+	}
+	for _, m := range matches {
+		if minSimilarity != 0 && m.Similarity < minSimilarity {
+			continue
+		}
+		out = append(out, &model.SceneMatchResult{
+			MediaId:        m.MediaId,
+			SequenceNumber: m.SequenceNumber,
+			Score:          m.Similarity,
+			Similarity:     m.Similarity,
+			Distance:       m.Distance,
+		})
+	}
+	return out, nil
+}
+
+// mediaScope returns the scope of the media matching the filter, nil for every media.
+func (s *SearchService) mediaScope(filter *SceneFilter) *cloud.MediaScope {
+	where, params := filter.Where("m")
+	if where == "" {
+		return nil
+	}
+	fqMediaTable := strings.Replace(s.BigqueryClient.Dataset(s.DatasetName).Table(s.MediaTable).FullyQualifiedName(), ":", ".", -1)
+	return &cloud.MediaScope{Table: fqMediaTable, Alias: "m", Where: where, Params: params}
 }
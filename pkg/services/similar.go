@@ -0,0 +1,105 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+)
+
+// ErrNoEmbeddings is returned when the source of a similarity search has no embeddings.
+var ErrNoEmbeddings = errors.New("no embeddings")
+
+// SimilarScenes returns the scenes nearest to the stored embedding of the scene, most similar
+// first, never including the scene itself. The other scenes of its media are excluded when
+// excludeSource is set.
+func (s *SearchService) SimilarScenes(ctx context.Context, mediaId string, sequenceNumber int, excludeSource bool, maxResults int) ([]*model.SceneMatchResult, error) {
+	embeddings, err := s.Index.Get(ctx, mediaId)
+	if err != nil {
+		return nil, err
+	}
+	var source *model.SceneEmbedding
+	for _, e := range embeddings {
+		if e.SequenceNumber == sequenceNumber {
+			source = e
+		}
+	}
+	if source == nil {
+		return nil, fmt.Errorf("%w: scene %d of media %s", ErrNoEmbeddings, sequenceNumber, mediaId)
+	}
+
+	vectorQuery := &cloud.VectorQuery{Vector: source.Embeddings, TopK: maxResults}
+	if excludeSource {
+		vectorQuery.ExcludeMediaIds = []string{mediaId}
+	} else {
+		// One more match makes up for the scene itself
+		vectorQuery.TopK++
+	}
+	results, err := s.queryIndex(ctx, s.Index, vectorQuery, s.MinSimilarity)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*model.SceneMatchResult, 0, len(results))
+	for _, r := range results {
+		if r.MediaId == mediaId && r.SequenceNumber == sequenceNumber {
+			continue
+		}
+		out = append(out, r)
+	}
+	if len(out) > maxResults {
+		out = out[:maxResults]
+	}
+	return out, nil
+}
+
+// SimilarMedia returns the scenes nearest to the media vector of the media, the mean of
+// its scene embeddings, most similar first. Its own scenes are excluded when excludeSource is set.
+func (s *SearchService) SimilarMedia(ctx context.Context, mediaId string, excludeSource bool, maxResults int) ([]*model.SceneMatchResult, error) {
+	embeddings, err := s.Index.Get(ctx, mediaId)
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("%w: media %s", ErrNoEmbeddings, mediaId)
+	}
+	vectorQuery := &cloud.VectorQuery{Vector: MeanVector(embeddings), TopK: maxResults}
+	if excludeSource {
+		vectorQuery.ExcludeMediaIds = []string{mediaId}
+	}
+	return s.queryIndex(ctx, s.Index, vectorQuery, s.MinSimilarity)
+}
+
+// MeanVector returns the element-wise mean of the embeddings.
+func MeanVector(embeddings []*model.SceneEmbedding) []float64 {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	out := make([]float64, len(embeddings[0].Embeddings))
+	for _, e := range embeddings {
+		for i, v := range e.Embeddings {
+			out[i] += v
+		}
+	}
+	for i := range out {
+		out[i] /= float64(len(embeddings))
+	}
+	return out
+}
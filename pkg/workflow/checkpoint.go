@@ -0,0 +1,32 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package workflow
+
+import (
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+)
+
+// withCheckpoint wraps the command so its output, of type T, is restored from the
+// checkpoint store when the same media file is processed again, commands are
+// returned unchanged when checkpoints are disabled.
+func withCheckpoint[T any](store cor.CheckpointStore, command cor.Command) cor.Command {
+	if store == nil {
+		return command
+	}
+	return cor.NewCheckpointCommand[T](command, store, commands.CheckpointKey)
+}
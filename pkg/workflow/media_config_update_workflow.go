@@ -39,6 +39,7 @@ func (m *MediaConfigUpdateWorkflow) initializeChain() {
 
 	out.AddCommand(commands.NewMediaConfigUpdateCommand("config-update-command", m.config, m.templateService))
 
+	validateChain(out)
 	m.chain = out
 }
 
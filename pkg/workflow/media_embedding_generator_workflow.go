@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -35,15 +36,30 @@ import (
 	"google.golang.org/genai"
 )
 
+// EmbeddingHook is called with the script embeddings of a media after they are upserted into the index.
+type EmbeddingHook func(ctx goctx.Context, media *model.Media, embeddings []*model.SceneEmbedding) error
+
 type MediaEmbeddingGeneratorWorkflow struct {
 	cor.BaseCommand
-	genaiEmbedding         *genai.Models
-	ModelName              string
-	bigqueryClient         *bigquery.Client
-	dataset                string
-	mediaTable             string
-	embeddingTable         string
-	findEligibleMediaQuery string
+	genaiEmbedding *genai.Models
+	ModelName      string
+	bigqueryClient *bigquery.Client
+	index          cloud.VectorIndex
+	mediaTable     string
+
+	// The keyframes of the scenes are embedded when a multimodal embedding model is configured
+	multimodalEmbedding cloud.MultimodalEmbeddingModel
+	MultimodalModelName string
+	multimodalIndex     cloud.VectorIndex
+	blobStore           cloud.BlobStore
+	ffmpegCommand       string
+
+	hooks []EmbeddingHook
+}
+
+// OnEmbedded adds a hook called after the scripts of a media are embedded, e.g. to evaluate saved searches.
+func (m *MediaEmbeddingGeneratorWorkflow) OnEmbedded(hook EmbeddingHook) {
+	m.hooks = append(m.hooks, hook)
 }
 
 func (m *MediaEmbeddingGeneratorWorkflow) StartTimer() {
@@ -57,7 +73,7 @@ func (m *MediaEmbeddingGeneratorWorkflow) StartTimer() {
 			select {
 			case <-ticker.C:
 				traceCtx, span := tracer.Start(goctx.Background(), "media-embeddings")
-				chainCtx := cor.NewBaseContext()
+				chainCtx := cor.NewConcurrentContext()
 				chainCtx.SetContext(traceCtx)
 				m.Execute(chainCtx)
 				if chainCtx.HasErrors() {
@@ -74,21 +90,26 @@ func (m *MediaEmbeddingGeneratorWorkflow) StartTimer() {
 	}(m)
 }
 
-func NewMediaEmbeddingGeneratorWorkflow(config *cloud.Config, serviceClients *cloud.ServiceClients) *MediaEmbeddingGeneratorWorkflow {
+func NewMediaEmbeddingGeneratorWorkflow(config *cloud.Config, serviceClients *cloud.ServiceClients, ffmpegCommand string) *MediaEmbeddingGeneratorWorkflow {
 
 	fqMediaTableName := strings.Replace(serviceClients.BiqQueryClient.Dataset(config.BigQueryDataSource.DatasetName).Table(config.BigQueryDataSource.MediaTable).FullyQualifiedName(), ":", ".", -1)
-	fqEmbeddingTable := strings.Replace(serviceClients.BiqQueryClient.Dataset(config.BigQueryDataSource.DatasetName).Table(config.BigQueryDataSource.EmbeddingTable).FullyQualifiedName(), ":", ".", -1)
-	query := fmt.Sprintf("SELECT * FROM `%s` WHERE ID NOT IN (SELECT MEDIA_ID FROM `%s`)", fqMediaTableName, fqEmbeddingTable)
+
+	if len(strings.Trim(ffmpegCommand, " ")) == 0 {
+		ffmpegCommand = DefaultFfmpegCommand
+	}
 
 	return &MediaEmbeddingGeneratorWorkflow{
-		BaseCommand:            *cor.NewBaseCommand("media-embedding-generator"),
-		genaiEmbedding:         serviceClients.EmbeddingModels["multi-lingual"],
-		bigqueryClient:         serviceClients.BiqQueryClient,
-		dataset:                config.BigQueryDataSource.DatasetName,
-		mediaTable:             config.BigQueryDataSource.MediaTable,
-		embeddingTable:         config.BigQueryDataSource.EmbeddingTable,
-		findEligibleMediaQuery: query,
-		ModelName:              config.EmbeddingModels["multi-lingual"].Model,
+		BaseCommand:         *cor.NewBaseCommand("media-embedding-generator"),
+		genaiEmbedding:      serviceClients.EmbeddingModels["multi-lingual"],
+		bigqueryClient:      serviceClients.BiqQueryClient,
+		index:               serviceClients.VectorIndex,
+		mediaTable:          fqMediaTableName,
+		ModelName:           config.EmbeddingModels["multi-lingual"].Model,
+		multimodalEmbedding: serviceClients.MultimodalEmbeddingModel,
+		MultimodalModelName: config.MultimodalEmbedding.Model,
+		multimodalIndex:     serviceClients.MultimodalIndex,
+		blobStore:           serviceClients.BlobStore,
+		ffmpegCommand:       ffmpegCommand,
 	}
 }
 
@@ -97,7 +118,31 @@ func (m *MediaEmbeddingGeneratorWorkflow) IsExecutable(_ cor.Context) bool {
 }
 
 func (m *MediaEmbeddingGeneratorWorkflow) Execute(context cor.Context) {
-	q := m.bigqueryClient.Query(m.findEligibleMediaQuery)
+	m.embed(context, m.index, m.embedScripts, m.hooks)
+	if m.multimodalEmbedding != nil {
+		m.embed(context, m.multimodalIndex, m.embedKeyframes, nil)
+	}
+}
+
+// embed upserts the scene embeddings of the media not yet in the index, calling the hooks with
+// the embeddings of each media upserted. A media failing to embed or a failed hook doesn't stop
+// the embedding of other media.
+func (m *MediaEmbeddingGeneratorWorkflow) embed(context cor.Context, index cloud.VectorIndex, embedScenes func(goctx.Context, *model.Media) ([]*model.SceneEmbedding, error), hooks []EmbeddingHook) {
+	// Media already in the index are not eligible, nor are media without scenes as they never
+	// get into the index and would be selected again by every pass
+	unindexed, params, err := index.Unindexed(context.GetContext(), "id")
+	if err != nil {
+		context.AddError(m.GetName(), err)
+		return
+	}
+	q, err := cloud.Select("*").From(m.mediaTable, "").
+		Where(unindexed, params...).
+		Where("ARRAY_LENGTH(scenes) > 0").
+		Query(m.bigqueryClient)
+	if err != nil {
+		context.AddError(m.GetName(), err)
+		return
+	}
 	it, err := q.Read(context.GetContext())
 	if err != nil {
 		context.AddError(m.GetName(), err)
@@ -115,31 +160,76 @@ func (m *MediaEmbeddingGeneratorWorkflow) Execute(context cor.Context) {
 			return
 		}
 
-		toInsert := make([]*model.SceneEmbedding, 0)
+		// A media failing to embed is left unindexed and retried by the next pass
+		toInsert, err := embedScenes(context.GetContext(), &value)
+		if err != nil {
+			context.AppendError(m.GetName(), fmt.Errorf("failed to embed media %s: %w", value.Id, err))
+			continue
+		}
 
-		for _, scene := range value.Scenes {
-			in := model.NewSceneEmbedding(value.Id, scene.SequenceNumber, m.ModelName)
-			contents := []*genai.Content{
-				genai.NewContentFromText(scene.Script, genai.RoleUser),
-			}
+		if err := index.Upsert(context.GetContext(), toInsert); err != nil {
+			context.AppendError(m.GetName(), fmt.Errorf("failed to index media %s: %w", value.Id, err))
+			continue
+		}
 
-			resp, err := m.genaiEmbedding.EmbedContent(context.GetContext(), m.ModelName, contents, nil)
-			if err != nil {
-				context.AddError(m.GetName(), err)
-				return
+		for _, hook := range hooks {
+			if err := hook(context.GetContext(), &value, toInsert); err != nil {
+				context.AppendError(m.GetName(), err)
 			}
-			for _, f := range resp.Embeddings {
-				for _, g := range f.Values {
-					in.Embeddings = append(in.Embeddings, float64(g))
-				}
+		}
+	}
+}
+
+// embedScripts embeds the scripts of the scenes with the text embedding model.
+func (m *MediaEmbeddingGeneratorWorkflow) embedScripts(ctx goctx.Context, media *model.Media) ([]*model.SceneEmbedding, error) {
+	toInsert := make([]*model.SceneEmbedding, 0)
+	for _, scene := range media.Scenes {
+		in := model.NewSceneEmbedding(media.Id, scene.SequenceNumber, m.ModelName)
+		contents := []*genai.Content{
+			genai.NewContentFromText(scene.Script, genai.RoleUser),
+		}
+
+		resp, err := m.genaiEmbedding.EmbedContent(ctx, m.ModelName, contents, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range resp.Embeddings {
+			for _, g := range f.Values {
+				in.Embeddings = append(in.Embeddings, float64(g))
 			}
-			toInsert = append(toInsert, in)
 		}
+		toInsert = append(toInsert, in)
+	}
+	return toInsert, nil
+}
 
-		inserter := m.bigqueryClient.Dataset(m.dataset).Table(m.embeddingTable).Inserter()
-		if err := inserter.Put(context.GetContext(), toInsert); err != nil {
-			context.AddError(m.GetName(), err)
-			return
+// embedKeyframes embeds a keyframe from the middle of each scene with the multimodal embedding model.
+func (m *MediaEmbeddingGeneratorWorkflow) embedKeyframes(ctx goctx.Context, media *model.Media) ([]*model.SceneEmbedding, error) {
+	bucket, name, err := commands.ParseMediaUrl(media.MediaUrl)
+	if err != nil {
+		return nil, err
+	}
+	fileName, cleanup, err := cloud.CopyToLocalFile(ctx, m.blobStore, bucket, name)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	toInsert := make([]*model.SceneEmbedding, 0)
+	for _, scene := range media.Scenes {
+		offset, err := commands.KeyframeOffset(scene)
+		if err != nil {
+			return nil, err
+		}
+		keyframe, err := commands.ExtractKeyframe(ctx, m.ffmpegCommand, fileName, offset)
+		if err != nil {
+			return nil, err
+		}
+		in := model.NewSceneEmbedding(media.Id, scene.SequenceNumber, m.MultimodalModelName)
+		if in.Embeddings, err = m.multimodalEmbedding.EmbedImage(ctx, keyframe); err != nil {
+			return nil, err
 		}
+		toInsert = append(toInsert, in)
 	}
+	return toInsert, nil
 }
@@ -19,8 +19,9 @@
 package workflow
 
 import (
+	"log"
+
 	"cloud.google.com/go/bigquery"
-	"cloud.google.com/go/storage"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
@@ -32,16 +33,29 @@ type MediaReaderWorkflow struct {
 	config          *cloud.Config
 	bigqueryClient  *bigquery.Client
 	genaiClient     *genai.Client
-	genaiModel      *cloud.QuotaAwareGenerativeAIModel
-	storageClient   *storage.Client
+	genaiModel      cloud.GenerativeModel
+	blobStore       cloud.BlobStore
 	numberOfWorkers int
 	templateService *cloud.TemplateService
+	checkpointStore cor.CheckpointStore
 	chain           cor.Chain
 	ffprobeCommand  string
 }
 
 func (m *MediaReaderWorkflow) Execute(context cor.Context) {
 	m.chain.Execute(context)
+
+	// Checkpoints are only needed to resume a failed ingestion
+	if m.checkpointStore == nil || context.HasErrors() {
+		return
+	}
+	key, err := commands.CheckpointKey(context)
+	if err != nil {
+		return
+	}
+	if err = m.checkpointStore.Clear(context.GetContext(), key); err != nil {
+		log.Printf("failed to clear checkpoints for %s: %v", key, err)
+	}
 }
 
 func (m *MediaReaderWorkflow) initializeChain() {
@@ -56,14 +70,14 @@ func (m *MediaReaderWorkflow) initializeChain() {
 	// Convert the Message to an Object
 	out.AddCommand(commands.NewMediaTriggerToGCSObject("media-trigger-to-gcs-object"))
 
-	// Get media length
-	out.AddCommand(commands.NewMediaLengthCommand("get-media-length", m.ffprobeCommand, MediaLengthOutputParamName, m.config))
-
-	// Determine the media content type
-	out.AddCommand(commands.NewMediaContentTypeCommand("get-media-content-type", m.config, m.genaiModel, m.templateService, ContentTypeOutputParamName))
+	// Get the media length and determine the media content type at the same time
+	mediaDetails := cor.NewParallelChain("get-media-details")
+	mediaDetails.AddCommand(withCheckpoint[int](m.checkpointStore, withRetry(m.config, commands.NewMediaLengthCommand("get-media-length", m.ffprobeCommand, MediaLengthOutputParamName, m.config, m.blobStore))))
+	mediaDetails.AddCommand(withCheckpoint[string](m.checkpointStore, withRetry(m.config, commands.NewMediaContentTypeCommand("get-media-content-type", m.config, m.genaiModel, m.templateService, ContentTypeOutputParamName))))
+	out.AddCommand(mediaDetails)
 
 	// Generate Summary
-	out.AddCommand(commands.NewMediaSummaryCreator("generate-media-summary", m.config, m.genaiModel, m.templateService, MediaLengthOutputParamName, ContentTypeOutputParamName))
+	out.AddCommand(withCheckpoint[string](m.checkpointStore, withRetry(m.config, commands.NewMediaSummaryCreator("generate-media-summary", m.config, m.genaiModel, m.templateService, MediaLengthOutputParamName, ContentTypeOutputParamName))))
 
 	// Convert the JSON to a struct and save to the summaryOutputParam
 	out.AddCommand(commands.NewMediaSummaryJsonToStruct("convert-media-summary", SummaryOutputParamName))
@@ -71,18 +85,28 @@ func (m *MediaReaderWorkflow) initializeChain() {
 	// Create the scene extraction command
 	sceneExtractor := commands.NewSceneExtractor("extract-media-scenes", m.genaiModel, m.templateService, m.numberOfWorkers, ContentTypeOutputParamName)
 	sceneExtractor.BaseCommand.OutputParamName = SceneOutputParamName
-	out.AddCommand(sceneExtractor)
+	out.AddCommand(withCheckpoint[[]string](m.checkpointStore, withRetry(m.config, sceneExtractor)))
 
 	// Assemble the output into a single media object
 	out.AddCommand(commands.NewMediaAssembly("assemble-media-scenes", SummaryOutputParamName, SceneOutputParamName, MediaOutputParamName, MediaLengthOutputParamName))
 
 	// Save media object to big query for async embedding job
-	out.AddCommand(commands.NewMediaPersistToBigQuery(
+	out.AddCommand(withRetry(m.config, commands.NewMediaPersistToBigQuery(
 		"write-to-bigquery",
 		m.bigqueryClient,
 		m.config.BigQueryDataSource.DatasetName,
-		m.config.BigQueryDataSource.MediaTable, MediaOutputParamName))
+		m.config.BigQueryDataSource.MediaTable, MediaOutputParamName)))
+
+	// Register the actors of the cast in the actor registry
+	if m.config.BigQueryDataSource.ActorTable != "" {
+		out.AddCommand(withRetry(m.config, commands.NewActorRegistry(
+			"register-actors",
+			m.bigqueryClient,
+			m.config.BigQueryDataSource.DatasetName,
+			m.config.BigQueryDataSource.ActorTable, MediaOutputParamName)))
+	}
 
+	validateChain(out)
 	m.chain = out
 }
 
@@ -99,7 +123,8 @@ func NewMediaReaderPipeline(
 		bigqueryClient:  serviceClients.BiqQueryClient,
 		genaiClient:     serviceClients.GenAIClient,
 		genaiModel:      serviceClients.AgentModels[agentModelName],
-		storageClient:   serviceClients.StorageClient,
+		blobStore:       serviceClients.BlobStore,
+		checkpointStore: serviceClients.CheckpointStore,
 		numberOfWorkers: config.Application.ThreadPoolSize,
 		templateService: templateService,
 		ffprobeCommand:  ffprobeCommand,
@@ -20,7 +20,6 @@ package workflow
 import (
 	"strings"
 
-	"cloud.google.com/go/storage"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
@@ -37,7 +36,7 @@ type MediaResizeWorkflow struct {
 	cor.BaseCommand
 	ffmpegCommand    string
 	videoFormat      *model.MediaFormatFilter
-	storageClient    *storage.Client
+	blobStore        cloud.BlobStore
 	outputBucketName string
 	chain            cor.Chain
 	config           *cloud.Config
@@ -54,8 +53,9 @@ func (m *MediaResizeWorkflow) initializeChain() {
 	out.AddCommand(commands.NewMediaTriggerToGCSObject("gcs-topic-listener"))
 
 	// Run FFMpeg
-	out.AddCommand(commands.NewFFMpegCommand("video-resize", m.ffmpegCommand, m.videoFormat.Width, m.config))
+	out.AddCommand(withRetry(m.config, commands.NewFFMpegCommand("video-resize", m.ffmpegCommand, m.videoFormat.Width, m.config, m.blobStore)))
 
+	validateChain(out)
 	m.chain = out
 }
 
@@ -79,7 +79,7 @@ func NewMediaResizeWorkflow(
 		BaseCommand:      *cor.NewBaseCommand("media-resize-workflow"),
 		ffmpegCommand:    ffmpegCommand,
 		videoFormat:      videoFormat,
-		storageClient:    serviceClients.StorageClient,
+		blobStore:        serviceClients.BlobStore,
 		config:           config,
 		outputBucketName: config.Storage.LowResOutputBucket}
 	out.initializeChain()
@@ -0,0 +1,32 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package workflow
+
+import (
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+)
+
+// withRetry wraps the command with the retry policy configured for its name,
+// commands without a configured policy are returned unchanged.
+func withRetry(config *cloud.Config, command cor.Command) cor.Command {
+	policy, ok := config.RetryPolicies[command.GetName()]
+	if !ok {
+		return command
+	}
+	return cor.NewRetryCommand(command, policy.ToRetryPolicy())
+}
@@ -0,0 +1,35 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package workflow
+
+import (
+	"log"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+)
+
+// validateChain panics when the wiring of a workflow chain is invalid, this ensures
+// a misconfigured workflow fails at startup rather than skipping commands at runtime.
+func validateChain(chain cor.Chain) {
+	report := chain.Validate()
+	for _, deadOutput := range report.DeadOutputs {
+		log.Printf("chain %s: %s", report.Chain, deadOutput)
+	}
+	if err := report.Err(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeBlob(t *testing.T, store cloud.BlobStore, bucket string, name string, content string) {
+	writer, err := store.Create(context.Background(), bucket, name, "video/mp4")
+	assert.Nil(t, err)
+	_, err = io.WriteString(writer, content)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	ctx := context.Background()
+	store := cloud.NewLocalBlobStore(t.TempDir())
+
+	_, err := store.Stat(ctx, "media", "trailer.mp4")
+	assert.ErrorIs(t, err, cloud.ErrBlobNotFound)
+
+	writeBlob(t, store, "media", "trailers/trailer.mp4", "video")
+	writeBlob(t, store, "media", "news.mp4", "news")
+
+	attrs, err := store.Stat(ctx, "media", "trailers/trailer.mp4")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), attrs.Size)
+	assert.Equal(t, "video/mp4", attrs.ContentType)
+
+	reader, err := store.Open(ctx, "media", "trailers/trailer.mp4")
+	assert.Nil(t, err)
+	content, err := io.ReadAll(reader)
+	assert.Nil(t, err)
+	assert.Nil(t, reader.Close())
+	assert.Equal(t, "video", string(content))
+
+	listed, err := store.List(ctx, "media", "trailers/")
+	assert.Nil(t, err)
+	assert.Len(t, listed, 1)
+	assert.Equal(t, "trailers/trailer.mp4", listed[0].Name)
+
+	url, err := store.SignedURL(ctx, "media", "news.mp4", "GET", 0)
+	assert.Nil(t, err)
+	assert.Contains(t, url, "file://")
+
+	assert.Nil(t, store.Delete(ctx, "media", "news.mp4"))
+	assert.ErrorIs(t, store.Delete(ctx, "media", "news.mp4"), cloud.ErrBlobNotFound)
+}
+
+func TestLocalBlobStoreRejectsTraversal(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := cloud.NewLocalBlobStore(filepath.Join(dir, "root"))
+	writeBlob(t, store, "media", "trailer.mp4", "video")
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0o644))
+
+	// Names staying in the bucket are accepted
+	path, err := store.LocalPath("media", "trailers/../trailer.mp4")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "root", "media", "trailer.mp4"), path)
+
+	for _, blob := range [][2]string{
+		{"media", "../../secret"},
+		{"media", "../other/trailer.mp4"},
+		{"media", "trailers/../../../secret"},
+		{"..", "secret"},
+		{"", "media/trailer.mp4"},
+	} {
+		_, err = store.LocalPath(blob[0], blob[1])
+		assert.ErrorIs(t, err, cloud.ErrInvalidBlobName, blob)
+		_, err = store.Open(ctx, blob[0], blob[1])
+		assert.ErrorIs(t, err, cloud.ErrInvalidBlobName, blob)
+		_, err = store.Create(ctx, blob[0], blob[1], "video/mp4")
+		assert.ErrorIs(t, err, cloud.ErrInvalidBlobName, blob)
+		assert.ErrorIs(t, store.Delete(ctx, blob[0], blob[1]), cloud.ErrInvalidBlobName, blob)
+	}
+	_, err = store.List(ctx, "..", "")
+	assert.ErrorIs(t, err, cloud.ErrInvalidBlobName)
+	_, err = os.Stat(filepath.Join(dir, "secret"))
+	assert.Nil(t, err)
+}
+
+func TestCopyLocalFile(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := cloud.NewLocalBlobStore(dir)
+	writeBlob(t, store, "media", "trailer.mp4", "video")
+
+	// Local stores return the blob path, so nothing is copied
+	path, cleanup, err := cloud.CopyToLocalFile(ctx, store, "media", "trailer.mp4")
+	assert.Nil(t, err)
+	cleanup()
+	localPath, err := store.LocalPath("media", "trailer.mp4")
+	assert.Nil(t, err)
+	assert.Equal(t, localPath, path)
+
+	source, err := os.CreateTemp(t.TempDir(), "resized")
+	assert.Nil(t, err)
+	_, err = source.WriteString("resized")
+	assert.Nil(t, err)
+	assert.Nil(t, source.Close())
+
+	assert.Nil(t, cloud.CopyFromLocalFile(ctx, store, source.Name(), "low-res", "trailer.mp4", "video/mp4"))
+	content, err := os.ReadFile(filepath.Join(dir, "low-res", "trailer.mp4"))
+	assert.Nil(t, err)
+	assert.Equal(t, "resized", string(content))
+}
+
+// failingReader returns its content then fails, like an interrupted upload.
+type failingReader struct {
+	content string
+	read    bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.read {
+		return 0, errors.New("connection reset")
+	}
+	r.read = true
+	return copy(p, r.content), nil
+}
+
+func TestWriteBlobAbortsOnError(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := cloud.NewLocalBlobStore(dir)
+	writeBlob(t, store, "media", "trailer.mp4", "video")
+
+	err := cloud.WriteBlob(ctx, store, &failingReader{content: "trunc"}, "media", "trailer.mp4", "video/mp4")
+	assert.NotNil(t, err)
+	err = cloud.WriteBlob(ctx, store, &failingReader{content: "trunc"}, "media", "news.mp4", "video/mp4")
+	assert.NotNil(t, err)
+
+	// The existing blob is untouched, no partial blob or temp file is left
+	content, err := os.ReadFile(filepath.Join(dir, "media", "trailer.mp4"))
+	assert.Nil(t, err)
+	assert.Equal(t, "video", string(content))
+	_, err = store.Stat(ctx, "media", "news.mp4")
+	assert.ErrorIs(t, err, cloud.ErrBlobNotFound)
+	entries, err := os.ReadDir(filepath.Join(dir, "media"))
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+}
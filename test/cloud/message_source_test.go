@@ -0,0 +1,97 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingCommand records the notifications it receives.
+type recordingCommand struct {
+	cor.BaseCommand
+	lock          sync.Mutex
+	notifications []*cloud.GCSPubSubNotification
+}
+
+func (c *recordingCommand) Execute(context cor.Context) {
+	notification := &cloud.GCSPubSubNotification{}
+	if err := json.Unmarshal([]byte(context.Get(cor.CtxIn).(string)), notification); err != nil {
+		context.AddError(c.GetName(), err)
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.notifications = append(c.notifications, notification)
+}
+
+func (c *recordingCommand) received() []*cloud.GCSPubSubNotification {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]*cloud.GCSPubSubNotification{}, c.notifications...)
+}
+
+func TestDirectoryMessageSource(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "media")
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "trailers"), 0o755))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "trailers", "trailer.mp4"), []byte("video"), 0o644))
+
+	command := &recordingCommand{BaseCommand: *cor.NewBaseCommand("record")}
+	source := cloud.NewDirectoryMessageSource(dir, "", time.Second)
+	source.SetCommand(command)
+
+	// A file is sent once it is unchanged between scans
+	assert.Nil(t, source.Scan(ctx))
+	assert.Empty(t, command.received())
+	assert.Nil(t, source.Scan(ctx))
+	assert.Nil(t, source.Scan(ctx))
+
+	received := command.received()
+	assert.Len(t, received, 1)
+	assert.Equal(t, "storage#object", received[0].Kind)
+	assert.Equal(t, "media", received[0].Bucket)
+	assert.Equal(t, "trailers/trailer.mp4", received[0].Name)
+	assert.Equal(t, "video/mp4", received[0].ContentType)
+	assert.Equal(t, "5", received[0].Size)
+	assert.NotEmpty(t, received[0].Generation)
+}
+
+func TestChannelMessageSource(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	command := &recordingCommand{BaseCommand: *cor.NewBaseCommand("record")}
+	source := cloud.NewChannelMessageSource(1)
+	source.SetCommand(command)
+	source.Listen(ctx)
+
+	assert.Nil(t, source.Send(ctx, &cloud.GCSPubSubNotification{Kind: "storage#object", Bucket: "media", Name: "trailer.mp4"}))
+	assert.Eventually(t, func() bool {
+		return len(command.received()) == 1
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, "trailer.mp4", command.received()[0].Name)
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	received := make(chan *model.SavedSearchAlert, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		alert := &model.SavedSearchAlert{}
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(alert))
+		received <- alert
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier, err := cloud.NewNotifier(cloud.SavedSearchConfig{Notifier: "webhook", WebhookUrl: server.URL})
+	assert.Nil(t, err)
+	err = notifier.Notify(context.Background(), &model.SavedSearchAlert{
+		SavedSearchId: "s1",
+		Query:         "helicopters",
+		MediaId:       "m1",
+		Matches:       []*model.SceneMatchResult{{MediaId: "m1", SequenceNumber: 2, Similarity: 0.9}},
+	})
+	assert.Nil(t, err)
+	alert := <-received
+	assert.Equal(t, "s1", alert.SavedSearchId)
+	assert.Equal(t, 2, alert.Matches[0].SequenceNumber)
+}
+
+func TestWebhookNotifierFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	notifier := cloud.NewWebhookNotifier(server.URL, 0)
+	assert.NotNil(t, notifier.Notify(context.Background(), &model.SavedSearchAlert{SavedSearchId: "s1"}))
+}
+
+func TestNewNotifier(t *testing.T) {
+	notifier, err := cloud.NewNotifier(cloud.SavedSearchConfig{})
+	assert.Nil(t, err)
+	assert.IsType(t, cloud.LogNotifier{}, notifier)
+	assert.Nil(t, notifier.Notify(context.Background(), &model.SavedSearchAlert{SavedSearchId: "s1"}))
+
+	_, err = cloud.NewNotifier(cloud.SavedSearchConfig{Notifier: "webhook"})
+	assert.NotNil(t, err)
+	_, err = cloud.NewNotifier(cloud.SavedSearchConfig{Notifier: "pager"})
+	assert.NotNil(t, err)
+}
@@ -55,7 +55,7 @@ func TestMessageHandler(t *testing.T) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 
-	pubsubListener := cloudClients.PubSubListeners["HiResTopic"]
+	pubsubListener := cloudClients.MessageSources["HiResTopic"]
 	pubsubListener.SetCommand(&MediaMessageCommand{})
 
 	assert.NotNil(t, pubsubListener)
@@ -0,0 +1,69 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud_test
+
+import (
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQueryBuilder(t *testing.T) {
+	id := "x' OR '1'='1"
+	sql, params, err := cloud.Select("s.sequence", "s.script").
+		From("project.media_ds.media", "m").Unnest("m.scenes", "s").
+		Where("m.id = @id", bigquery.QueryParameter{Name: "id", Value: id}).
+		Where("").
+		Where("m.genre = @genre OR m.genre IS NULL", bigquery.QueryParameter{Name: "genre", Value: "action"}).
+		OrderBy("s.sequence").
+		Limit(10).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT s.sequence, s.script FROM `project.media_ds.media` m, UNNEST(m.scenes) s "+
+		"WHERE (m.id = @id) AND (m.genre = @genre OR m.genre IS NULL) ORDER BY s.sequence LIMIT @limit", sql)
+	assert.Equal(t, []bigquery.QueryParameter{
+		{Name: "id", Value: id},
+		{Name: "genre", Value: "action"},
+		{Name: "limit", Value: 10},
+	}, params)
+}
+
+func TestQueryBuilderSingleConditionWithoutLimit(t *testing.T) {
+	sql, params, err := cloud.Select("*").From("project.media_ds.media", "").
+		Where("id = @id", bigquery.QueryParameter{Name: "id", Value: "1"}).
+		Limit(0).
+		Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT * FROM `project.media_ds.media` WHERE id = @id", sql)
+	assert.Len(t, params, 1)
+}
+
+func TestQueryBuilderErrors(t *testing.T) {
+	_, _, err := cloud.Select("*").From("media` WHERE 1=1 --", "").Build()
+	assert.NotNil(t, err)
+
+	_, _, err = cloud.Select("*").From("media", "").
+		Where("id = @id", bigquery.QueryParameter{Name: "id", Value: "1"}).
+		Where("parent = @id", bigquery.QueryParameter{Name: "id", Value: "2"}).
+		Build()
+	assert.ErrorContains(t, err, "@id")
+
+	_, _, err = cloud.Select("*").Build()
+	assert.NotNil(t, err)
+}
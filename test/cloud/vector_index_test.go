@@ -0,0 +1,288 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cloud_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"cloud.google.com/go/bigquery"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func randomEmbeddings(r *rand.Rand, media int, scenes int, dims int) []*model.SceneEmbedding {
+	out := make([]*model.SceneEmbedding, 0)
+	for m := 0; m < media; m++ {
+		for s := 0; s < scenes; s++ {
+			e := model.NewSceneEmbedding(fmt.Sprintf("media-%d", m), s, "test")
+			for d := 0; d < dims; d++ {
+				e.Embeddings = append(e.Embeddings, r.Float64()*2-1)
+			}
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// exactMatches returns the keys of the k nearest embeddings by brute force.
+func exactMatches(metric cloud.Metric, embeddings []*model.SceneEmbedding, vector []float64, k int) []string {
+	sorted := slices.Clone(embeddings)
+	slices.SortFunc(sorted, func(a, b *model.SceneEmbedding) int {
+		da, db := metric.Distance(vector, a.Embeddings), metric.Distance(vector, b.Embeddings)
+		switch {
+		case da < db:
+			return -1
+		case da > db:
+			return 1
+		}
+		return 0
+	})
+	out := make([]string, 0, k)
+	for _, e := range sorted[:k] {
+		out = append(out, fmt.Sprintf("%s/%d", e.Id, e.SequenceNumber))
+	}
+	return out
+}
+
+func TestHNSWVectorIndexRecall(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewPCG(1, 2))
+	embeddings := randomEmbeddings(r, 50, 20, 16)
+
+	for _, metric := range []cloud.Metric{cloud.MetricEuclidean, cloud.MetricCosine, cloud.MetricDot} {
+		index, err := cloud.NewHNSWVectorIndex(metric, cloud.HNSWParams{}, "")
+		assert.Nil(t, err)
+		assert.Nil(t, index.Upsert(ctx, embeddings))
+
+		found, total := 0, 0
+		for q := 0; q < 20; q++ {
+			vector := randomEmbeddings(r, 1, 1, 16)[0].Embeddings
+			matches, err := index.Query(ctx, &cloud.VectorQuery{Vector: vector, TopK: 10})
+			assert.Nil(t, err)
+			assert.Len(t, matches, 10)
+			for i := 1; i < len(matches); i++ {
+				assert.LessOrEqual(t, matches[i-1].Distance, matches[i].Distance)
+			}
+			exact := exactMatches(metric, embeddings, vector, 10)
+			for _, m := range matches {
+				if slices.Contains(exact, fmt.Sprintf("%s/%d", m.MediaId, m.SequenceNumber)) {
+					found++
+				}
+			}
+			total += len(exact)
+		}
+		assert.GreaterOrEqual(t, float64(found)/float64(total), 0.9, "recall of %s", metric)
+	}
+}
+
+func TestHNSWVectorIndexUpsertDeleteAndFilter(t *testing.T) {
+	ctx := context.Background()
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.Nil(t, err)
+
+	embeddings := []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 0, Embeddings: []float64{0, 0}},
+		{Id: "a", SequenceNumber: 1, Embeddings: []float64{1, 0}},
+		{Id: "b", SequenceNumber: 0, Embeddings: []float64{0, 1}},
+	}
+	assert.Nil(t, index.Upsert(ctx, embeddings))
+
+	matches, err := index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0.9, 0}, TopK: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, "a", matches[0].MediaId)
+	assert.Equal(t, 1, matches[0].SequenceNumber)
+	assert.InDelta(t, 0.1, matches[0].Distance, 1e-9)
+	assert.InDelta(t, 1/1.1, matches[0].Similarity, 1e-9)
+
+	// Replacing a scene moves it
+	assert.Nil(t, index.Upsert(ctx, []*model.SceneEmbedding{{Id: "a", SequenceNumber: 1, Embeddings: []float64{5, 5}}}))
+	matches, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0.9, 0}, TopK: 3})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 3)
+	assert.Equal(t, "a", matches[0].MediaId)
+	assert.Equal(t, 0, matches[0].SequenceNumber)
+	assert.Equal(t, 1, matches[2].SequenceNumber)
+
+	// A filtered query only matches the scenes of the media
+	matches, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3, MediaIds: []string{"b"}})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "b", matches[0].MediaId)
+
+	matches, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3, MediaIds: []string{}})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 0)
+
+	matches, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3, ExcludeMediaIds: []string{"a"}})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "b", matches[0].MediaId)
+
+	stored, err := index.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.Len(t, stored, 2)
+	assert.Equal(t, 0, stored[0].SequenceNumber)
+	assert.Equal(t, []float64{5, 5}, stored[1].Embeddings)
+
+	assert.Nil(t, index.Delete(ctx, "a"))
+	stored, err = index.Get(ctx, "a")
+	assert.Nil(t, err)
+	assert.Len(t, stored, 0)
+	ids, err := index.MediaIds(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"b"}, ids)
+	matches, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 1)
+
+	_, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0, 0}, TopK: 3})
+	assert.NotNil(t, err)
+	assert.NotNil(t, index.Upsert(ctx, []*model.SceneEmbedding{{Id: "c", Embeddings: []float64{0}}}))
+}
+
+func TestHNSWVectorIndexPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index", "scenes.hnsw")
+	embeddings := randomEmbeddings(rand.New(rand.NewPCG(3, 4)), 10, 5, 8)
+
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricCosine, cloud.HNSWParams{}, path)
+	assert.Nil(t, err)
+	assert.Nil(t, index.Upsert(ctx, embeddings))
+	assert.Nil(t, index.Delete(ctx, "media-3"))
+
+	query := &cloud.VectorQuery{Vector: embeddings[0].Embeddings, TopK: 5}
+	expected, err := index.Query(ctx, query)
+	assert.Nil(t, err)
+
+	reloaded, err := cloud.NewHNSWVectorIndex(cloud.MetricCosine, cloud.HNSWParams{}, path)
+	assert.Nil(t, err)
+	actual, err := reloaded.Query(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, actual)
+	ids, err := reloaded.MediaIds(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, ids, 9)
+	assert.NotContains(t, ids, "media-3")
+
+	// Loading with another metric rebuilds the graph
+	euclidean, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, path)
+	assert.Nil(t, err)
+	matches, err := euclidean.Query(ctx, query)
+	assert.Nil(t, err)
+	assert.Equal(t, "media-0", matches[0].MediaId)
+	assert.Equal(t, 0.0, matches[0].Distance)
+}
+
+func TestParseMetric(t *testing.T) {
+	metric, err := cloud.ParseMetric("")
+	assert.Nil(t, err)
+	assert.Equal(t, cloud.MetricEuclidean, metric)
+
+	metric, err = cloud.ParseMetric("COSINE")
+	assert.Nil(t, err)
+	assert.Equal(t, cloud.MetricCosine, metric)
+
+	_, err = cloud.ParseMetric("manhattan")
+	assert.NotNil(t, err)
+
+	assert.InDelta(t, -11.0, cloud.MetricDot.Distance([]float64{1, 2}, []float64{3, 4}), 1e-9)
+	assert.InDelta(t, 0.0, cloud.MetricCosine.Distance([]float64{1, 2}, []float64{2, 4}), 1e-9)
+	assert.Equal(t, 11.0, cloud.MetricDot.Similarity(-11))
+	assert.Equal(t, 0.75, cloud.MetricCosine.Similarity(0.25))
+	assert.Equal(t, 1.0, cloud.MetricEuclidean.Similarity(0))
+}
+
+func TestHNSWVectorIndexScope(t *testing.T) {
+	ctx := context.Background()
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.Nil(t, err)
+	assert.Nil(t, index.Upsert(ctx, []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 0, Embeddings: []float64{0, 0}},
+		{Id: "b", SequenceNumber: 0, Embeddings: []float64{1, 1}},
+		{Id: "c", SequenceNumber: 0, Embeddings: []float64{2, 2}},
+	}))
+	scope := &cloud.MediaScope{Table: "p.d.media", Alias: "m", Where: "m.category = @category"}
+
+	// Scoped queries fail without a resolver
+	_, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3, Scope: scope})
+	assert.NotNil(t, err)
+
+	index.ResolveScopes(func(_ context.Context, s *cloud.MediaScope) ([]string, error) {
+		assert.Equal(t, scope, s)
+		return []string{"b", "c"}, nil
+	})
+	matches, err := index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3, Scope: scope})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "b", matches[0].MediaId)
+
+	// The scope and the media ids intersect
+	matches, err = index.Query(ctx, &cloud.VectorQuery{Vector: []float64{0, 0}, TopK: 3, Scope: scope, MediaIds: []string{"a", "c"}})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, "c", matches[0].MediaId)
+
+	where, params, err := index.Unindexed(ctx, "id")
+	assert.Nil(t, err)
+	assert.Equal(t, "id NOT IN UNNEST(@indexed)", where)
+	assert.Equal(t, []string{"a", "b", "c"}, params[0].Value)
+}
+
+func TestMediaScopeSelect(t *testing.T) {
+	scope := &cloud.MediaScope{Table: "p.d.media", Alias: "m", Where: "m.category = @category",
+		Params: []bigquery.QueryParameter{{Name: "category", Value: "trailer"}}}
+	sql, params, err := scope.Select().Build()
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT m.id AS id FROM `p.d.media` m WHERE m.category = @category", sql)
+	assert.Equal(t, scope.Params, params)
+}
+
+func TestHNSWVectorIndexDeleteMostOfIndex(t *testing.T) {
+	ctx := context.Background()
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.Nil(t, err)
+	embeddings := randomEmbeddings(rand.New(rand.NewPCG(5, 6)), 1, 3, 4)
+	assert.Nil(t, index.Upsert(ctx, embeddings))
+	// Deleting the only media rebuilds the graph part way through its nodes
+	assert.Nil(t, index.Delete(ctx, "media-0"))
+	ids, err := index.MediaIds(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, ids, 0)
+
+	// A media making up most of the index leaves the other media intact
+	embeddings = randomEmbeddings(rand.New(rand.NewPCG(7, 8)), 2, 1, 4)
+	assert.Nil(t, index.Upsert(ctx, embeddings))
+	big := randomEmbeddings(rand.New(rand.NewPCG(9, 10)), 1, 6, 4)
+	for _, e := range big {
+		e.Id = "big"
+	}
+	assert.Nil(t, index.Upsert(ctx, big))
+	assert.Nil(t, index.Delete(ctx, "big"))
+	ids, err = index.MediaIds(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"media-0", "media-1"}, ids)
+	matches, err := index.Query(ctx, &cloud.VectorQuery{Vector: embeddings[0].Embeddings, TopK: 5})
+	assert.Nil(t, err)
+	assert.Len(t, matches, 2)
+	assert.Equal(t, "media-0", matches[0].MediaId)
+}
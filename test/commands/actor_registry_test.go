@@ -0,0 +1,74 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveActors(t *testing.T) {
+	known := model.NewActor("Summer Glau")
+	known.Aliases = append(known.Aliases, "Summar Glau")
+	known.NormalizeAliases()
+	fillion := model.NewActor("Nathan Fillion")
+
+	changed := commands.ResolveActors([]string{
+		"Nathan Fillion",
+		"Summar Glau",
+		"Sean Maher",
+		"sean maher",
+		"Unidentified",
+		"Nathan Fillion (voice)",
+	}, []*model.Actor{known, fillion})
+
+	// The known spellings change nothing, the new spellings are aliases of the actor they resolve to
+	assert.Equal(t, 2, len(changed))
+	assert.Equal(t, "Sean Maher", changed[0].Name)
+	assert.Equal(t, []string{"sean maher"}, changed[0].Aliases)
+	assert.Equal(t, fillion.Id, changed[1].Id)
+	assert.Equal(t, []string{"Nathan Fillion (voice)"}, changed[1].Aliases)
+}
+
+func TestResolveActorsByAlias(t *testing.T) {
+	known := model.NewActor("Dwayne Johnson")
+	known.Aliases = append(known.Aliases, "The Rock")
+	known.NormalizeAliases()
+
+	changed := commands.ResolveActors([]string{"the rock"}, []*model.Actor{known})
+	assert.Equal(t, 1, len(changed))
+	assert.Equal(t, known.Id, changed[0].Id)
+	assert.Equal(t, []string{"The Rock", "the rock"}, changed[0].Aliases)
+}
+
+func TestResolveActorsNormalizesAliases(t *testing.T) {
+	known := model.NewActor("Dwayne Johnson")
+	known.Aliases = append(known.Aliases, "The Rock (uncredited)")
+
+	// An actor without normalized aliases is upserted with them
+	changed := commands.ResolveActors([]string{"Dwayne Johnson"}, []*model.Actor{known})
+	assert.Equal(t, 1, len(changed))
+	assert.Equal(t, []string{"dwayne johnson", "the rock"}, changed[0].NormalizedAliases)
+
+	changed = commands.ResolveActors([]string{"Dwayne Johnson", "THE ROCK"}, []*model.Actor{known})
+	assert.Equal(t, 1, len(changed))
+	assert.Equal(t, []string{"The Rock (uncredited)", "THE ROCK"}, changed[0].Aliases)
+	assert.Equal(t, []string{"dwayne johnson", "the rock"}, changed[0].NormalizedAliases)
+}
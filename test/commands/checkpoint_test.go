@@ -0,0 +1,44 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkpointKey(t *testing.T, gcsObject *cloud.GCSObject) string {
+	chainCtx := cor.NewBaseContext()
+	cloud.GCSObjectKey.Set(chainCtx, gcsObject)
+	key, err := commands.CheckpointKey(chainCtx)
+	assert.Nil(t, err)
+	return key
+}
+
+func TestCheckpointKey(t *testing.T) {
+	first := checkpointKey(t, &cloud.GCSObject{Bucket: "media", Name: "trailer.mp4", Generation: "1"})
+	assert.Equal(t, first, checkpointKey(t, &cloud.GCSObject{Bucket: "media", Name: "trailer.mp4", Generation: "1"}))
+	assert.NotEqual(t, first, checkpointKey(t, &cloud.GCSObject{Bucket: "media", Name: "trailer.mp4", Generation: "2"}))
+	assert.NotEqual(t, first, checkpointKey(t, &cloud.GCSObject{Bucket: "media", Name: "other.mp4", Generation: "1"}))
+
+	_, err := commands.CheckpointKey(cor.NewBaseContext())
+	assert.ErrorIs(t, err, cor.ErrMissingValue)
+}
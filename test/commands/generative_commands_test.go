@@ -0,0 +1,139 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genai"
+)
+
+const contentTypeParam = "__content_type_output__"
+
+// fakeModel answers each request with a canned response selected by the output schema.
+type fakeModel struct {
+	calls atomic.Int32
+}
+
+func (f *fakeModel) GenerateContent(_ context.Context, _ string, contents []*genai.Content, outputSchema *genai.Schema) (*genai.GenerateContentResponse, error) {
+	f.calls.Add(1)
+	var text string
+	switch {
+	case outputSchema == nil:
+		text = " Trailer\n"
+	case reflect.DeepEqual(outputSchema, model.NewSceneExtractorSchema()):
+		// Echo the scene time span so each scene request has its own response
+		prompt := contents[0].Parts[0].Text
+		scene := model.GetExampleScene()
+		scene.Script = prompt
+		out, _ := json.Marshal(scene)
+		text = string(out)
+	default:
+		out, _ := json.Marshal(model.GetExampleSummary())
+		text = string(out)
+	}
+	return &genai.GenerateContentResponse{
+		Candidates:    []*genai.Candidate{{Content: genai.NewContentFromText(text, genai.RoleModel)}},
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{PromptTokenCount: 10, CandidatesTokenCount: 5},
+	}, nil
+}
+
+func newGenerativeConfig() *cloud.Config {
+	config := cloud.NewConfig()
+	config.ContentType = cloud.ContentType{
+		Types:          []string{"trailer", "movie"},
+		PromptTemplate: "Select one of {{ range .CONTENT_TYPES }}{{ . }} {{ end }}",
+		DefaultType:    "movie",
+	}
+	config.Categories["trailer"] = cloud.Category{Name: "trailer", Definition: "A movie trailer"}
+	config.PromptTemplates = map[string]cloud.PromptTemplates{}
+	config.PromptTemplates["trailer"] = cloud.PromptTemplates{
+		SystemInstructions: "You are a film critic",
+		SummaryPrompt:      "Summarize the {{ .VIDEO_LENGTH }} second video like {{ .EXAMPLE_JSON }}",
+		ScenePrompt:        "Describe the scene {{ .TIME_START }} to {{ .TIME_END }}",
+	}
+	return config
+}
+
+func newGenerativeContext() cor.Context {
+	chainCtx := cor.NewConcurrentContext()
+	chainCtx.SetContext(context.Background())
+	cloud.GCSObjectKey.Set(chainCtx, &cloud.GCSObject{Bucket: "media", Name: "serenity.mp4", MIMEType: "video/mp4"})
+	chainCtx.Add(cor.CtxIn, "serenity.mp4")
+	chainCtx.Add(mediaLengthParam, 120)
+	return chainCtx
+}
+
+// runGenerativeCommands runs the content type, summary and scene commands, returning their outputs.
+func runGenerativeCommands(t *testing.T, generativeModel cloud.GenerativeModel) (string, string, []string) {
+	config := newGenerativeConfig()
+	templateService := cloud.NewTemplateService(config)
+	chainCtx := newGenerativeContext()
+
+	commands.NewMediaContentTypeCommand("get-media-content-type", config, generativeModel, templateService, contentTypeParam).Execute(chainCtx)
+	assert.False(t, chainCtx.HasErrors(), fmt.Sprint(chainCtx.GetErrors()))
+	contentType := chainCtx.Get(contentTypeParam).(string)
+
+	commands.NewMediaSummaryCreator("generate-media-summary", config, generativeModel, templateService, mediaLengthParam, contentTypeParam).Execute(chainCtx)
+	assert.False(t, chainCtx.HasErrors(), fmt.Sprint(chainCtx.GetErrors()))
+	summary := chainCtx.Get(cor.CtxOut).(string)
+
+	chainCtx.Add(summaryParam, model.GetExampleSummary())
+	sceneExtractor := commands.NewSceneExtractor("extract-media-scenes", generativeModel, templateService, 2, contentTypeParam)
+	sceneExtractor.BaseCommand.InputParamName = summaryParam
+	sceneExtractor.Execute(chainCtx)
+	assert.False(t, chainCtx.HasErrors(), fmt.Sprint(chainCtx.GetErrors()))
+	scenes := chainCtx.Get(cor.CtxOut).([]string)
+
+	return contentType, summary, scenes
+}
+
+func TestGenerativeCommandsReplay(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeModel{}
+
+	// Record the responses of the fake model
+	contentType, summary, scenes := runGenerativeCommands(t, cloud.NewReplayGenerativeModel(dir, fake))
+	assert.Equal(t, "trailer", contentType)
+	assert.Contains(t, summary, "Serenity")
+	assert.Len(t, scenes, 2)
+	assert.Equal(t, int32(4), fake.calls.Load())
+
+	// Replay them from disk without a model
+	replayedType, replayedSummary, replayedScenes := runGenerativeCommands(t, cloud.NewReplayGenerativeModel(dir, nil))
+	assert.Equal(t, contentType, replayedType)
+	assert.Equal(t, summary, replayedSummary)
+	assert.ElementsMatch(t, scenes, replayedScenes)
+	assert.Equal(t, int32(4), fake.calls.Load())
+}
+
+func TestReplayGenerativeModelNotRecorded(t *testing.T) {
+	replay := cloud.NewReplayGenerativeModel(t.TempDir(), nil)
+	_, err := replay.GenerateContent(context.Background(), "", genai.Text("hello"), nil)
+	assert.ErrorIs(t, err, cloud.ErrRecordingNotFound)
+	assert.ErrorIs(t, err, cor.ErrPermanent)
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMediaUrl(t *testing.T) {
+	bucket, name, err := commands.ParseMediaUrl(fmt.Sprintf(commands.MediaUrlFormat, "low_res", "trailers/Serenity.mp4"))
+	assert.NoError(t, err)
+	assert.Equal(t, "low_res", bucket)
+	assert.Equal(t, "trailers/Serenity.mp4", name)
+
+	_, _, err = commands.ParseMediaUrl("https://example.com/low_res/Serenity.mp4")
+	assert.Error(t, err)
+	_, _, err = commands.ParseMediaUrl(fmt.Sprintf(commands.MediaUrlFormat, "low_res", ""))
+	assert.Error(t, err)
+}
+
+func TestKeyframeOffset(t *testing.T) {
+	offset, err := commands.KeyframeOffset(&model.Scene{Start: "00:01:00", End: "00:01:10"})
+	assert.NoError(t, err)
+	assert.Equal(t, 65*time.Second, offset)
+
+	// A scene without a valid end uses its start
+	offset, err = commands.KeyframeOffset(&model.Scene{Start: "01:00:00", End: "00:00:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, offset)
+
+	_, err = commands.KeyframeOffset(&model.Scene{Start: "1:00", End: "00:01:10"})
+	assert.Error(t, err)
+}
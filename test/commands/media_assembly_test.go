@@ -0,0 +1,69 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package commands_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	summaryParam     = "__summary_output__"
+	sceneParam       = "__scene_output__"
+	mediaParam       = "__media_output__"
+	mediaLengthParam = "__media_length_output__"
+)
+
+func newAssemblyContext() cor.Context {
+	chainCtx := cor.NewBaseContext()
+	chainCtx.SetContext(context.Background())
+	chainCtx.Add(summaryParam, model.GetExampleSummary())
+	chainCtx.Add(sceneParam, []string{`{"sequence": 1, "start": "00:00:00", "end": "00:00:05", "script": "Opening"}`})
+	return chainCtx
+}
+
+func TestMediaAssembly(t *testing.T) {
+	chainCtx := newAssemblyContext()
+	chainCtx.Add(mediaLengthParam, 10)
+
+	assembly := commands.NewMediaAssembly("assemble-media-scenes", summaryParam, sceneParam, mediaParam, mediaLengthParam)
+	assert.True(t, assembly.IsExecutable(chainCtx))
+	assembly.Execute(chainCtx)
+
+	assert.False(t, chainCtx.HasErrors())
+	media := chainCtx.Get(mediaParam).(*model.Media)
+	assert.Equal(t, "Serenity", media.Title)
+	assert.Equal(t, 1, len(media.Scenes))
+}
+
+func TestMediaAssemblyWiringError(t *testing.T) {
+	chainCtx := newAssemblyContext()
+	// The media length is misspelled, so the command must report it rather than panic
+	chainCtx.Add("__media_lenght_output__", 10)
+
+	assembly := commands.NewMediaAssembly("assemble-media-scenes", summaryParam, sceneParam, mediaParam, mediaLengthParam)
+	assert.NotPanics(t, func() { assembly.Execute(chainCtx) })
+
+	assert.True(t, chainCtx.HasErrors())
+	assert.ErrorIs(t, chainCtx.GetErrors()["assemble-media-scenes"], cor.ErrMissingValue)
+	assert.Nil(t, chainCtx.Get(mediaParam))
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+func checkpointKey(cor.Context) (string, error) {
+	return "media-1", nil
+}
+
+func TestCheckpointCommand(t *testing.T) {
+	store := cor.NewFileCheckpointStore(t.TempDir())
+	command := newFlakyCommand(0, nil)
+
+	chainCtx := newChainContext()
+	cor.NewCheckpointCommand[string](command, store, checkpointKey).Execute(chainCtx)
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, "done", chainCtx.Get(cor.CtxOut))
+
+	// The second execution is restored from the checkpoint
+	chainCtx = newChainContext()
+	cor.NewCheckpointCommand[string](command, store, checkpointKey).Execute(chainCtx)
+	assert.Equal(t, 1, command.calls)
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, "done", chainCtx.Get(cor.CtxOut))
+
+	assert.Nil(t, store.Clear(context.Background(), "media-1"))
+	_, ok, err := store.Load(context.Background(), "media-1", "flaky")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestCheckpointCommandFailure(t *testing.T) {
+	store := cor.NewFileCheckpointStore(t.TempDir())
+	command := newFlakyCommand(1, errors.New("unavailable"))
+
+	chainCtx := newChainContext()
+	cor.NewCheckpointCommand[string](command, store, checkpointKey).Execute(chainCtx)
+	assert.True(t, chainCtx.HasErrors())
+
+	// A failed execution is not checkpointed, so the command is executed again
+	chainCtx = newChainContext()
+	cor.NewCheckpointCommand[string](command, store, checkpointKey).Execute(chainCtx)
+	assert.Equal(t, 2, command.calls)
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, "done", chainCtx.Get(cor.CtxOut))
+}
+
+func TestCheckpointCommandResume(t *testing.T) {
+	store := cor.NewFileCheckpointStore(t.TempDir())
+	first := newFlakyCommand(0, nil)
+	second := newFlakyCommand(1, errors.New("unavailable"))
+	second.Name = "second"
+
+	newChain := func() cor.Chain {
+		chain := cor.NewBaseChain("resume")
+		chain.AddCommand(cor.NewCheckpointCommand[string](first, store, checkpointKey))
+		chain.AddCommand(cor.NewCheckpointCommand[string](second, store, checkpointKey))
+		return chain
+	}
+
+	chainCtx := newChainContext()
+	newChain().Execute(chainCtx)
+	assert.True(t, chainCtx.HasErrors())
+
+	// The retry resumes from the failed command
+	chainCtx = newChainContext()
+	newChain().Execute(chainCtx)
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, 1, first.calls)
+	assert.Equal(t, 2, second.calls)
+}
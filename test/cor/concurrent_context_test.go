@@ -0,0 +1,178 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+const workers = 50
+
+func TestConcurrentContext(t *testing.T) {
+	chainCtx := cor.NewConcurrentContext()
+	chainCtx.SetContext(context.Background())
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i)
+			chainCtx.Add(key, i)
+			assert.Equal(t, i, chainCtx.Get(key))
+			chainCtx.AddTempFile(key)
+			chainCtx.AppendError("shared", fmt.Errorf("error %d", i))
+			_ = chainCtx.GetErrors()
+			_ = chainCtx.GetTempFiles()
+			_ = chainCtx.HasErrors()
+			_ = chainCtx.GetContext()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, workers, len(chainCtx.GetTempFiles()))
+	assert.Equal(t, 1, len(chainCtx.GetErrors()))
+	for i := 0; i < workers; i++ {
+		assert.ErrorContains(t, chainCtx.GetErrors()["shared"], fmt.Sprintf("error %d", i))
+	}
+}
+
+func TestConcurrentContextGetOrAdd(t *testing.T) {
+	chainCtx := cor.NewConcurrentContext()
+
+	var added atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			actual, loaded := chainCtx.GetOrAdd("once", i)
+			if !loaded {
+				added.Add(1)
+				assert.Equal(t, i, actual)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), added.Load())
+	assert.NotNil(t, chainCtx.Get("once"))
+}
+
+func TestBaseContextAppendError(t *testing.T) {
+	chainCtx := cor.NewBaseContext()
+	first := errors.New("first")
+	chainCtx.AppendError("key", first)
+	assert.Equal(t, first, chainCtx.GetErrors()["key"])
+
+	second := errors.New("second")
+	chainCtx.AppendError("key", second)
+	assert.ErrorIs(t, chainCtx.GetErrors()["key"], first)
+	assert.ErrorIs(t, chainCtx.GetErrors()["key"], second)
+}
+
+// fanOutCommand writes from several goroutines into the context it is given.
+type fanOutCommand struct {
+	cor.BaseCommand
+	fail bool
+}
+
+func newFanOutCommand(name string, fail bool) *fanOutCommand {
+	out := &fanOutCommand{BaseCommand: *cor.NewBaseCommand(name), fail: fail}
+	out.OutputParamName = fmt.Sprintf("__%s__", name)
+	return out
+}
+
+func (c *fanOutCommand) Execute(context cor.Context) {
+	var count atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counter, _ := context.GetOrAdd(c.GetOutputParam(), &count)
+			counter.(*atomic.Int32).Add(1)
+			context.AddTempFile(fmt.Sprintf("%s-%d", c.GetName(), i))
+			if c.fail {
+				context.AppendError(c.GetName(), fmt.Errorf("worker %d failed", i))
+			}
+			_ = context.Get(cor.CtxIn)
+		}()
+	}
+	wg.Wait()
+	context.Add(cor.CtxOut, c.GetName())
+}
+
+func TestParallelChainWithConcurrentContext(t *testing.T) {
+	chain := cor.NewParallelChain("parallel")
+	for i := 0; i < 5; i++ {
+		chain.AddCommand(newFanOutCommand(fmt.Sprintf("command-%d", i), i == 2))
+	}
+
+	chainCtx := cor.NewConcurrentContext()
+	chainCtx.SetContext(context.Background())
+	chainCtx.Add(cor.CtxIn, "input")
+	chain.Execute(chainCtx)
+
+	for i := 0; i < 5; i++ {
+		counter := chainCtx.Get(fmt.Sprintf("__command-%d__", i)).(*atomic.Int32)
+		assert.Equal(t, int32(workers), counter.Load())
+	}
+	assert.Equal(t, 5*workers, len(chainCtx.GetTempFiles()))
+	assert.Equal(t, 1, len(chainCtx.GetErrors()))
+	assert.ErrorContains(t, chainCtx.GetErrors()["command-2"], "worker 0 failed")
+	assert.Equal(t, "command-4", chainCtx.Get(cor.CtxOut))
+}
+
+func TestNestedParallelChains(t *testing.T) {
+	inner := cor.NewParallelChain("inner")
+	inner.AddCommand(newFanOutCommand("inner-a", false)).AddCommand(newFanOutCommand("inner-b", false))
+
+	outer := cor.NewParallelChain("outer")
+	outer.AddCommand(inner).AddCommand(newFanOutCommand("outer-a", false))
+
+	chain := cor.NewBaseChain("serial")
+	chain.AddCommand(outer)
+
+	chainCtx := cor.NewConcurrentContext()
+	chainCtx.SetContext(context.Background())
+	chainCtx.Add(cor.CtxIn, "input")
+	chain.Execute(chainCtx)
+
+	assert.False(t, chainCtx.HasErrors())
+	assert.NotNil(t, chainCtx.Get("__inner-a__"))
+	assert.NotNil(t, chainCtx.Get("__inner-b__"))
+	assert.NotNil(t, chainCtx.Get("__outer-a__"))
+	assert.Equal(t, "outer-a", chainCtx.Get(cor.CtxIn))
+}
+
+func TestJoinErrors(t *testing.T) {
+	assert.Nil(t, cor.JoinErrors(map[string]error{}))
+
+	unavailable := errors.New("unavailable")
+	err := cor.JoinErrors(map[string]error{"persist": unavailable, "extract": errors.New("bad json")})
+	assert.ErrorIs(t, err, unavailable)
+	assert.Equal(t, "extract: bad json\npersist: unavailable", err.Error())
+}
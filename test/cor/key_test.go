@@ -0,0 +1,67 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyGet(t *testing.T) {
+	chainCtx := cor.NewBaseContext()
+	lengthKey := cor.NewKey[int]("__media_length_output__")
+	lengthKey.Set(chainCtx, 120)
+
+	value, err := lengthKey.Get(chainCtx)
+	assert.Nil(t, err)
+	assert.Equal(t, 120, value)
+
+	value, ok := lengthKey.Lookup(chainCtx)
+	assert.True(t, ok)
+	assert.Equal(t, 120, value)
+}
+
+func TestKeyMissingValue(t *testing.T) {
+	chainCtx := cor.NewBaseContext()
+	summaryKey := cor.NewKey[string]("__summary_ouptut__")
+
+	_, err := summaryKey.Get(chainCtx)
+	assert.ErrorIs(t, err, cor.ErrMissingValue)
+
+	_, ok := summaryKey.Lookup(chainCtx)
+	assert.False(t, ok)
+
+	_, ok = summaryKey.MustGet(chainCtx, "convert-media-summary")
+	assert.False(t, ok)
+	assert.ErrorIs(t, chainCtx.GetErrors()["convert-media-summary"], cor.ErrMissingValue)
+}
+
+func TestKeyWrongType(t *testing.T) {
+	chainCtx := cor.NewBaseContext()
+	chainCtx.Add("__media_length_output__", "120")
+	lengthKey := cor.NewKey[int]("__media_length_output__")
+
+	_, err := lengthKey.Get(chainCtx)
+	assert.ErrorIs(t, err, cor.ErrWrongType)
+	assert.ErrorContains(t, err, "string")
+
+	_, ok := lengthKey.MustGet(chainCtx, "assemble-media-scenes")
+	assert.False(t, ok)
+	assert.ErrorIs(t, chainCtx.GetErrors()["assemble-media-scenes"], cor.ErrWrongType)
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+// barrierCommand writes its value to the output param once every command
+// sharing the barrier has started, proving the commands run at the same time.
+type barrierCommand struct {
+	cor.BaseCommand
+	value   string
+	err     error
+	started *sync.WaitGroup
+}
+
+func newBarrierCommand(name string, outputParam string, value string, started *sync.WaitGroup) *barrierCommand {
+	out := &barrierCommand{BaseCommand: *cor.NewBaseCommand(name), value: value, started: started}
+	out.OutputParamName = outputParam
+	return out
+}
+
+func (c *barrierCommand) Execute(context cor.Context) {
+	c.started.Done()
+	done := make(chan struct{})
+	go func() {
+		c.started.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		context.AddError(c.GetName(), errors.New("commands did not run in parallel"))
+		return
+	}
+	if c.err != nil {
+		context.AddError(c.GetName(), c.err)
+		return
+	}
+	context.Add(c.GetOutputParam(), c.value)
+	context.Add(cor.CtxOut, c.value)
+}
+
+func newChainContext() cor.Context {
+	chainCtx := cor.NewBaseContext()
+	chainCtx.SetContext(context.Background())
+	chainCtx.Add(cor.CtxIn, "input")
+	return chainCtx
+}
+
+func TestParallelChain(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(3)
+
+	chain := cor.NewParallelChain("parallel")
+	chain.AddCommand(newBarrierCommand("first", "__first__", "one", &started)).
+		AddCommand(newBarrierCommand("second", "__second__", "two", &started)).
+		AddCommand(newBarrierCommand("third", "__third__", "three", &started))
+
+	chainCtx := newChainContext()
+	assert.True(t, chain.IsExecutable(chainCtx))
+	chain.Execute(chainCtx)
+
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, "one", chainCtx.Get("__first__"))
+	assert.Equal(t, "two", chainCtx.Get("__second__"))
+	assert.Equal(t, "three", chainCtx.Get("__third__"))
+	// The output of the last command is carried forward
+	assert.Equal(t, "three", chainCtx.Get(cor.CtxOut))
+	assert.Equal(t, "input", chainCtx.Get(cor.CtxIn))
+}
+
+func TestParallelChainMergesErrors(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+
+	failing := newBarrierCommand("failing", "__failing__", "", &started)
+	failing.err = errors.New("failed")
+
+	chain := cor.NewParallelChain("parallel")
+	chain.AddCommand(failing).AddCommand(newBarrierCommand("passing", "__passing__", "ok", &started))
+
+	chainCtx := newChainContext()
+	chain.Execute(chainCtx)
+
+	assert.True(t, chainCtx.HasErrors())
+	assert.EqualError(t, chainCtx.GetErrors()["failing"], "failed")
+	assert.Nil(t, chainCtx.Get("__failing__"))
+	assert.Equal(t, "ok", chainCtx.Get("__passing__"))
+}
+
+func TestParallelChainInSerialChain(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+
+	parallel := cor.NewParallelChain("parallel")
+	parallel.AddCommand(newBarrierCommand("left", "__left__", "left", &started)).
+		AddCommand(newBarrierCommand("right", "__right__", "right", &started))
+
+	chain := cor.NewBaseChain("serial")
+	chain.AddCommand(parallel)
+
+	chainCtx := newChainContext()
+	chain.Execute(chainCtx)
+
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, "left", chainCtx.Get("__left__"))
+	// The serial chain maps the parallel chains output to the next input
+	assert.Equal(t, "right", chainCtx.Get(cor.CtxIn))
+}
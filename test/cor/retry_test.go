@@ -0,0 +1,138 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyCommand fails until it has been executed the given number of times.
+type flakyCommand struct {
+	cor.BaseCommand
+	failures int
+	err      error
+	calls    int
+}
+
+func newFlakyCommand(failures int, err error) *flakyCommand {
+	return &flakyCommand{BaseCommand: *cor.NewBaseCommand("flaky"), failures: failures, err: err}
+}
+
+func (c *flakyCommand) Execute(context cor.Context) {
+	c.calls++
+	context.Add("__partial__", c.calls)
+	if c.calls <= c.failures {
+		context.AddError(c.GetName(), c.err)
+		return
+	}
+	context.Add(c.GetOutputParam(), "done")
+}
+
+func newRetryPolicy(maxAttempts int) *cor.RetryPolicy {
+	return &cor.RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, Multiplier: 2, Jitter: 0.5}
+}
+
+func TestRetryCommand(t *testing.T) {
+	command := newFlakyCommand(2, errors.New("unavailable"))
+	chainCtx := newChainContext()
+
+	cor.NewRetryCommand(command, newRetryPolicy(3)).Execute(chainCtx)
+
+	assert.Equal(t, 3, command.calls)
+	assert.False(t, chainCtx.HasErrors())
+	assert.Equal(t, "done", chainCtx.Get(cor.CtxOut))
+	// Only the values of the successful attempt are kept
+	assert.Equal(t, 3, chainCtx.Get("__partial__"))
+}
+
+func TestRetryCommandExhausted(t *testing.T) {
+	command := newFlakyCommand(5, errors.New("unavailable"))
+	chainCtx := newChainContext()
+
+	cor.NewRetryCommand(command, newRetryPolicy(3)).Execute(chainCtx)
+
+	assert.Equal(t, 3, command.calls)
+	assert.EqualError(t, chainCtx.GetErrors()["flaky"], "unavailable")
+	assert.Nil(t, chainCtx.Get(cor.CtxOut))
+}
+
+func TestRetryCommandNotRetryable(t *testing.T) {
+	command := newFlakyCommand(5, cor.Permanent(errors.New("bad request")))
+	chainCtx := newChainContext()
+
+	cor.NewRetryCommand(command, newRetryPolicy(3)).Execute(chainCtx)
+
+	assert.Equal(t, 1, command.calls)
+	assert.ErrorIs(t, chainCtx.GetErrors()["flaky"], cor.ErrPermanent)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &cor.RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 5*time.Second, policy.Backoff(4))
+
+	policy.Jitter = 0.5
+	for attempt := 1; attempt <= 3; attempt++ {
+		backoff := policy.Backoff(attempt)
+		assert.GreaterOrEqual(t, backoff, time.Duration(float64(time.Second)*0.5))
+		assert.LessOrEqual(t, backoff, time.Duration(float64(5*time.Second)*1.5))
+	}
+}
+
+func TestRetryPolicyAttemptTimeout(t *testing.T) {
+	policy := newRetryPolicy(2)
+	policy.AttemptTimeout = 10 * time.Millisecond
+
+	attempts := 0
+	err := policy.Do(context.Background(), func(ctx context.Context, attempt int) error {
+		attempts = attempt
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, 2, attempts)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryPolicyCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := &cor.RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Hour}
+
+	attempts := 0
+	err := policy.Do(ctx, func(_ context.Context, attempt int) error {
+		attempts = attempt
+		cancel()
+		return errors.New("unavailable")
+	})
+	assert.Equal(t, 1, attempts)
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestRetryCommandValidation(t *testing.T) {
+	chain := cor.NewBaseChain("retry")
+	chain.AddCommand(cor.NewRetryCommand(newWiredCommand("missing", []string{"__missing__"}, nil), newRetryPolicy(2)))
+
+	report := chain.Validate()
+	assert.Equal(t, []string{"missing requires __missing__"}, report.MissingProducers)
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package cor_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/stretchr/testify/assert"
+)
+
+// wiredCommand declares its parameters without executing any logic.
+type wiredCommand struct {
+	cor.BaseCommand
+	requires []string
+	produces []string
+}
+
+func newWiredCommand(name string, requires []string, produces []string) *wiredCommand {
+	return &wiredCommand{BaseCommand: *cor.NewBaseCommand(name), requires: requires, produces: produces}
+}
+
+func (c *wiredCommand) Execute(_ cor.Context) {}
+
+func (c *wiredCommand) GetRequiredParams() []string {
+	return c.requires
+}
+
+func (c *wiredCommand) GetProducedParams() []string {
+	return c.produces
+}
+
+func TestValidateChain(t *testing.T) {
+	details := cor.NewParallelChain("details")
+	details.AddCommand(newWiredCommand("length", []string{cor.CtxIn}, []string{"__length__", cor.CtxOut})).
+		AddCommand(newWiredCommand("content-type", []string{cor.CtxIn}, []string{"__type__", cor.CtxOut}))
+
+	chain := cor.NewBaseChain("valid")
+	chain.AddCommand(newWiredCommand("trigger", []string{cor.CtxIn}, []string{"__object__", cor.CtxOut})).
+		AddCommand(details).
+		AddCommand(newWiredCommand("summary", []string{cor.CtxIn, "__object__", "__length__", "__type__"}, []string{cor.CtxOut}))
+
+	report := chain.Validate()
+	assert.Nil(t, report.Err())
+	assert.Empty(t, report.MissingProducers)
+	assert.Empty(t, report.Collisions)
+	assert.Empty(t, report.DeadOutputs)
+}
+
+func TestValidateChainMissingProducer(t *testing.T) {
+	chain := cor.NewBaseChain("missing")
+	chain.AddCommand(newWiredCommand("summary", []string{cor.CtxIn}, []string{"__summary__"})).
+		AddCommand(newWiredCommand("scenes", []string{cor.CtxIn}, []string{cor.CtxOut})).
+		AddCommand(newWiredCommand("assembly", []string{"__summary__", "__scene__"}, []string{cor.CtxOut}))
+
+	report := chain.Validate()
+	assert.ErrorIs(t, report.Err(), cor.ErrInvalidChain)
+	// The summary did not produce CtxOut, and nothing produced the scenes
+	assert.Equal(t, []string{"scenes requires __IN__", "assembly requires __scene__"}, report.MissingProducers)
+}
+
+func TestValidateParallelChainSiblings(t *testing.T) {
+	chain := cor.NewParallelChain("siblings")
+	chain.AddCommand(newWiredCommand("first", []string{cor.CtxIn}, []string{"__shared__", cor.CtxOut})).
+		AddCommand(newWiredCommand("second", []string{"__shared__"}, []string{"__shared__", cor.CtxOut}))
+
+	report := chain.Validate()
+	assert.NotNil(t, report.Err())
+	assert.Equal(t, []string{"second requires __shared__"}, report.MissingProducers)
+	assert.Equal(t, []string{"__shared__ is produced by first and second"}, report.Collisions)
+}
+
+func TestValidateChainDeadOutput(t *testing.T) {
+	chain := cor.NewBaseChain("dead")
+	chain.AddCommand(newWiredCommand("trigger", []string{cor.CtxIn}, []string{"__object__", cor.CtxOut})).
+		AddCommand(newWiredCommand("resize", []string{cor.CtxIn}, []string{cor.CtxOut}))
+
+	report := chain.Validate()
+	assert.Nil(t, report.Err())
+	assert.Equal(t, []string{"__object__ produced by trigger is never used"}, report.DeadOutputs)
+}
@@ -0,0 +1,308 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingCommand fails until it has been executed the given number of times.
+type failingCommand struct {
+	cor.BaseCommand
+	failures int32
+	calls    atomic.Int32
+}
+
+func (c *failingCommand) Execute(context cor.Context) {
+	if c.calls.Add(1) <= c.failures {
+		context.AddError(c.GetName(), errors.New("unavailable"))
+	}
+}
+
+func newFailingCommand(failures int32) *failingCommand {
+	return &failingCommand{BaseCommand: *cor.NewBaseCommand("failing"), failures: failures}
+}
+
+func newRetryPolicy(maxAttempts int) *cor.RetryPolicy {
+	return &cor.RetryPolicy{MaxAttempts: maxAttempts, InitialBackoff: time.Millisecond, Multiplier: 2}
+}
+
+func waitForState(t *testing.T, queue *jobs.Queue, id string, state jobs.State) *jobs.Job {
+	var job *jobs.Job
+	assert.Eventually(t, func() bool {
+		var err error
+		job, err = queue.Get(context.Background(), id)
+		return err == nil && job.State == state
+	}, 5*time.Second, time.Millisecond)
+	return job
+}
+
+func TestQueue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	command := newFailingCommand(0)
+	queue := jobs.NewQueue(command, jobs.NewMemoryStore(), newRetryPolicy(3), 2)
+	assert.Nil(t, queue.Start(ctx))
+
+	job, err := queue.Enqueue(ctx, "1", "payload", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "failing-1", job.Id)
+
+	job = waitForState(t, queue, job.Id, jobs.StateSucceeded)
+	assert.Equal(t, 1, job.Attempts)
+
+	// A redelivered message is not executed again
+	_, err = queue.Enqueue(ctx, "1", "payload", nil)
+	assert.Nil(t, err)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, int32(1), command.calls.Load())
+}
+
+// blockingCommand blocks until released.
+type blockingCommand struct {
+	cor.BaseCommand
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *blockingCommand) Execute(_ cor.Context) {
+	c.calls.Add(1)
+	<-c.release
+}
+
+func TestQueueEnqueueWithBusyWorkers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	command := &blockingCommand{BaseCommand: *cor.NewBaseCommand("blocking"), release: make(chan struct{})}
+	queue := jobs.NewQueue(command, jobs.NewMemoryStore(), newRetryPolicy(1), 1)
+	assert.Nil(t, queue.Start(ctx))
+
+	// The worker is busy with the first job, the others are recorded without waiting for it
+	enqueued := make(chan struct{})
+	go func() {
+		for _, id := range []string{"1", "2", "3", "4"} {
+			_, err := queue.Enqueue(ctx, id, "payload", nil)
+			assert.Nil(t, err)
+		}
+		close(enqueued)
+	}()
+	select {
+	case <-enqueued:
+	case <-time.After(5 * time.Second):
+		t.Fatal("enqueue blocked on a busy worker")
+	}
+
+	close(command.release)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		waitForState(t, queue, "blocking-"+id, jobs.StateSucceeded)
+	}
+	assert.Equal(t, int32(4), command.calls.Load())
+}
+
+func TestQueueRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	command := newFailingCommand(2)
+	queue := jobs.NewQueue(command, jobs.NewMemoryStore(), newRetryPolicy(3), 1)
+	assert.Nil(t, queue.Start(ctx))
+
+	job, err := queue.Enqueue(ctx, "1", "payload", nil)
+	assert.Nil(t, err)
+
+	job = waitForState(t, queue, job.Id, jobs.StateSucceeded)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Empty(t, job.LastError)
+}
+
+func TestQueueDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lock sync.Mutex
+	deadLettered := make([]string, 0)
+
+	queue := jobs.NewQueue(newFailingCommand(10), jobs.NewMemoryStore(), newRetryPolicy(2), 1)
+	queue.SetDeadLetter(func(_ context.Context, job *jobs.Job) error {
+		lock.Lock()
+		defer lock.Unlock()
+		deadLettered = append(deadLettered, job.Payload)
+		return nil
+	})
+	assert.Nil(t, queue.Start(ctx))
+
+	job, err := queue.Enqueue(ctx, "1", "payload", nil)
+	assert.Nil(t, err)
+
+	job = waitForState(t, queue, job.Id, jobs.StateDeadLettered)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, "failing: unavailable", job.LastError)
+	lock.Lock()
+	assert.Equal(t, []string{"payload"}, deadLettered)
+	lock.Unlock()
+}
+
+func TestQueueFailedWithoutDeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	queue := jobs.NewQueue(newFailingCommand(10), jobs.NewMemoryStore(), newRetryPolicy(1), 1)
+	assert.Nil(t, queue.Start(ctx))
+
+	job, err := queue.Enqueue(ctx, "1", "payload", nil)
+	assert.Nil(t, err)
+	waitForState(t, queue, job.Id, jobs.StateFailed)
+
+	failed, err := queue.List(ctx, jobs.StateFailed, 0)
+	assert.Nil(t, err)
+	assert.Len(t, failed, 1)
+}
+
+// interruptedCommand fails once the context of its job is cancelled, as a chain interrupted at shutdown.
+type interruptedCommand struct {
+	cor.BaseCommand
+	started chan struct{}
+}
+
+func (c *interruptedCommand) Execute(context cor.Context) {
+	close(c.started)
+	<-context.GetContext().Done()
+	context.AddError(c.GetName(), context.GetContext().Err())
+}
+
+func TestQueueShutdownRequeues(t *testing.T) {
+	dir := t.TempDir()
+	store, err := jobs.NewFileStore(dir, 0)
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	command := &interruptedCommand{BaseCommand: *cor.NewBaseCommand("failing"), started: make(chan struct{})}
+	queue := jobs.NewQueue(command, store, newRetryPolicy(1), 1)
+	deadLettered := atomic.Int32{}
+	queue.SetDeadLetter(func(context.Context, *jobs.Job) error {
+		deadLettered.Add(1)
+		return nil
+	})
+	assert.Nil(t, queue.Start(ctx))
+
+	job, err := queue.Enqueue(ctx, "1", "payload", nil)
+	assert.Nil(t, err)
+	<-command.started
+	cancel()
+
+	// The interrupted job is queued again with its attempt given back
+	job = waitForState(t, queue, job.Id, jobs.StateQueued)
+	assert.Equal(t, 0, job.Attempts)
+	assert.Equal(t, int32(0), deadLettered.Load())
+
+	// and resumed by the next process
+	store, err = jobs.NewFileStore(dir, 0)
+	assert.Nil(t, err)
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	queue = jobs.NewQueue(newFailingCommand(0), store, newRetryPolicy(1), 1)
+	assert.Nil(t, queue.Start(ctx))
+	job = waitForState(t, queue, job.Id, jobs.StateSucceeded)
+	assert.Equal(t, 1, job.Attempts)
+}
+
+func TestQueueResume(t *testing.T) {
+	dir := t.TempDir()
+	store, err := jobs.NewFileStore(dir, 0)
+	assert.Nil(t, err)
+
+	// A job left running by a stopped process
+	job := jobs.NewJob("failing", "failing-1", "payload", nil)
+	_, _, err = store.Create(context.Background(), job)
+	assert.Nil(t, err)
+	assert.Nil(t, job.Transition(jobs.StateRunning))
+	assert.Nil(t, store.Update(context.Background(), job))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	store, err = jobs.NewFileStore(dir, 0)
+	assert.Nil(t, err)
+	queue := jobs.NewQueue(newFailingCommand(0), store, newRetryPolicy(1), 1)
+	assert.Nil(t, queue.Start(ctx))
+
+	waitForState(t, queue, "failing-1", jobs.StateSucceeded)
+}
+
+func TestFileStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	store, err := jobs.NewFileStore(dir, time.Hour)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	// Jobs last updated two hours ago, only the finished one is out of retention
+	for _, id := range []string{"succeeded", "queued"} {
+		job := jobs.NewJob("queue", id, "payload", nil)
+		_, _, err = store.Create(ctx, job)
+		assert.Nil(t, err)
+		if id == "succeeded" {
+			assert.Nil(t, job.Transition(jobs.StateRunning))
+			assert.Nil(t, job.Transition(jobs.StateSucceeded))
+		}
+		job.UpdateTime = time.Now().Add(-2 * time.Hour)
+		assert.Nil(t, store.Update(ctx, job))
+	}
+	recent := jobs.NewJob("queue", "recent", "payload", nil)
+	_, _, err = store.Create(ctx, recent)
+	assert.Nil(t, err)
+	assert.Nil(t, recent.Transition(jobs.StateRunning))
+	assert.Nil(t, recent.Transition(jobs.StateSucceeded))
+	assert.Nil(t, store.Update(ctx, recent))
+
+	store, err = jobs.NewFileStore(dir, time.Hour)
+	assert.Nil(t, err)
+	_, err = store.Get(ctx, "succeeded")
+	assert.ErrorIs(t, err, jobs.ErrJobNotFound)
+	assert.NoFileExists(t, filepath.Join(dir, "succeeded.json"))
+	for _, id := range []string{"queued", "recent"} {
+		_, err = store.Get(ctx, id)
+		assert.Nil(t, err)
+	}
+
+	assert.Nil(t, store.Prune(ctx, time.Now().Add(time.Minute)))
+	_, err = store.Get(ctx, "recent")
+	assert.ErrorIs(t, err, jobs.ErrJobNotFound)
+	assert.NoFileExists(t, filepath.Join(dir, "recent.json"))
+	_, err = store.Get(ctx, "queued")
+	assert.Nil(t, err)
+}
+
+func TestJobTransition(t *testing.T) {
+	job := jobs.NewJob("queue", "1", "payload", nil)
+	assert.ErrorIs(t, job.Transition(jobs.StateSucceeded), jobs.ErrInvalidTransition)
+	assert.Nil(t, job.Transition(jobs.StateRunning))
+	assert.Nil(t, job.Transition(jobs.StateFailed))
+	assert.True(t, job.State.IsTerminal())
+	assert.Nil(t, job.Transition(jobs.StateDeadLettered))
+	assert.ErrorIs(t, job.Transition(jobs.StateQueued), jobs.ErrInvalidTransition)
+}
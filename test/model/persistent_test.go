@@ -50,3 +50,18 @@ func TestNewSceneEmbedding(t *testing.T) {
 	assert.Equal(t, modelName, embedding.ModelName)
 	assert.Equal(t, 0, len(embedding.Embeddings))
 }
+
+func TestNormalizeActorName(t *testing.T) {
+	assert.Equal(t, "samuel l jackson", model.NormalizeActorName(" Samuel  L. Jackson (voice)"))
+	assert.Equal(t, "jean claude van damme", model.NormalizeActorName("Jean-Claude Van Damme [uncredited]"))
+	assert.Equal(t, "", model.NormalizeActorName("(unidentified)"))
+}
+
+func TestNewActor(t *testing.T) {
+	actor := model.NewActor("Samuel  L. Jackson")
+
+	assert.Equal(t, model.NewActor("samuel l jackson").Id, actor.Id)
+	assert.Equal(t, "Samuel L. Jackson", actor.Name)
+	assert.WithinDuration(t, time.Now(), actor.CreateDate, time.Second)
+	assert.Equal(t, 0, len(actor.Aliases))
+}
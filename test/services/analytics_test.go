@@ -0,0 +1,186 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func newAnalyticsService(t *testing.T) *services.AnalyticsService {
+	sink, err := cloud.NewAnalyticsSink(cloud.Analytics{Backend: "local", Path: t.TempDir()}, cloud.BigQueryDataSource{}, nil)
+	assert.NoError(t, err)
+	return &services.AnalyticsService{Sink: sink}
+}
+
+// search records a search of the query with the number of results and returns its id.
+func search(t *testing.T, analytics *services.AnalyticsService, query string, results int, latency time.Duration) string {
+	matches := make([]*model.SceneMatchResult, 0)
+	for i := 0; i < results; i++ {
+		matches = append(matches, &model.SceneMatchResult{MediaId: "m1", SequenceNumber: i})
+	}
+	event := services.SearchEvent(query, services.SearchModeVector, &services.SceneFilter{Genre: []string{"action"}}, latency, matches)
+	assert.NoError(t, analytics.RecordSearch(context.Background(), event))
+	return event.Id
+}
+
+func feedback(t *testing.T, analytics *services.AnalyticsService, searchId string, feedbackType string) {
+	assert.NoError(t, analytics.RecordFeedback(context.Background(), &model.FeedbackEvent{
+		SearchId: searchId, Type: feedbackType, MediaId: "m1", SequenceNumber: 0,
+	}))
+}
+
+func TestAnalyticsSearchEvent(t *testing.T) {
+	results := []*model.SceneMatchResult{{MediaId: "m1", SequenceNumber: 2}, {MediaId: "m2", SequenceNumber: 0}}
+	event := services.SearchEvent("  Car  CHASE ", services.SearchModeHybrid, &services.SceneFilter{Genre: []string{"action"}}, 1500*time.Millisecond, results)
+	assert.That(t, event.Id != "")
+	assert.Equal(t, "car chase", event.Query)
+	assert.Equal(t, "hybrid", event.Mode)
+	assert.Equal(t, int64(1500), event.LatencyMs)
+	assert.DeepEqual(t, []string{"m1/2", "m2/0"}, event.ResultIds)
+	assert.That(t, event.Filter != "")
+}
+
+func TestRecordedCursor(t *testing.T) {
+	ctx := context.Background()
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, index.Upsert(ctx, []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 1, Embeddings: []float64{0, 0}},
+		{Id: "b", SequenceNumber: 1, Embeddings: []float64{1, 1}},
+	}))
+	search := &services.SearchService{
+		ModelName:      "text-embedding-005",
+		Index:          index,
+		EmbeddingCache: services.NewCache[string, []float64]("test.embedding", 10, 0),
+	}
+	search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "car chase"), []float64{0, 0})
+
+	page, err := search.SearchScenes(ctx, "car chase", services.SearchModeVector, nil, nil, "", 1)
+	assert.NoError(t, err)
+	searchId, err := services.CursorSearchId(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "", searchId)
+
+	// The next pages carry the id of the recorded first page
+	cursor, err := services.RecordedCursor(page.NextCursor, "search-1")
+	assert.NoError(t, err)
+	searchId, err = services.CursorSearchId(cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, "search-1", searchId)
+	page, err = search.SearchScenes(ctx, "car chase", services.SearchModeVector, nil, nil, cursor, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", page.Results[0].MediaId)
+
+	_, err = services.CursorSearchId("not a cursor")
+	assert.That(t, errors.Is(err, services.ErrInvalidCursor))
+}
+
+func TestAnalyticsInvalidFeedback(t *testing.T) {
+	analytics := newAnalyticsService(t)
+	for _, event := range []*model.FeedbackEvent{
+		{SearchId: "s1", Type: "like", MediaId: "m1"},
+		{Type: model.FeedbackClick, MediaId: "m1"},
+		{SearchId: "s1", Type: model.FeedbackClick},
+		{SearchId: "s1", Type: model.FeedbackClick, MediaId: "m1", SequenceNumber: -1},
+	} {
+		err := analytics.RecordFeedback(context.Background(), event)
+		assert.That(t, errors.Is(err, services.ErrInvalidFeedback))
+	}
+}
+
+func TestAnalyticsReport(t *testing.T) {
+	analytics := newAnalyticsService(t)
+
+	// Never found
+	search(t, analytics, "flying whales", 0, 100*time.Millisecond)
+	search(t, analytics, "Flying Whales", 0, 300*time.Millisecond)
+	search(t, analytics, "purple cars", 0, 0)
+	// Found but never opened
+	for i := 0; i < 5; i++ {
+		search(t, analytics, "sunset", 3, 0)
+	}
+	// Found and opened
+	for i := 0; i < 5; i++ {
+		id := search(t, analytics, "car chase", 3, 0)
+		feedback(t, analytics, id, model.FeedbackClick)
+		feedback(t, analytics, id, model.FeedbackClick)
+		feedback(t, analytics, id, model.FeedbackThumbsUp)
+	}
+	// Too few searches for a rate
+	search(t, analytics, "rain", 3, 0)
+
+	report, err := analytics.Report(context.Background(), services.ReportOptions{
+		MinSearches: 5, MaxCTR: 0.1, Limit: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 14, report.Searches)
+	assert.Equal(t, 5, report.Queries)
+
+	assert.Equal(t, 2, len(report.ZeroResultQueries))
+	assert.Equal(t, "flying whales", report.ZeroResultQueries[0].Query)
+	assert.Equal(t, 2, report.ZeroResultQueries[0].ZeroResults)
+	assert.Equal(t, 200.0, report.ZeroResultQueries[0].MeanLatencyMs)
+	assert.Equal(t, "purple cars", report.ZeroResultQueries[1].Query)
+
+	assert.Equal(t, 1, len(report.LowCTRQueries))
+	assert.Equal(t, "sunset", report.LowCTRQueries[0].Query)
+	assert.Equal(t, 0.0, report.LowCTRQueries[0].ClickThroughRate)
+
+	// Clicks count once per search
+	report, err = analytics.Report(context.Background(), services.ReportOptions{MinSearches: 1, MaxCTR: 1})
+	assert.NoError(t, err)
+	var chase *model.QueryStats
+	for _, q := range report.LowCTRQueries {
+		if q.Query == "car chase" {
+			chase = q
+		}
+	}
+	assert.That(t, chase != nil)
+	assert.Equal(t, 5, chase.ClickedSearches)
+	assert.Equal(t, 1.0, chase.ClickThroughRate)
+	assert.Equal(t, 5, chase.ThumbsUp)
+}
+
+func TestAnalyticsReportSince(t *testing.T) {
+	analytics := newAnalyticsService(t)
+	search(t, analytics, "flying whales", 0, 0)
+
+	report, err := analytics.Report(context.Background(), services.ReportOptions{Since: time.Now().Add(time.Hour)})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, report.Searches)
+	assert.Equal(t, 0, len(report.ZeroResultQueries))
+}
+
+func TestAnalyticsSinkConfig(t *testing.T) {
+	sink, err := cloud.NewAnalyticsSink(cloud.Analytics{}, cloud.BigQueryDataSource{}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, sink)
+	_, err = cloud.NewAnalyticsSink(cloud.Analytics{Backend: "local"}, cloud.BigQueryDataSource{}, nil)
+	assert.Error(t, err)
+	_, err = cloud.NewAnalyticsSink(cloud.Analytics{Backend: "bigquery"}, cloud.BigQueryDataSource{}, nil)
+	assert.Error(t, err)
+	_, err = cloud.NewAnalyticsSink(cloud.Analytics{Backend: "kafka"}, cloud.BigQueryDataSource{}, nil)
+	assert.Error(t, err)
+}
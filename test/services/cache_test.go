@@ -0,0 +1,108 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := services.NewCache[string, int]("test", 2, 0)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	_, ok := cache.Get(ctx, "a")
+	assert.That(t, ok)
+	cache.Put("c", 3)
+
+	_, ok = cache.Get(ctx, "b")
+	assert.That(t, !ok)
+	value, ok := cache.Get(ctx, "a")
+	assert.That(t, ok)
+	assert.Equal(t, 1, value)
+	assert.Equal(t, 2, cache.Len())
+
+	cache.Clear()
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCacheExpires(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	cache := services.NewCache[string, int]("test", 2, time.Minute)
+	cache.SetClock(func() time.Time { return now })
+	cache.Put("a", 1)
+
+	now = now.Add(59 * time.Second)
+	_, ok := cache.Get(ctx, "a")
+	assert.That(t, ok)
+	now = now.Add(time.Second)
+	_, ok = cache.Get(ctx, "a")
+	assert.That(t, !ok)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCacheDisabled(t *testing.T) {
+	cache := services.NewCache[string, int]("test", 0, time.Minute)
+	assert.Nil(t, cache)
+	cache.Put("a", 1)
+	_, ok := cache.Get(context.Background(), "a")
+	assert.That(t, !ok)
+}
+
+func TestSearchScenesCache(t *testing.T) {
+	ctx := context.Background()
+	hnsw, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.NoError(t, err)
+	index := cloud.NewNotifyingVectorIndex(hnsw)
+	assert.NoError(t, index.Upsert(ctx, []*model.SceneEmbedding{{Id: "a", SequenceNumber: 1, Embeddings: []float64{1, 0}}}))
+
+	// The query is embedded from the cache, so no embedding model is needed
+	search := &services.SearchService{
+		ModelName:      "text-embedding-005",
+		Index:          index,
+		EmbeddingCache: services.NewCache[string, []float64]("test.embedding", 10, 0),
+		ResultCache:    services.NewCache[string, *services.ScenePage]("test.result", 10, 0),
+	}
+	search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "car chase"), []float64{0, 0})
+	index.OnChange(search.InvalidateResults)
+
+	page, err := search.SearchScenes(ctx, " Car  Chase", services.SearchModeVector, nil, nil, "", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(page.Results))
+	// Cached pages are copies
+	page.Results[0].Reason = "changed"
+	page, err = search.SearchScenes(ctx, "car chase", services.SearchModeVector, nil, nil, "", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, "", page.Results[0].Reason)
+
+	assert.NoError(t, index.Upsert(ctx, []*model.SceneEmbedding{{Id: "b", SequenceNumber: 1, Embeddings: []float64{0, 0}}}))
+	assert.Equal(t, 0, search.ResultCache.Len())
+
+	page, err = search.SearchScenes(ctx, "car chase", services.SearchModeVector, nil, nil, "", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Results))
+	assert.Equal(t, "b", page.Results[0].MediaId)
+	assert.Equal(t, 1, search.ResultCache.Len())
+}
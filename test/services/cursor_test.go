@@ -0,0 +1,50 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func TestPageSize(t *testing.T) {
+	assert.Equal(t, services.DefaultPageSize, services.PageSize(0))
+	assert.Equal(t, 1, services.PageSize(-5))
+	assert.Equal(t, 7, services.PageSize(7))
+	assert.Equal(t, services.MaxPageSize, services.PageSize(services.MaxPageSize+1))
+}
+
+func TestParseMediaSort(t *testing.T) {
+	sort, err := services.ParseMediaSort("")
+	assert.NoError(t, err)
+	assert.Equal(t, services.MediaSort{Field: services.MediaSortCreateDate, Descending: true}, sort)
+	assert.Equal(t, services.DefaultMediaSort, sort.String())
+
+	sort, err = services.ParseMediaSort("title")
+	assert.NoError(t, err)
+	assert.Equal(t, services.MediaSort{Field: services.MediaSortTitle}, sort)
+	assert.Equal(t, "title", sort.String())
+
+	sort, err = services.ParseMediaSort("-release_year")
+	assert.NoError(t, err)
+	assert.That(t, sort.Descending)
+
+	_, err = services.ParseMediaSort("summary; DROP TABLE media")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+	"google.golang.org/genai"
+)
+
+// explainModel answers with the explanations, recording the prompt.
+type explainModel struct {
+	explanations []*model.SceneExplanation
+	prompt       string
+}
+
+func (e *explainModel) GenerateContent(_ context.Context, _ string, contents []*genai.Content, _ *genai.Schema) (*genai.GenerateContentResponse, error) {
+	e.prompt = contents[0].Parts[0].Text
+	out, _ := json.Marshal(e.explanations)
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(string(out), genai.RoleModel)}},
+	}, nil
+}
+
+func TestExplain(t *testing.T) {
+	first, second := scene("a", 1), scene("a", 2)
+	results := []*model.MediaMatchResult{{
+		Media: &model.Media{Id: "a", Title: "Heat", Scenes: []*model.Scene{
+			{SequenceNumber: 1, Script: "EXT. STREET - DAY\nA car chase through downtown."},
+			{SequenceNumber: 2, Script: "INT. DINER - NIGHT\nTwo men talk over coffee."},
+		}},
+		Matches: []*model.SceneMatchResult{first, second},
+	}}
+	fake := &explainModel{explanations: []*model.SceneExplanation{
+		{MediaId: "a", SequenceNumber: 1, Span: "A car chase through downtown.", Reason: "The scene is a car chase."},
+		{MediaId: "a", SequenceNumber: 2, Span: "A shootout at the bank.", Reason: "Invented span."},
+		{MediaId: "b", SequenceNumber: 1, Span: "Unknown", Reason: "Unknown scene."},
+	}}
+
+	explainer := &services.ExplainService{Model: fake}
+	assert.NoError(t, explainer.Explain(context.Background(), "car chase", results))
+
+	assert.That(t, strings.Contains(fake.prompt, "Query: car chase"))
+	assert.That(t, strings.Contains(fake.prompt, "Two men talk over coffee."))
+	assert.Equal(t, "A car chase through downtown.", first.Span)
+	assert.Equal(t, "The scene is a car chase.", first.Reason)
+	// A span not found in the script is dropped
+	assert.Equal(t, "", second.Span)
+	assert.Equal(t, "Invented span.", second.Reason)
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func TestParseFacets(t *testing.T) {
+	out, err := services.ParseFacets("")
+	assert.NoError(t, err)
+	assert.DeepEqual(t, services.DefaultFacets, out)
+
+	out, err = services.ParseFacets(" Genre, release_decade")
+	assert.NoError(t, err)
+	assert.DeepEqual(t, []string{services.FacetGenre, services.FacetReleaseDecade}, out)
+
+	_, err = services.ParseFacets("genre,budget")
+	assert.That(t, errors.Is(err, services.ErrInvalidFacet))
+}
+
+func TestFacetQuery(t *testing.T) {
+	sql, err := services.FacetQuery("p.media_ds.media", []string{services.FacetGenre, services.FacetCast})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(sql, " UNION ALL "))
+	assert.That(t, strings.Contains(sql, "SELECT 'genre' AS facet, ANY_VALUE(m.genre) AS value"))
+	assert.That(t, strings.Contains(sql, "FROM `p.media_ds.media` m, UNNEST(m.cast) c WHERE m.id IN UNNEST(@facet_media_ids)"))
+	assert.That(t, strings.Contains(sql, "GROUP BY LOWER(c.actor_name)"))
+	assert.That(t, strings.Contains(sql, "<= @facet_size"))
+}
+
+func TestFacetCountsWithoutMedia(t *testing.T) {
+	search := &services.SearchService{}
+	out, err := search.FacetCounts(context.Background(), nil, []string{services.FacetGenre, services.FacetRating})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, 0, len(out[services.FacetGenre]))
+}
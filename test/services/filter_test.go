@@ -0,0 +1,72 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func TestSceneFilterEmpty(t *testing.T) {
+	var filter *services.SceneFilter
+	where, params := filter.Where("m")
+	assert.Equal(t, "", where)
+	assert.Equal(t, 0, len(params))
+
+	where, _ = (&services.SceneFilter{}).Where("m")
+	assert.Equal(t, "", where)
+}
+
+func TestSceneFilterWhere(t *testing.T) {
+	filter := &services.SceneFilter{
+		Genre:       []string{"Action"},
+		Rating:      []string{"PG-13", "R"},
+		ReleaseYear: services.IntRange{Min: 2016},
+	}
+	where, params := filter.Where("m")
+	assert.Equal(t, "LOWER(m.genre) = @filter_genre AND LOWER(m.rating) IN UNNEST(@filter_rating) AND m.release_year >= @filter_release_year_min", where)
+	assert.Equal(t, 3, len(params))
+	assert.Equal(t, "action", params[0].Value)
+	assert.DeepEqual(t, []string{"pg-13", "r"}, params[1].Value)
+	assert.Equal(t, 2016, params[2].Value)
+}
+
+func TestSceneFilterCast(t *testing.T) {
+	filter := &services.SceneFilter{Cast: []string{"Al Pacino "}}
+	where, params := filter.Where("m")
+	assert.Equal(t, "EXISTS (SELECT 1 FROM UNNEST(m.cast) c WHERE LOWER(c.actor_name) IN UNNEST(@filter_cast))", where)
+	assert.DeepEqual(t, []string{"al pacino"}, params[0].Value)
+}
+
+func TestSceneFilterMerge(t *testing.T) {
+	interpreted := &services.SceneFilter{
+		Genre:       []string{"thriller"},
+		Director:    []string{"Michael Mann"},
+		ReleaseYear: services.IntRange{Min: 1990, Max: 1999},
+	}
+	merged := interpreted.Merge(&services.SceneFilter{Genre: []string{"action"}, Rating: []string{"R"}})
+	assert.DeepEqual(t, []string{"action"}, merged.Genre)
+	assert.DeepEqual(t, []string{"R"}, merged.Rating)
+	assert.DeepEqual(t, []string{"Michael Mann"}, merged.Director)
+	assert.Equal(t, services.IntRange{Min: 1990, Max: 1999}, merged.ReleaseYear)
+	assert.DeepEqual(t, []string{"thriller"}, interpreted.Genre)
+
+	var none *services.SceneFilter
+	assert.DeepEqual(t, &services.SceneFilter{}, none.Merge(nil))
+}
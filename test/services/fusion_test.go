@@ -0,0 +1,112 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func scene(mediaId string, sequence int) *model.SceneMatchResult {
+	return &model.SceneMatchResult{MediaId: mediaId, SequenceNumber: sequence}
+}
+
+func TestReciprocalRankFusion(t *testing.T) {
+	vector := []*model.SceneMatchResult{scene("a", 1), scene("b", 1), scene("c", 1)}
+	keyword := []*model.SceneMatchResult{scene("c", 1), scene("b", 1), scene("d", 1)}
+
+	out := services.ReciprocalRankFusion(60,
+		services.RankedList{Results: vector, Weight: 1},
+		services.RankedList{Results: keyword, Weight: 1})
+
+	assert.Equal(t, 4, len(out))
+	// c and b appear in both rankings, a first and a third rank outscore two seconds
+	assert.Equal(t, "c", out[0].MediaId)
+	assert.Equal(t, "b", out[1].MediaId)
+	assert.Equal(t, "a", out[2].MediaId)
+	assert.Equal(t, "d", out[3].MediaId)
+	assert.Equal(t, 1.0/62+1.0/62, out[1].Score)
+}
+
+func TestReciprocalRankFusionWeights(t *testing.T) {
+	vector := []*model.SceneMatchResult{scene("a", 1), scene("b", 1)}
+	keyword := []*model.SceneMatchResult{scene("b", 1), scene("a", 1)}
+
+	out := services.ReciprocalRankFusion(60,
+		services.RankedList{Results: vector, Weight: 1},
+		services.RankedList{Results: keyword, Weight: 2})
+
+	assert.Equal(t, "b", out[0].MediaId)
+	assert.Equal(t, "a", out[1].MediaId)
+}
+
+func TestReciprocalRankFusionKeepsSimilarity(t *testing.T) {
+	vector := []*model.SceneMatchResult{{MediaId: "a", SequenceNumber: 1, Similarity: 0.8, Distance: 0.25}}
+	keyword := []*model.SceneMatchResult{scene("b", 1), scene("a", 1)}
+
+	out := services.ReciprocalRankFusion(60,
+		services.RankedList{Results: keyword, Weight: 1},
+		services.RankedList{Results: vector, Weight: 1})
+
+	assert.Equal(t, "a", out[0].MediaId)
+	assert.Equal(t, 0.8, out[0].Similarity)
+	assert.Equal(t, 0.25, out[0].Distance)
+	assert.Equal(t, 0.0, out[1].Similarity)
+}
+
+func TestParseSearchMode(t *testing.T) {
+	mode, err := services.ParseSearchMode("Hybrid")
+	assert.NoError(t, err)
+	assert.Equal(t, services.SearchModeHybrid, mode)
+
+	mode, err = services.ParseSearchMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, services.DefaultSearchMode, mode)
+
+	_, err = services.ParseSearchMode("fuzzy")
+	assert.That(t, err != nil)
+}
+
+func TestHybridSearchZeroWeight(t *testing.T) {
+	ctx := context.Background()
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, index.Upsert(ctx, []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 1, Embeddings: []float64{1, 0}},
+		{Id: "b", SequenceNumber: 1, Embeddings: []float64{0, 1}},
+	}))
+
+	// A keyword weight of 0 switches the keyword ranking off, so no BigQuery client is needed
+	off := 0.0
+	search := &services.SearchService{
+		ModelName:      "text-embedding-005",
+		Index:          index,
+		KeywordWeight:  &off,
+		EmbeddingCache: services.NewCache[string, []float64]("test.embedding", 10, 0),
+	}
+	search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "car chase"), []float64{0, 1})
+
+	page, err := search.SearchScenes(ctx, "car chase", services.SearchModeHybrid, nil, nil, "", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(page.Results))
+	assert.Equal(t, "b", page.Results[0].MediaId)
+}
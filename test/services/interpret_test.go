@@ -0,0 +1,105 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+	"google.golang.org/genai"
+)
+
+// interpretModel answers with the interpretation, recording the prompt.
+type interpretModel struct {
+	interpretation string
+	prompt         string
+}
+
+func (i *interpretModel) GenerateContent(_ context.Context, _ string, contents []*genai.Content, _ *genai.Schema) (*genai.GenerateContentResponse, error) {
+	i.prompt = contents[0].Parts[0].Text
+	return &genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: genai.NewContentFromText(i.interpretation, genai.RoleModel)}},
+	}, nil
+}
+
+func TestInterpret(t *testing.T) {
+	fake := &interpretModel{interpretation: `{"query": "night car chases", "filter": {"category": ["Movie", "Cartoon"],
+		"genre": ["thriller"], "director": ["Michael Mann"], "release_year": {"min": 1990, "max": 1999}}}`}
+	interpreter := &services.InterpretService{Model: fake, Categories: []string{"movie", "trailer"}}
+
+	out, err := interpreter.Interpret(context.Background(), "night car chases in 90s thrillers directed by Michael Mann")
+	assert.NoError(t, err)
+	assert.That(t, strings.Contains(fake.prompt, "Categories: movie, trailer"))
+	assert.Equal(t, "night car chases", out.Query)
+	assert.DeepEqual(t, []string{"movie"}, out.Filter.Category)
+	assert.DeepEqual(t, []string{"thriller"}, out.Filter.Genre)
+	assert.DeepEqual(t, []string{"Michael Mann"}, out.Filter.Director)
+	assert.Equal(t, services.IntRange{Min: 1990, Max: 1999}, out.Filter.ReleaseYear)
+}
+
+func TestInterpretKeepsQuery(t *testing.T) {
+	interpreter := &services.InterpretService{Model: &interpretModel{interpretation: `{"query": " ", "filter": {}}`}}
+	out, err := interpreter.Interpret(context.Background(), "car chases")
+	assert.NoError(t, err)
+	assert.Equal(t, "car chases", out.Query)
+
+	interpreter = &services.InterpretService{Model: &interpretModel{interpretation: "not json"}}
+	_, err = interpreter.Interpret(context.Background(), "car chases")
+	assert.Error(t, err)
+}
+
+func TestInterpretedCursor(t *testing.T) {
+	ctx := context.Background()
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, index.Upsert(ctx, []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 1, Embeddings: []float64{0, 0}},
+		{Id: "b", SequenceNumber: 1, Embeddings: []float64{1, 1}},
+	}))
+	search := &services.SearchService{
+		ModelName:      "text-embedding-005",
+		Index:          index,
+		EmbeddingCache: services.NewCache[string, []float64]("test.embedding", 10, 0),
+	}
+	search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "car chases"), []float64{0, 0})
+	interpretation := &services.QueryInterpretation{Query: "car chases", Filter: services.SceneFilter{Genre: []string{"thriller"}}}
+
+	page, err := search.SearchScenes(ctx, interpretation.Query, services.SearchModeVector, nil, nil, "", 1)
+	assert.NoError(t, err)
+	cursor, err := services.InterpretedCursor(page.NextCursor, "car chases in thrillers", interpretation)
+	assert.NoError(t, err)
+
+	// The next page is searched with the interpretation of its cursor
+	carried, err := services.CursorInterpretation(cursor, "Car chases in  thrillers")
+	assert.NoError(t, err)
+	assert.DeepEqual(t, interpretation, carried)
+	page, err = search.SearchScenes(ctx, carried.Query, services.SearchModeVector, nil, nil, cursor, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", page.Results[0].MediaId)
+
+	_, err = services.CursorInterpretation(cursor, "car chases in comedies")
+	assert.That(t, errors.Is(err, services.ErrInvalidCursor))
+	carried, err = services.CursorInterpretation(page.NextCursor, "car chases")
+	assert.NoError(t, err)
+	assert.Nil(t, carried)
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func TestRankMedia(t *testing.T) {
+	matches := []*model.SceneMatchResult{scene("b", 3), scene("a", 1), scene("b", 1), scene("gone", 1), scene("a", 9)}
+	media := map[string]*model.Media{
+		"a": {Id: "a", Title: "A", Scenes: []*model.Scene{{SequenceNumber: 1}, {SequenceNumber: 2}}},
+		"b": {Id: "b", Title: "B"},
+	}
+	scenes := map[services.SceneKey]*model.Scene{
+		{MediaId: "a", SequenceNumber: 1}:    {SequenceNumber: 1},
+		{MediaId: "b", SequenceNumber: 1}:    {SequenceNumber: 1},
+		{MediaId: "b", SequenceNumber: 3}:    {SequenceNumber: 3},
+		{MediaId: "gone", SequenceNumber: 1}: {SequenceNumber: 1},
+	}
+
+	out := services.RankMedia(matches, media, scenes)
+
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, "b", out[0].Id)
+	assert.Equal(t, 2, len(out[0].Scenes))
+	assert.Equal(t, 3, out[0].Scenes[0].SequenceNumber)
+	assert.Equal(t, 1, out[0].Scenes[1].SequenceNumber)
+	assert.Equal(t, 1.0/61+1.0/63, out[0].Score)
+	assert.Equal(t, matches[0], out[0].Matches[0])
+	assert.Equal(t, matches[2], out[0].Matches[1])
+
+	// The scene missing from the scenes is skipped
+	assert.Equal(t, "a", out[1].Id)
+	assert.Equal(t, 1, len(out[1].Scenes))
+	assert.Equal(t, 1.0/62, out[1].Score)
+
+	// The media passed in are not modified
+	assert.Equal(t, 2, len(media["a"].Scenes))
+}
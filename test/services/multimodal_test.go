@@ -0,0 +1,92 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+// fakeMultimodalModel embeds an image as its first two bytes and a clip as their negation.
+type fakeMultimodalModel struct{}
+
+func (fakeMultimodalModel) EmbedImage(_ context.Context, image []byte) ([]float64, error) {
+	return []float64{float64(image[0]), float64(image[1])}, nil
+}
+
+func (fakeMultimodalModel) EmbedVideo(_ context.Context, video []byte) ([]float64, error) {
+	return []float64{-float64(video[0]), -float64(video[1])}, nil
+}
+
+func multimodalService(t *testing.T) *services.SearchService {
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, index.Upsert(context.Background(), []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 1, Embeddings: []float64{1, 1}},
+		{Id: "a", SequenceNumber: 2, Embeddings: []float64{-1, -1}},
+		{Id: "b", SequenceNumber: 1, Embeddings: []float64{2, 2}},
+	}))
+	return &services.SearchService{MultimodalModel: fakeMultimodalModel{}, MultimodalIndex: index}
+}
+
+func TestFindScenesByMedia(t *testing.T) {
+	ctx := context.Background()
+	search := multimodalService(t)
+
+	out, err := search.FindScenesByMedia(ctx, "image/jpeg", []byte{1, 1}, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, "a", out[0].MediaId)
+	assert.Equal(t, 1, out[0].SequenceNumber)
+	assert.Equal(t, 1.0, out[0].Similarity)
+	assert.Equal(t, "b", out[1].MediaId)
+
+	out, err = search.FindScenesByMedia(ctx, "video/mp4", []byte{1, 1}, nil, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(out))
+	assert.Equal(t, "a", out[0].MediaId)
+	assert.Equal(t, 2, out[0].SequenceNumber)
+
+	_, err = search.FindScenesByMedia(ctx, "text/plain", []byte("frame"), nil, 1)
+	assert.That(t, errors.Is(err, services.ErrUnsupportedMediaType))
+}
+
+func TestFindScenesByMediaDisabled(t *testing.T) {
+	search := &services.SearchService{}
+	_, err := search.FindScenesByImage(context.Background(), []byte{1, 1}, nil, 1)
+	assert.That(t, errors.Is(err, services.ErrMultimodalDisabled))
+}
+
+func TestFindScenesByMediaMinSimilarity(t *testing.T) {
+	ctx := context.Background()
+	search := multimodalService(t)
+	// The script threshold doesn't apply to keyframe matches
+	search.MinSimilarity = 0.99
+	search.MultimodalMinSimilarity = 0.4
+
+	out, err := search.FindScenesByMedia(ctx, "image/jpeg", []byte{1, 1}, nil, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, "a", out[0].MediaId)
+	assert.Equal(t, "b", out[1].MediaId)
+}
@@ -0,0 +1,304 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+// recordingNotifier keeps the alerts it is notified of.
+type recordingNotifier struct {
+	alerts []*model.SavedSearchAlert
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, alert *model.SavedSearchAlert) error {
+	r.alerts = append(r.alerts, alert)
+	return nil
+}
+
+// failingNotifier fails the first alerts it is notified of, then keeps them.
+type failingNotifier struct {
+	recordingNotifier
+	failures int
+}
+
+func (f *failingNotifier) Notify(ctx context.Context, alert *model.SavedSearchAlert) error {
+	if f.failures > 0 {
+		f.failures--
+		return errors.New("webhook responded with 503 Service Unavailable")
+	}
+	return f.recordingNotifier.Notify(ctx, alert)
+}
+
+// newSavedSearchService returns a service whose queries are embedded from the cache.
+func newSavedSearchService(store services.SavedSearchStore, notifier cloud.Notifier) *services.SavedSearchService {
+	search := &services.SearchService{
+		ModelName:      "text-embedding-005",
+		EmbeddingCache: services.NewCache[string, []float64]("test.embedding", 10, 0),
+	}
+	search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "helicopters"), []float64{1, 0})
+	search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "car chase"), []float64{0, 1})
+	return &services.SavedSearchService{
+		Store:         store,
+		Search:        search,
+		MinSimilarity: 0.8,
+		Notifier:      notifier,
+	}
+}
+
+func TestSavedSearchCrud(t *testing.T) {
+	ctx := context.Background()
+	service := newSavedSearchService(services.NewMemorySavedSearchStore(), &recordingNotifier{})
+
+	err := service.Create(ctx, &services.SavedSearch{Query: "  "})
+	assert.That(t, errors.Is(err, services.ErrInvalidSavedSearch))
+
+	search := &services.SavedSearch{Query: " helicopters "}
+	assert.NoError(t, service.Create(ctx, search))
+	assert.That(t, search.Id != "")
+	assert.Equal(t, "helicopters", search.Name)
+
+	found, err := service.Get(ctx, search.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "helicopters", found.Query)
+
+	update := &services.SavedSearch{Name: "Choppers", Query: "helicopters", Filter: &services.SceneFilter{Genre: []string{"action"}}}
+	assert.NoError(t, service.Update(ctx, search.Id, update))
+	assert.Equal(t, search.Id, update.Id)
+	assert.Equal(t, search.CreateDate, update.CreateDate)
+	assert.Equal(t, 1, search.Version)
+	assert.Equal(t, 2, update.Version)
+
+	list, err := service.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, "Choppers", list[0].Name)
+	assert.DeepEqual(t, []string{"action"}, list[0].Filter.Genre)
+
+	assert.NoError(t, service.Delete(ctx, search.Id))
+	_, err = service.Get(ctx, search.Id)
+	assert.That(t, errors.Is(err, services.ErrSavedSearchNotFound))
+	err = service.Delete(ctx, search.Id)
+	assert.That(t, errors.Is(err, services.ErrSavedSearchNotFound))
+	err = service.Update(ctx, search.Id, update)
+	assert.That(t, errors.Is(err, services.ErrSavedSearchNotFound))
+}
+
+func TestSavedSearchFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := services.NewSavedSearchStore(cloud.SavedSearchConfig{Store: "local", Path: dir})
+	assert.NoError(t, err)
+	service := newSavedSearchService(store, &recordingNotifier{})
+
+	kept := &services.SavedSearch{Query: "helicopters"}
+	deleted := &services.SavedSearch{Query: "car chase"}
+	assert.NoError(t, service.Create(ctx, kept))
+	assert.NoError(t, service.Create(ctx, deleted))
+	assert.NoError(t, service.Delete(ctx, deleted.Id))
+
+	// The searches survive a restart
+	reloaded, err := services.NewFileSavedSearchStore(dir)
+	assert.NoError(t, err)
+	list, err := reloaded.List(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(list))
+	assert.Equal(t, kept.Id, list[0].Id)
+
+	// The store defaults to local files in the DefaultSavedSearchPath
+	defaultPath := services.DefaultSavedSearchPath
+	defer func() { services.DefaultSavedSearchPath = defaultPath }()
+	services.DefaultSavedSearchPath = filepath.Join(dir, "default")
+	_, err = services.NewSavedSearchStore(cloud.SavedSearchConfig{})
+	assert.NoError(t, err)
+	_, err = os.Stat(services.DefaultSavedSearchPath)
+	assert.NoError(t, err)
+	_, err = services.NewSavedSearchStore(cloud.SavedSearchConfig{Store: "redis"})
+	assert.Error(t, err)
+}
+
+func TestSavedSearchEvaluate(t *testing.T) {
+	ctx := context.Background()
+	notifier := &recordingNotifier{}
+	service := newSavedSearchService(services.NewMemorySavedSearchStore(), notifier)
+
+	helicopters := &services.SavedSearch{Query: "helicopters"}
+	strict := &services.SavedSearch{Query: "helicopters", MinSimilarity: 0.999}
+	comedies := &services.SavedSearch{Query: "helicopters", Filter: &services.SceneFilter{Genre: []string{"comedy"}}}
+	chases := &services.SavedSearch{Query: "car chase", Filter: &services.SceneFilter{Genre: []string{"Action"}}}
+	for _, search := range []*services.SavedSearch{helicopters, strict, comedies, chases} {
+		assert.NoError(t, service.Create(ctx, search))
+	}
+
+	media := &model.Media{Id: "m1", Title: "Skyfall", Genre: "action"}
+	embeddings := []*model.SceneEmbedding{
+		{Id: "m1", SequenceNumber: 0, Embeddings: []float64{1, 0.1}},
+		{Id: "m1", SequenceNumber: 1, Embeddings: []float64{0, 1}},
+		{Id: "m1", SequenceNumber: 2, Embeddings: []float64{1, 0}},
+		// Another model's embedding isn't compared
+		{Id: "m1", SequenceNumber: 3, Embeddings: []float64{1, 0, 0}},
+	}
+	assert.NoError(t, service.Evaluate(ctx, media, embeddings))
+
+	// One alert per matching search, the comedies don't match the media
+	alerts := make(map[string]*model.SavedSearchAlert)
+	for _, alert := range notifier.alerts {
+		alerts[alert.SavedSearchId] = alert
+	}
+	assert.Equal(t, 3, len(notifier.alerts))
+
+	alert := alerts[helicopters.Id]
+	assert.Equal(t, "Skyfall", alert.Title)
+	assert.Equal(t, 2, len(alert.Matches))
+	assert.Equal(t, 2, alert.Matches[0].SequenceNumber)
+	assert.Equal(t, 0, alert.Matches[1].SequenceNumber)
+
+	assert.Equal(t, 1, len(alerts[strict.Id].Matches))
+	assert.Equal(t, 2, alerts[strict.Id].Matches[0].SequenceNumber)
+
+	assert.Equal(t, 1, len(alerts[chases.Id].Matches))
+	assert.Equal(t, 1, alerts[chases.Id].Matches[0].SequenceNumber)
+}
+
+func TestSceneFilterMatches(t *testing.T) {
+	media := &model.Media{
+		Category:    "movie",
+		Genre:       "Action",
+		ReleaseYear: 2012,
+		Cast:        []*model.CastMember{{ActorName: "Daniel Craig"}},
+	}
+	var none *services.SceneFilter
+	assert.That(t, none.Matches(media))
+	assert.That(t, (&services.SceneFilter{Genre: []string{"comedy", " action "}}).Matches(media))
+	assert.That(t, !(&services.SceneFilter{Genre: []string{"comedy"}}).Matches(media))
+	assert.That(t, (&services.SceneFilter{Cast: []string{"daniel craig"}}).Matches(media))
+	assert.That(t, !(&services.SceneFilter{Cast: []string{"judi dench"}}).Matches(media))
+	assert.That(t, (&services.SceneFilter{ReleaseYear: services.IntRange{Min: 2010}}).Matches(media))
+	assert.That(t, !(&services.SceneFilter{ReleaseYear: services.IntRange{Max: 2010}}).Matches(media))
+	assert.That(t, !(&services.SceneFilter{Genre: []string{"action"}, Director: []string{"sam mendes"}}).Matches(media))
+}
+
+// unitVector returns a random vector of the dimensions normalized to length 1, as text embeddings are.
+func unitVector(r *rand.Rand, dims int) []float64 {
+	out := make([]float64, dims)
+	var norm float64
+	for i := range out {
+		out[i] = r.NormFloat64()
+		norm += out[i] * out[i]
+	}
+	for i := range out {
+		out[i] /= math.Sqrt(norm)
+	}
+	return out
+}
+
+func TestSavedSearchEvaluateNormalizedEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	r := rand.New(rand.NewPCG(1, 2))
+	query := unitVector(r, 768)
+	// A related scene, at a cosine similarity of about 0.8 to the query, and an unrelated one
+	noise := unitVector(r, 768)
+	related := make([]float64, len(query))
+	for i := range related {
+		related[i] = 0.8*query[i] + 0.6*noise[i]
+	}
+	unrelated := unitVector(r, 768)
+
+	notifier := &recordingNotifier{}
+	service := newSavedSearchService(services.NewMemorySavedSearchStore(), notifier)
+	service.MinSimilarity = 0
+	service.Search.EmbeddingCache.Put(services.EmbeddingCacheKey("text-embedding-005", "rooftop chase"), query)
+	assert.NoError(t, service.Create(ctx, &services.SavedSearch{Query: "rooftop chase"}))
+
+	// The euclidean similarity of the related scene is below the default
+	euclidean := cloud.MetricEuclidean.Similarity(cloud.MetricEuclidean.Distance(query, related))
+	assert.That(t, euclidean < services.DefaultAlertSimilarity)
+
+	assert.NoError(t, service.Evaluate(ctx, &model.Media{Id: "m1"}, []*model.SceneEmbedding{
+		{Id: "m1", SequenceNumber: 0, Embeddings: unrelated},
+		{Id: "m1", SequenceNumber: 1, Embeddings: related},
+	}))
+	assert.Equal(t, 1, len(notifier.alerts))
+	assert.Equal(t, 1, len(notifier.alerts[0].Matches))
+	assert.Equal(t, 1, notifier.alerts[0].Matches[0].SequenceNumber)
+	assert.That(t, notifier.alerts[0].Matches[0].Similarity > 0.75)
+}
+
+func TestSavedSearchAlertRetried(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	notifier := &failingNotifier{failures: 2}
+	store := jobs.NewMemoryStore()
+	alerts := jobs.NewQueue(commands.NewSavedSearchAlertCommand("saved-search-alert", notifier), store, cor.NewRetryPolicy(3, time.Millisecond), 1)
+	assert.NoError(t, alerts.Start(ctx))
+
+	service := newSavedSearchService(services.NewMemorySavedSearchStore(), notifier)
+	service.Alerts = alerts
+	search := &services.SavedSearch{Query: "helicopters"}
+	assert.NoError(t, service.Create(ctx, search))
+	media := &model.Media{Id: "m1", Title: "Skyfall"}
+	embeddings := []*model.SceneEmbedding{{Id: "m1", SequenceNumber: 0, Embeddings: []float64{1, 0}}}
+
+	// The alert is recorded as a job and sent once the notifier recovers
+	assert.NoError(t, service.Evaluate(ctx, media, embeddings))
+	job := waitForAlert(t, alerts, "saved-search-alert-"+search.Id+"-v1-m1")
+	assert.Equal(t, jobs.StateSucceeded, job.State)
+	assert.Equal(t, 3, job.Attempts)
+	assert.Equal(t, 1, len(notifier.alerts))
+	assert.Equal(t, "Skyfall", notifier.alerts[0].Title)
+
+	// The alert of the search and media isn't sent again
+	assert.NoError(t, service.Evaluate(ctx, media, embeddings))
+	assert.Equal(t, 1, len(notifier.alerts))
+
+	// unless the search is updated
+	assert.NoError(t, service.Update(ctx, search.Id, &services.SavedSearch{Name: "Choppers", Query: "helicopters"}))
+	assert.NoError(t, service.Evaluate(ctx, media, embeddings))
+	job = waitForAlert(t, alerts, "saved-search-alert-"+search.Id+"-v2-m1")
+	assert.Equal(t, jobs.StateSucceeded, job.State)
+	assert.Equal(t, 2, len(notifier.alerts))
+	assert.Equal(t, "Choppers", notifier.alerts[1].Name)
+}
+
+// waitForAlert returns the job of the alert once it is in a terminal state.
+func waitForAlert(t *testing.T, alerts *jobs.Queue, id string) *jobs.Job {
+	var job *jobs.Job
+	for range 200 {
+		var err error
+		if job, err = alerts.Get(context.Background(), id); err == nil && job.State.IsTerminal() {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.NotNil(t, job)
+	return job
+}
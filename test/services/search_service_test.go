@@ -51,9 +51,10 @@ func TestSearchService(t *testing.T) {
 		DatasetName:    "media_ds",
 		MediaTable:     "media",
 		EmbeddingTable: "scene_embeddings",
+		Index:          cloud.NewBigQueryVectorIndex(cloudClients.BiqQueryClient, "media_ds", "scene_embeddings", config.EmbeddingModels["multi-lingual"].Model, cloud.MetricEuclidean),
 	}
 
-	out, err := searchService.FindScenes(ctx, "Scenes that Woody Harrelson", 5)
+	out, err := searchService.FindScenes(ctx, "Scenes that Woody Harrelson", nil, 5)
 
 	if err != nil {
 		t.Error(err)
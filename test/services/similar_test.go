@@ -0,0 +1,87 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/zeebo/assert"
+)
+
+func similarService(t *testing.T) *services.SearchService {
+	index, err := cloud.NewHNSWVectorIndex(cloud.MetricEuclidean, cloud.HNSWParams{}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, index.Upsert(context.Background(), []*model.SceneEmbedding{
+		{Id: "a", SequenceNumber: 1, Embeddings: []float64{0, 0}},
+		{Id: "a", SequenceNumber: 2, Embeddings: []float64{0, 1}},
+		{Id: "b", SequenceNumber: 1, Embeddings: []float64{0, 0.5}},
+		{Id: "c", SequenceNumber: 1, Embeddings: []float64{5, 5}},
+	}))
+	return &services.SearchService{Index: index}
+}
+
+func TestSimilarScenes(t *testing.T) {
+	ctx := context.Background()
+	search := similarService(t)
+
+	out, err := search.SimilarScenes(ctx, "a", 1, false, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, "b", out[0].MediaId)
+	assert.Equal(t, 0.5, out[0].Distance)
+	assert.Equal(t, 1/1.5, out[0].Similarity)
+	assert.Equal(t, "a", out[1].MediaId)
+	assert.Equal(t, 2, out[1].SequenceNumber)
+
+	out, err = search.SimilarScenes(ctx, "a", 1, true, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(out))
+	assert.Equal(t, "b", out[0].MediaId)
+	assert.Equal(t, "c", out[1].MediaId)
+
+	_, err = search.SimilarScenes(ctx, "a", 9, false, 5)
+	assert.That(t, errors.Is(err, services.ErrNoEmbeddings))
+}
+
+func TestSimilarMedia(t *testing.T) {
+	ctx := context.Background()
+	search := similarService(t)
+	search.MinSimilarity = 0.5
+
+	// The media vector of a is (0, 0.5)
+	out, err := search.SimilarMedia(ctx, "a", true, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(out))
+	assert.Equal(t, "b", out[0].MediaId)
+	assert.Equal(t, 1.0, out[0].Score)
+
+	_, err = search.SimilarMedia(ctx, "unknown", false, 5)
+	assert.That(t, errors.Is(err, services.ErrNoEmbeddings))
+}
+
+func TestMeanVector(t *testing.T) {
+	assert.Nil(t, services.MeanVector(nil))
+	assert.DeepEqual(t, []float64{1, 2}, services.MeanVector([]*model.SceneEmbedding{
+		{Embeddings: []float64{0, 1}},
+		{Embeddings: []float64{2, 3}},
+	}))
+}
@@ -34,7 +34,7 @@ func TestMediaEmbeddings(t *testing.T) {
 	chainCtx := cor.NewBaseContext()
 	chainCtx.SetContext(traceCtx)
 
-	embeddingWorkflow := workflow.NewMediaEmbeddingGeneratorWorkflow(config, cloudClients)
+	embeddingWorkflow := workflow.NewMediaEmbeddingGeneratorWorkflow(config, cloudClients, "bin/ffmpeg")
 	embeddingWorkflow.Execute(chainCtx)
 
 	for _, e := range chainCtx.GetErrors() {
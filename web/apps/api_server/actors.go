@@ -0,0 +1,89 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package main
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/gin-gonic/gin"
+)
+
+func ActorRouter(r *gin.RouterGroup) {
+	actors := r.Group("/actors")
+	{
+		actors.GET("", func(c *gin.Context) {
+			pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(services.DefaultPageSize)))
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid page_size: " + c.Query("page_size")})
+				return
+			}
+			page, err := state.actorService.List(c, c.Query("name"), c.Query("cursor"), pageSize)
+			if errors.Is(err, services.ErrInvalidCursor) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
+			}
+			setNextCursor(c, page.NextCursor)
+			c.JSON(200, page.Actors)
+		})
+
+		actors.GET("/:id", func(c *gin.Context) {
+			out, err := state.actorService.Get(c, c.Param("id"))
+			if err != nil {
+				c.Status(404)
+				return
+			}
+			c.JSON(200, out)
+		})
+
+		actors.GET("/:id/media", func(c *gin.Context) {
+			sort, err := services.ParseMediaSort(c.DefaultQuery("sort", "-release_year"))
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(services.DefaultPageSize)))
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid page_size: " + c.Query("page_size")})
+				return
+			}
+			page, err := state.actorService.Filmography(c, c.Param("id"), sort, c.Query("cursor"), pageSize)
+			if errors.Is(err, services.ErrActorNotFound) {
+				c.JSON(404, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, services.ErrInvalidCursor) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
+			}
+			setNextCursor(c, page.NextCursor)
+			c.JSON(200, page.Media)
+		})
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchIdHeader is the response header carrying the id of a recorded search, sent back with feedback on its results.
+const SearchIdHeader = "X-Search-Id"
+
+// DefaultReportPeriod is the period of an analytics report without a since parameter.
+const DefaultReportPeriod = 7 * 24 * time.Hour
+
+func AnalyticsRouter(r *gin.RouterGroup) {
+	r.POST("/feedback", func(c *gin.Context) {
+		if state.analyticsService == nil {
+			c.JSON(501, gin.H{"error": "analytics are disabled"})
+			return
+		}
+		event := &model.FeedbackEvent{}
+		if err := c.ShouldBindJSON(event); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		err := state.analyticsService.RecordFeedback(c, event)
+		if errors.Is(err, services.ErrInvalidFeedback) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			log.Println(err)
+			c.Status(500)
+			return
+		}
+		c.JSON(201, event)
+	})
+
+	r.GET("/analytics/report", func(c *gin.Context) {
+		if state.analyticsService == nil {
+			c.JSON(501, gin.H{"error": "analytics are disabled"})
+			return
+		}
+		options, err := reportOptions(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		report, err := state.analyticsService.Report(c, options)
+		if err != nil {
+			log.Println(err)
+			c.Status(500)
+			return
+		}
+		c.JSON(200, report)
+	})
+}
+
+// recordSearch records the first page of a search in the background, sending its id in the
+// SearchIdHeader, and returns the next cursor carrying the id. The next pages send the id of
+// their cursor instead of being recorded. Searches aren't recorded when analytics are disabled.
+func recordSearch(c *gin.Context, cursor string, nextCursor string, event *model.SearchEvent) (string, error) {
+	if state.analyticsService == nil {
+		return nextCursor, nil
+	}
+	searchId, err := services.CursorSearchId(cursor)
+	if err != nil {
+		return "", err
+	}
+	if searchId == "" {
+		searchId = event.Id
+		// The context outlives the request
+		ctx := context.WithoutCancel(c.Request.Context())
+		go func() {
+			if err := state.analyticsService.RecordSearch(ctx, event); err != nil {
+				log.Printf("failed to record search: %v", err)
+			}
+		}()
+	}
+	c.Header(SearchIdHeader, searchId)
+	return services.RecordedCursor(nextCursor, searchId)
+}
+
+// reportOptions reads the options of an analytics report from the query parameters,
+// e.g. ?since=2025-01-01T00:00:00Z&min_searches=5&max_ctr=0.1&limit=20
+func reportOptions(c *gin.Context) (options services.ReportOptions, err error) {
+	options.Since = time.Now().Add(-DefaultReportPeriod)
+	if since, ok := c.GetQuery("since"); ok {
+		if options.Since, err = time.Parse(time.RFC3339, since); err != nil {
+			return options, fmt.Errorf("invalid since: %s", since)
+		}
+	}
+	if options.MinSearches, err = strconv.Atoi(c.DefaultQuery("min_searches", strconv.Itoa(services.DefaultReportMinSearches))); err != nil {
+		return options, fmt.Errorf("invalid min_searches: %s", c.Query("min_searches"))
+	}
+	if options.MaxCTR, err = strconv.ParseFloat(c.DefaultQuery("max_ctr", strconv.FormatFloat(services.DefaultReportMaxCTR, 'f', -1, 64)), 64); err != nil {
+		return options, fmt.Errorf("invalid max_ctr: %s", c.Query("max_ctr"))
+	}
+	if options.Limit, err = strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultReportLimit))); err != nil {
+		return options, fmt.Errorf("invalid limit: %s", c.Query("limit"))
+	}
+	return options, nil
+}
@@ -54,9 +54,9 @@ func main() {
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH"},
-		AllowHeaders:     []string{"Origin"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type"},
+		ExposeHeaders:    []string{"Content-Length", NextCursorHeader, SearchIdHeader},
 		AllowCredentials: true,
 		AllowOriginFunc: func(origin string) bool {
 			return true
@@ -71,6 +71,21 @@ func main() {
 		MediaRouter(apiV1)
 		// Register "/api/v1/uploads"
 		FileUpload(apiV1)
+		// Register "/api/v1/jobs" end-points
+		JobRouter(apiV1)
+		// Register "/api/v1/actors" end-points
+		ActorRouter(apiV1)
+		// Register "/api/v1/feedback" and "/api/v1/analytics" end-points
+		AnalyticsRouter(apiV1)
+		// Register "/api/v1/saved-searches" end-points
+		SavedSearchRouter(apiV1)
+	}
+
+	// Create the "/api/v2" group
+	apiV2 := r.Group("/api/v2")
+	{
+		// Register "/api/v2/media" end-points
+		MediaRouterV2(apiV2)
 	}
 
 	// serving the front-end asset
@@ -18,9 +18,10 @@ package main
 
 import (
 	"log"
-	"os"
+	"mime/multipart"
 	"path/filepath"
 
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
 	"github.com/gin-gonic/gin"
 )
 
@@ -36,40 +37,31 @@ func FileUpload(r *gin.RouterGroup) {
 				return
 			}
 			files := form.File["files"]
-			bucket := state.cloud.StorageClient.Bucket(config.Storage.HiResInputBucket)
 
 			for _, file := range files {
-				localPath := filepath.Join(os.TempDir(), file.Filename)
-				err := c.SaveUploadedFile(file, localPath)
-				if err != nil {
-					log.Println(err)
-					c.Status(400)
-					return
-				}
-				content, err := os.ReadFile(localPath)
-				if err != nil {
-					log.Println(err)
-					c.Status(400)
-					return
-				}
-				wc := bucket.Object(file.Filename).NewWriter(c)
-				wc.ContentType = "video/mp4"
-				_, err = wc.Write(content)
+				err = uploadFile(c, config.Storage.HiResInputBucket, file)
 				if err != nil {
 					c.Status(500)
 					log.Printf("failed to write file to bucket: %v\n", err)
 					return
 				}
-				err = wc.Close()
-				if err != nil {
-					log.Printf("failed to close bucket handle: %v\n", err)
-				}
-				err = os.Remove(localPath)
-				if err != nil {
-					log.Printf("failed to remove file from server: %v\n", err)
-				}
 			}
 			c.Status(200)
 		})
 	}
 }
+
+// uploadFile streams the uploaded file to the bucket.
+func uploadFile(c *gin.Context, bucket string, file *multipart.FileHeader) error {
+	content, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	contentType := file.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = "video/mp4"
+	}
+	return cloud.WriteBlob(c, state.cloud.BlobStore, content, bucket, filepath.Base(file.Filename), contentType)
+}
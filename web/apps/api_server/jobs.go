@@ -0,0 +1,64 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package main
+
+import (
+	"errors"
+	"log"
+	"strconv"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
+	"github.com/gin-gonic/gin"
+)
+
+func JobRouter(r *gin.RouterGroup) {
+	jobGroup := r.Group("/jobs")
+	{
+		jobGroup.GET("", func(c *gin.Context) {
+			limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+			if err != nil {
+				limit = 100
+			}
+			filter := jobs.Filter{
+				Queue: c.Query("queue"),
+				State: jobs.State(c.Query("state")),
+				Limit: limit,
+			}
+			out, err := state.cloud.JobStore.List(c, filter)
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
+			}
+			c.JSON(200, out)
+		})
+
+		jobGroup.GET("/:id", func(c *gin.Context) {
+			out, err := state.cloud.JobStore.Get(c, c.Param("id"))
+			if errors.Is(err, jobs.ErrJobNotFound) {
+				c.Status(404)
+				return
+			}
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
+			}
+			c.JSON(200, out)
+		})
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"context"
 
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cor"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/workflow"
 )
@@ -28,15 +30,36 @@ import (
 func SetupListeners(config *cloud.Config, cloudClients *cloud.ServiceClients, templateService *cloud.TemplateService, ctx context.Context) {
 	// TODO - Externalize the destination topic and ffmpeg command
 	mediaResizeWorkflow := workflow.NewMediaResizeWorkflow(config, cloudClients, "bin/ffmpeg", &model.MediaFormatFilter{Width: "240"})
-	cloudClients.PubSubListeners["HiResTopic"].SetCommand(mediaResizeWorkflow)
-	cloudClients.PubSubListeners["HiResTopic"].Listen(ctx)
+	listen(ctx, config, cloudClients, "HiResTopic", mediaResizeWorkflow)
 
 	mediaIngestion := workflow.NewMediaReaderPipeline(config, cloudClients, "creative-flash", "bin/ffprobe", templateService)
-
-	cloudClients.PubSubListeners["LowResTopic"].SetCommand(mediaIngestion)
-	cloudClients.PubSubListeners["LowResTopic"].Listen(ctx)
+	listen(ctx, config, cloudClients, "LowResTopic", mediaIngestion)
 
 	mediaConfigUpdateWorkflow := workflow.NewMediaConfigUpdateWorkflow(config, templateService)
-	cloudClients.PubSubListeners["ConfigTopic"].SetCommand(mediaConfigUpdateWorkflow)
-	cloudClients.PubSubListeners["ConfigTopic"].Listen(ctx)
+	listen(ctx, config, cloudClients, "ConfigTopic", mediaConfigUpdateWorkflow)
+}
+
+// listen records the messages of the subscription as jobs, executed by the command
+// on a job queue with the retry policy of the command and the subscription's dead-letter topic.
+func listen(ctx context.Context, config *cloud.Config, cloudClients *cloud.ServiceClients, subscription string, command cor.Command) {
+	topic := config.TopicSubscriptions[subscription]
+
+	queue := jobs.NewQueue(command, cloudClients.JobStore, retryPolicy(config, command), topic.Workers)
+	if topic.DeadLetterTopic != "" {
+		queue.SetDeadLetter(cloud.NewPubSubDeadLetter(cloudClients.PubsubClient, topic.DeadLetterTopic))
+	}
+	if err := queue.Start(ctx); err != nil {
+		panic(err)
+	}
+
+	cloudClients.MessageSources[subscription].SetQueue(queue)
+	cloudClients.MessageSources[subscription].Listen(ctx)
+}
+
+// retryPolicy returns the configured retry policy of the command, nil for the default policy of a job queue.
+func retryPolicy(config *cloud.Config, command cor.Command) *cor.RetryPolicy {
+	if configured, ok := config.RetryPolicies[command.GetName()]; ok {
+		return configured.ToRetryPolicy()
+	}
+	return nil
 }
@@ -17,10 +17,17 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/model"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,54 +35,56 @@ func MediaRouter(r *gin.RouterGroup) {
 	media := r.Group("/media")
 	{
 		media.GET("", func(c *gin.Context) {
-			query := c.Query("s")
+			filter, err := sceneFilter(c)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			if len(c.Query("s")) == 0 {
+				listMedia(c, filter)
+				return
+			}
+			if c.Query("facets") != "" || c.Query("interpret") != "" {
+				c.JSON(400, gin.H{"error": "facets and interpret are only supported by /api/v2/media"})
+				return
+			}
+			searchMedia(c, filter, false)
+		})
+
+		media.POST("/search", func(c *gin.Context) {
+			filter, err := sceneFilter(c)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
 			count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
 			if err != nil {
 				count = 5
 			}
-			if len(query) == 0 {
-				c.Status(404)
+			mimeType, data, err := queryMedia(c)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			sceneResults, err := state.searchService.FindScenesByMedia(c, mimeType, data, filter, services.PageSize(count))
+			if errors.Is(err, services.ErrUnsupportedMediaType) {
+				c.JSON(415, gin.H{"error": err.Error()})
+				return
+			}
+			if errors.Is(err, services.ErrMultimodalDisabled) || errors.Is(err, cloud.ErrVideoDimension) {
+				c.JSON(501, gin.H{"error": err.Error()})
 				return
 			}
-			sceneResults, err := state.searchService.FindScenes(c, query, count)
-
 			if err != nil {
-				c.Status(404)
 				log.Println(err)
+				c.Status(500)
 				return
 			}
-
-			out := make(map[string]*model.Media, 0)
-
-			// Convert the results into a map driven by the media id
-			for _, r := range sceneResults {
-				var med *model.Media
-				if m, ok := out[r.MediaId]; !ok {
-					m, err := state.mediaService.Get(c, r.MediaId)
-					if err != nil {
-						log.Print(err)
-						c.Status(400)
-						return
-					}
-					// Clear the scenes
-					m.Scenes = make([]*model.Scene, 0)
-					out[r.MediaId] = m
-					med = m
-				} else {
-					med = m
-				}
-
-				s, err := state.mediaService.GetScene(c, r.MediaId, r.SequenceNumber)
-				if err != nil {
-					c.Status(400)
-					return
-				}
-				med.Scenes = append(med.Scenes, s)
-			}
-			// Reduce
-			results := make([]*model.Media, 0)
-			for _, v := range out {
-				results = append(results, v)
+			results, err := state.mediaService.Hydrate(c, sceneResults)
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
 			}
 			c.JSON(200, results)
 		})
@@ -90,6 +99,31 @@ func MediaRouter(r *gin.RouterGroup) {
 			c.JSON(200, out)
 		})
 
+		media.GET("/:id/similar", func(c *gin.Context) {
+			count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+			if err != nil {
+				count = 5
+			}
+			excludeSource, _ := strconv.ParseBool(c.Query("exclude_source"))
+			results, err := state.searchService.SimilarMedia(c, c.Param("id"), excludeSource, services.PageSize(count))
+			respondWithSimilar(c, results, err)
+		})
+
+		media.GET("/:id/scenes/:scene_id/similar", func(c *gin.Context) {
+			sceneId, err := strconv.Atoi(c.Param("scene_id"))
+			if err != nil {
+				c.Status(400)
+				return
+			}
+			count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+			if err != nil {
+				count = 5
+			}
+			excludeSource, _ := strconv.ParseBool(c.Query("exclude_source"))
+			results, err := state.searchService.SimilarScenes(c, c.Param("id"), sceneId, excludeSource, services.PageSize(count))
+			respondWithSimilar(c, results, err)
+		})
+
 		media.GET("/:id/scenes/:scene_id", func(c *gin.Context) {
 			id := c.Param("id")
 			sceneId, err := strconv.Atoi(c.Param("scene_id"))
@@ -106,3 +140,264 @@ func MediaRouter(r *gin.RouterGroup) {
 		})
 	}
 }
+
+// SearchResponse is the body of the searches of /api/v2/media, the facets and the
+// interpretation are null unless requested.
+type SearchResponse struct {
+	Results        []*model.MediaMatchResult     `json:"results"`
+	Facets         services.Facets               `json:"facets"`
+	Interpretation *services.QueryInterpretation `json:"interpretation"`
+}
+
+// MediaRouterV2 registers the end-points of /api/v2, searches respond with a SearchResponse.
+func MediaRouterV2(r *gin.RouterGroup) {
+	media := r.Group("/media")
+	{
+		media.GET("", func(c *gin.Context) {
+			if len(c.Query("s")) == 0 {
+				c.JSON(400, gin.H{"error": "missing search query s"})
+				return
+			}
+			filter, err := sceneFilter(c)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			searchMedia(c, filter, true)
+		})
+	}
+}
+
+// searchMedia responds with a page of the media matching the search query of the s parameter,
+// the results alone or, in an envelope, with the facets and interpretation requested.
+func searchMedia(c *gin.Context, filter *services.SceneFilter, envelope bool) {
+	started := time.Now()
+	query := c.Query("s")
+	count, err := strconv.Atoi(c.DefaultQuery("count", "5"))
+	if err != nil {
+		count = 5
+	}
+	mode, err := services.ParseSearchMode(c.DefaultQuery("mode", GetConfig().Search.DefaultMode))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	// The next pages of an interpreted search carry its interpretation in their cursor
+	cursor := c.Query("cursor")
+	interpretation, err := services.CursorInterpretation(cursor, query)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	// Interpretation is best effort, the query is searched as typed on errors
+	if interpret, _ := strconv.ParseBool(c.Query("interpret")); interpret && cursor == "" && state.interpretService != nil {
+		if interpretation, err = state.interpretService.Interpret(c, query); err != nil {
+			log.Printf("failed to interpret search query: %v", err)
+		}
+	}
+	searchQuery := query
+	if interpretation != nil {
+		// The filters of the request override those of the interpretation
+		filter = interpretation.Filter.Merge(filter)
+		interpretation.Filter = *filter
+		searchQuery = interpretation.Query
+	}
+	facets, err := requestedFacets(c)
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	page, err := state.searchService.SearchScenes(c, searchQuery, mode, filter, facets, cursor, count)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.Status(404)
+		log.Println(err)
+		return
+	}
+	if interpretation != nil {
+		if page.NextCursor, err = services.InterpretedCursor(page.NextCursor, query, interpretation); err != nil {
+			log.Println(err)
+			c.Status(500)
+			return
+		}
+	}
+
+	// Fetch the media and scenes of the results in ranking order
+	results, err := state.mediaService.Hydrate(c, page.Results)
+	if err != nil {
+		log.Println(err)
+		c.Status(500)
+		return
+	}
+	// Explanations are best effort, the results are returned without them on errors
+	if explain, _ := strconv.ParseBool(c.Query("explain")); explain && state.explainService != nil {
+		if err = state.explainService.Explain(c, searchQuery, results); err != nil {
+			log.Printf("failed to explain search results: %v", err)
+		}
+	}
+	event := services.SearchEvent(searchQuery, mode, filter, time.Since(started), page.Results)
+	if page.NextCursor, err = recordSearch(c, cursor, page.NextCursor, event); err != nil {
+		log.Println(err)
+		c.Status(500)
+		return
+	}
+	setNextCursor(c, page.NextCursor)
+	if envelope {
+		c.JSON(200, SearchResponse{Results: results, Facets: page.Facets, Interpretation: interpretation})
+		return
+	}
+	c.JSON(200, results)
+}
+
+// NextCursorHeader is the response header carrying the cursor of the next page.
+const NextCursorHeader = "X-Next-Cursor"
+
+func setNextCursor(c *gin.Context, cursor string) {
+	if cursor != "" {
+		c.Header(NextCursorHeader, cursor)
+	}
+}
+
+// requestedFacets returns the facet fields of the facets parameter, a comma separated list of
+// fields or true for the configured fields, none without the parameter.
+func requestedFacets(c *gin.Context) ([]string, error) {
+	param := c.Query("facets")
+	if param == "" {
+		return nil, nil
+	}
+	if enabled, err := strconv.ParseBool(param); err == nil {
+		if enabled {
+			return state.facets, nil
+		}
+		return nil, nil
+	}
+	return services.ParseFacets(param)
+}
+
+// listMedia responds with a page of the media catalog, sorted by the sort parameter.
+func listMedia(c *gin.Context, filter *services.SceneFilter) {
+	sort, err := services.ParseMediaSort(c.Query("sort"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(services.DefaultPageSize)))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid page_size: " + c.Query("page_size")})
+		return
+	}
+	page, err := state.mediaService.List(c, filter, sort, c.Query("cursor"), pageSize)
+	if errors.Is(err, services.ErrInvalidCursor) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		c.Status(500)
+		return
+	}
+	setNextCursor(c, page.NextCursor)
+	c.JSON(200, page.Media)
+}
+
+// MaxQueryMediaSize is the largest image or clip accepted as a search query.
+const MaxQueryMediaSize = 20 << 20
+
+// queryMedia reads the image or clip of a search from the "file" field of the multipart form,
+// the MIME type is that of the part or, if missing, sniffed from the content.
+func queryMedia(c *gin.Context) (string, []byte, error) {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return "", nil, fmt.Errorf("missing file: %w", err)
+	}
+	if header.Size > MaxQueryMediaSize {
+		return "", nil, fmt.Errorf("file is larger than %d bytes", MaxQueryMediaSize)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, MaxQueryMediaSize))
+	if err != nil {
+		return "", nil, err
+	}
+	mimeType := header.Header.Get("Content-Type")
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	return mimeType, data, nil
+}
+
+// respondWithSimilar responds with the media of the scenes found by a similarity search.
+func respondWithSimilar(c *gin.Context, sceneResults []*model.SceneMatchResult, err error) {
+	if errors.Is(err, services.ErrNoEmbeddings) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		c.Status(500)
+		return
+	}
+	results, err := state.mediaService.Hydrate(c, sceneResults)
+	if err != nil {
+		log.Println(err)
+		c.Status(500)
+		return
+	}
+	c.JSON(200, results)
+}
+
+// sceneFilter reads the media filter of a search from the query parameters, the string
+// fields may be repeated to match any of the values and the year and length take a
+// value or an inclusive range, e.g. ?genre=action&rating=PG-13&release_year_min=2016
+func sceneFilter(c *gin.Context) (*services.SceneFilter, error) {
+	filter := &services.SceneFilter{
+		Category: c.QueryArray("category"),
+		Genre:    c.QueryArray("genre"),
+		Rating:   c.QueryArray("rating"),
+		Director: c.QueryArray("director"),
+		Cast:     c.QueryArray("cast"),
+	}
+	var err error
+	if filter.ReleaseYear, err = intRange(c, "release_year"); err != nil {
+		return nil, err
+	}
+	if filter.LengthInSeconds, err = intRange(c, "length_in_seconds"); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// intRange reads the range of the parameter from param, param_min and param_max.
+func intRange(c *gin.Context, param string) (r services.IntRange, err error) {
+	value := func(name string) (int, error) {
+		v, ok := c.GetQuery(name)
+		if !ok {
+			return 0, nil
+		}
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %s", name, v)
+		}
+		return i, nil
+	}
+	if r.Min, err = value(param + "_min"); err != nil {
+		return r, err
+	}
+	if r.Max, err = value(param + "_max"); err != nil {
+		return r, err
+	}
+	exact, err := value(param)
+	if err != nil {
+		return r, err
+	}
+	if exact != 0 {
+		r.Min, r.Max = exact, exact
+	}
+	return r, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2025 Google, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// Author: rrmcguinness (Ryan McGuinness)
+
+package main
+
+import (
+	"errors"
+	"log"
+
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
+	"github.com/gin-gonic/gin"
+)
+
+func SavedSearchRouter(r *gin.RouterGroup) {
+	savedSearches := r.Group("/saved-searches")
+	{
+		savedSearches.GET("", func(c *gin.Context) {
+			out, err := state.savedSearchService.List(c)
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
+			}
+			c.JSON(200, out)
+		})
+
+		savedSearches.POST("", func(c *gin.Context) {
+			search := &services.SavedSearch{}
+			if err := c.ShouldBindJSON(search); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			err := state.savedSearchService.Create(c, search)
+			respondWithSavedSearch(c, 201, search, err)
+		})
+
+		savedSearches.GET("/:id", func(c *gin.Context) {
+			search, err := state.savedSearchService.Get(c, c.Param("id"))
+			respondWithSavedSearch(c, 200, search, err)
+		})
+
+		savedSearches.PUT("/:id", func(c *gin.Context) {
+			search := &services.SavedSearch{}
+			if err := c.ShouldBindJSON(search); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			err := state.savedSearchService.Update(c, c.Param("id"), search)
+			respondWithSavedSearch(c, 200, search, err)
+		})
+
+		savedSearches.DELETE("/:id", func(c *gin.Context) {
+			err := state.savedSearchService.Delete(c, c.Param("id"))
+			if errors.Is(err, services.ErrSavedSearchNotFound) {
+				c.JSON(404, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				log.Println(err)
+				c.Status(500)
+				return
+			}
+			c.Status(204)
+		})
+	}
+}
+
+// respondWithSavedSearch responds with the saved search in the status, or the error of the saved search service.
+func respondWithSavedSearch(c *gin.Context, status int, search *services.SavedSearch, err error) {
+	if errors.Is(err, services.ErrSavedSearchNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidSavedSearch) {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Println(err)
+		c.Status(500)
+		return
+	}
+	c.JSON(status, search)
+}
@@ -21,17 +21,28 @@ import (
 	"context"
 	"log"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/cloud"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/commands"
+	"github.com/GoogleCloudPlatform/media-search-solution/pkg/jobs"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/services"
 	"github.com/GoogleCloudPlatform/media-search-solution/pkg/workflow"
 )
 
 type StateManager struct {
-	config        *cloud.Config
-	cloud         *cloud.ServiceClients
-	searchService *services.SearchService
-	mediaService  *services.MediaService
+	config             *cloud.Config
+	cloud              *cloud.ServiceClients
+	searchService      *services.SearchService
+	mediaService       *services.MediaService
+	actorService       *services.ActorService
+	facets             []string
+	explainService     *services.ExplainService
+	interpretService   *services.InterpretService
+	analyticsService   *services.AnalyticsService
+	savedSearchService *services.SavedSearchService
 }
 
 var state = &StateManager{}
@@ -86,13 +97,53 @@ func InitState(ctx context.Context) {
 	mediaTableName := config.BigQueryDataSource.MediaTable
 	embeddingTableName := config.BigQueryDataSource.EmbeddingTable
 
+	cacheTTL := time.Duration(config.Search.CacheTTLInSeconds) * time.Second
 	state.searchService = &services.SearchService{
 		BigqueryClient: cloudClients.BiqQueryClient,
 		EmbeddingModel: cloudClients.EmbeddingModels["multi-lingual"],
 		DatasetName:    datasetName,
 		MediaTable:     mediaTableName,
 		EmbeddingTable: embeddingTableName,
+		Index:          cloudClients.VectorIndex,
 		ModelName:      config.EmbeddingModels["multi-lingual"].Model,
+		VectorWeight:   config.Search.VectorWeight,
+		KeywordWeight:  config.Search.KeywordWeight,
+		RRFK:           config.Search.RRFK,
+		MinSimilarity:  config.Search.MinSimilarity,
+		FacetDepth:     config.Search.FacetDepth,
+		FacetSize:      config.Search.FacetSize,
+		EmbeddingCache: services.NewCache[string, []float64]("search.embedding", config.Search.EmbeddingCacheSize, cacheTTL),
+		ResultCache:    services.NewCache[string, *services.ScenePage]("search.result", config.Search.ResultCacheSize, cacheTTL),
+
+		MultimodalModel: cloudClients.MultimodalEmbeddingModel,
+		MultimodalIndex: cloudClients.MultimodalIndex,
+
+		MultimodalMinSimilarity: config.MultimodalEmbedding.MinSimilarity,
+	}
+
+	// New embeddings change the results of searches
+	cloudClients.VectorIndex.OnChange(state.searchService.InvalidateResults)
+
+	state.facets, err = services.ParseFacets(strings.Join(config.Search.Facets, ","))
+	if err != nil {
+		panic(err)
+	}
+
+	if explainModel, ok := cloudClients.AgentModels[config.Search.ExplainModel]; ok {
+		state.explainService = &services.ExplainService{Model: explainModel}
+	}
+
+	if interpretModel, ok := cloudClients.AgentModels[config.Search.InterpretModel]; ok {
+		categories := make([]string, 0, len(config.Categories))
+		for key := range config.Categories {
+			categories = append(categories, key)
+		}
+		sort.Strings(categories)
+		state.interpretService = &services.InterpretService{Model: interpretModel, Categories: categories}
+	}
+
+	if cloudClients.AnalyticsSink != nil {
+		state.analyticsService = &services.AnalyticsService{Sink: cloudClients.AnalyticsSink}
 	}
 
 	state.mediaService = &services.MediaService{
@@ -101,7 +152,34 @@ func InitState(ctx context.Context) {
 		MediaTable:     mediaTableName,
 	}
 
-	embeddingGenerator := workflow.NewMediaEmbeddingGeneratorWorkflow(config, cloudClients)
+	state.actorService = &services.ActorService{
+		BigqueryClient: cloudClients.BiqQueryClient,
+		DatasetName:    datasetName,
+		ActorTable:     config.BigQueryDataSource.ActorTable,
+		Media:          state.mediaService,
+	}
+
+	savedSearchStore, err := services.NewSavedSearchStore(config.SavedSearches)
+	if err != nil {
+		panic(err)
+	}
+	// Alerts are sent by a job queue, so the alerts the notifier fails to send are retried and kept
+	alertCommand := commands.NewSavedSearchAlertCommand("saved-search-alert", cloudClients.Notifier)
+	alerts := jobs.NewQueue(alertCommand, cloudClients.JobStore, retryPolicy(config, alertCommand), 1)
+	if err = alerts.Start(ctx); err != nil {
+		panic(err)
+	}
+	state.savedSearchService = &services.SavedSearchService{
+		Store:         savedSearchStore,
+		Search:        state.searchService,
+		MinSimilarity: config.SavedSearches.MinSimilarity,
+		Notifier:      cloudClients.Notifier,
+		Alerts:        alerts,
+	}
+
+	embeddingGenerator := workflow.NewMediaEmbeddingGeneratorWorkflow(config, cloudClients, "bin/ffmpeg")
+	// New media are matched against the saved searches once their scripts are embedded
+	embeddingGenerator.OnEmbedded(state.savedSearchService.Evaluate)
 	embeddingGenerator.StartTimer()
 
 	SetupListeners(config, cloudClients, cloud.NewTemplateService(config), ctx)